@@ -7,10 +7,20 @@ import (
 	"time"
 
 	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
 	"github.com/annusingmar/lavurso-backend/internal/validator"
 	"github.com/go-chi/chi/v5"
 )
 
+func (app *application) canReviewExcuse(user *data.UserExt, studentID int) (bool, error) {
+	if *user.Role == data.RoleAdministrator {
+		return true, nil
+	}
+
+	return app.models.Users.IsUserTeacherOfStudent(studentID, user.ID)
+}
+
 func (app *application) excuseAbsenceForStudent(w http.ResponseWriter, r *http.Request) {
 	sessionUser := app.getUserFromContext(r)
 
@@ -58,6 +68,12 @@ func (app *application) excuseAbsenceForStudent(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	canReview, err := app.canReviewExcuse(sessionUser, *mark.UserID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
 	at := time.Now().UTC()
 
 	excuse := &data.Excuse{
@@ -65,6 +81,14 @@ func (app *application) excuseAbsenceForStudent(w http.ResponseWriter, r *http.R
 		Excuse: &input.Excuse,
 		UserID: &sessionUser.ID,
 		At:     &at,
+		Status: helpers.ToPtr(data.ExcusePending),
+	}
+
+	// excuses submitted by the class teacher or an admin don't need reviewing
+	if canReview {
+		excuse.Status = helpers.ToPtr(data.ExcuseAccepted)
+		excuse.ReviewerID = &sessionUser.ID
+		excuse.ReviewedAt = &at
 	}
 
 	v := validator.NewValidator()
@@ -77,7 +101,7 @@ func (app *application) excuseAbsenceForStudent(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if mark.Excuse != nil {
+	if mark.Excuse != nil && *mark.Excuse.Status != data.ExcuseRejected {
 		app.writeErrorResponse(w, r, http.StatusConflict, data.ErrAbsenceExcused.Error())
 		return
 	}
@@ -132,6 +156,22 @@ func (app *application) deleteExcuseForStudent(w http.ResponseWriter, r *http.Re
 		}
 	}
 
+	if mark.Excuse == nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchExcuse.Error())
+		return
+	}
+
+	canReview, err := app.canReviewExcuse(sessionUser, *mark.UserID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if !canReview && *mark.Excuse.Status != data.ExcusePending {
+		app.writeErrorResponse(w, r, http.StatusConflict, data.ErrExcuseAlreadyReviewed.Error())
+		return
+	}
+
 	err = app.models.Absences.DeleteExcuseByMarkID(mark.ID)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -144,3 +184,337 @@ func (app *application) deleteExcuseForStudent(w http.ResponseWriter, r *http.Re
 		return
 	}
 }
+
+func (app *application) reviewExcuseForStudent(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	markID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if markID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMark.Error())
+		return
+	}
+
+	var input struct {
+		Status  string  `json:"status"`
+		Comment *string `json:"comment"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(input.Status == data.ExcuseAccepted || input.Status == data.ExcuseRejected, "status", "must be provided and valid")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	if input.Comment != nil && *input.Comment == "" {
+		input.Comment = nil
+	}
+
+	mark, err := app.models.Marks.GetMarkAndExcuseByID(markID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchMark):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if *mark.Type != data.MarkAbsent {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMark.Error())
+		return
+	}
+
+	canReview, err := app.canReviewExcuse(sessionUser, *mark.UserID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	if !canReview {
+		app.notAllowed(w, r)
+		return
+	}
+
+	if mark.Excuse == nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchExcuse.Error())
+		return
+	}
+
+	err = app.models.Absences.ReviewExcuse(mark.ID, sessionUser.ID, input.Status, input.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrExcuseAlreadyReviewed):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getExcusesForTeacher(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	teacherID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if teacherID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	if teacherID != sessionUser.ID && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = data.ExcusePending
+	}
+
+	if status != data.ExcusePending && status != data.ExcuseAccepted && status != data.ExcuseRejected {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, data.ErrNoSuchExcuseStatus.Error())
+		return
+	}
+
+	absences, err := app.models.Absences.GetExcusedAbsencesForClassTeacher(teacherID, status)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"absences": absences})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getAttendanceForStudent(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if year < 1 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, "not valid year")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	student, err := app.models.Users.GetStudentByID(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUser):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if sessionUser.ID != student.ID && *sessionUser.Role != data.RoleAdministrator {
+		ok, err := app.models.Users.IsUserTeacherOrParentOfStudent(student.ID, sessionUser.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+	}
+
+	attendance, err := app.models.Absences.GetAttendanceForStudent(student.ID, year)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"attendance": attendance})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) createAbsenceNotice(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	var input struct {
+		StartDate types.Date `json:"start_date"`
+		EndDate   types.Date `json:"end_date"`
+		Reason    string     `json:"reason"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(input.StartDate.Time != nil, "start_date", "must be provided")
+	v.Check(input.EndDate.Time != nil, "end_date", "must be provided")
+	v.Check(input.Reason != "", "reason", "must be provided")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	v.Check(!input.EndDate.Before(*input.StartDate.Time), "end_date", "must not be before start date")
+	v.Check(!input.EndDate.Before(today), "end_date", "must not be in the past")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	student, err := app.models.Users.GetStudentByID(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUser):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if *sessionUser.Role != data.RoleAdministrator {
+		ok, err := app.models.Users.IsUserTeacherOrParentOfStudent(student.ID, sessionUser.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+	}
+
+	notice := &data.AbsenceNotice{
+		StudentID: &student.ID,
+		UserID:    &sessionUser.ID,
+		StartDate: &input.StartDate,
+		EndDate:   &input.EndDate,
+		Reason:    &input.Reason,
+		CreatedAt: helpers.ToPtr(time.Now().UTC()),
+	}
+
+	err = app.models.Absences.InsertAbsenceNotice(notice)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"absence_notice": notice})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getAbsenceNoticesForStudent(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	student, err := app.models.Users.GetStudentByID(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUser):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if sessionUser.ID != student.ID && *sessionUser.Role != data.RoleAdministrator {
+		ok, err := app.models.Users.IsUserTeacherOrParentOfStudent(student.ID, sessionUser.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+	}
+
+	notices, err := app.models.Absences.GetAbsenceNoticesForStudent(student.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"absence_notices": notices})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteAbsenceNotice(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	noticeID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if noticeID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAbsenceNotice.Error())
+		return
+	}
+
+	notice, err := app.models.Absences.GetAbsenceNoticeByID(noticeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchAbsenceNotice):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if *notice.UserID != sessionUser.ID && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	err = app.models.Absences.DeleteAbsenceNotice(notice.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
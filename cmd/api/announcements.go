@@ -0,0 +1,434 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slices"
+)
+
+type audienceInput struct {
+	Type    string  `json:"type"`
+	Role    *string `json:"role"`
+	ClassID *int    `json:"class_id"`
+	GroupID *int    `json:"group_id"`
+}
+
+// checks the audiences and turns them into the stored form, keeping only the field the type uses
+func (app *application) validateAnnouncementAudiences(v *validator.Validator, input []*audienceInput) ([]*data.AnnouncementAudience, error) {
+	v.Check(len(input) > 0, "audiences", "must be provided")
+
+	var allClassIDs, allGroupIDs []int
+	var err error
+
+	audiences := []*data.AnnouncementAudience{}
+
+	for i, in := range input {
+		audience := &data.AnnouncementAudience{Type: helpers.ToPtr(in.Type)}
+		field := fmt.Sprintf("audiences[%d]", i)
+
+		switch in.Type {
+		case data.AudienceEveryone:
+		case data.AudienceRole:
+			v.Check(in.Role != nil && slices.Contains([]string{data.RoleAdministrator, data.RoleTeacher, data.RoleParent, data.RoleStudent}, *in.Role), field, "invalid role")
+			audience.Role = in.Role
+		case data.AudienceClass, data.AudienceClassParents:
+			if allClassIDs == nil {
+				allClassIDs, err = app.models.Classes.GetAllClassIDs()
+				if err != nil {
+					return nil, err
+				}
+			}
+			v.Check(in.ClassID != nil && slices.Contains(allClassIDs, *in.ClassID), field, data.ErrNoSuchClass.Error())
+			audience.ClassID = in.ClassID
+		case data.AudienceGroup:
+			if allGroupIDs == nil {
+				allGroupIDs, err = app.models.Groups.GetAllGroupIDs()
+				if err != nil {
+					return nil, err
+				}
+			}
+			v.Check(in.GroupID != nil && slices.Contains(allGroupIDs, *in.GroupID), field, data.ErrNoSuchGroup.Error())
+			audience.GroupID = in.GroupID
+		default:
+			v.Add(field, "invalid type")
+		}
+
+		audiences = append(audiences, audience)
+	}
+
+	return audiences, nil
+}
+
+// fetches the announcement from the URL and checks that the user made it or is an administrator
+func (app *application) getAnnouncementForAuthor(w http.ResponseWriter, r *http.Request) (*data.AnnouncementExt, bool) {
+	sessionUser := app.getUserFromContext(r)
+
+	announcementID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if announcementID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAnnouncement.Error())
+		return nil, false
+	}
+
+	announcement, err := app.models.Announcements.GetAnnouncementByID(announcementID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchAnnouncement):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	if (announcement.UserID == nil || *announcement.UserID != sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return nil, false
+	}
+
+	return announcement, true
+}
+
+func (app *application) createAnnouncement(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	var input struct {
+		Title       string           `json:"title"`
+		Body        string           `json:"body"`
+		Audiences   []*audienceInput `json:"audiences"`
+		PublishAt   *time.Time       `json:"publish_at"`
+		ExpiresAt   *time.Time       `json:"expires_at"`
+		RequiresAck bool             `json:"requires_ack"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	currentTime := time.Now().UTC()
+
+	if input.PublishAt == nil {
+		input.PublishAt = &currentTime
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+
+	v := validator.NewValidator()
+
+	v.Check(input.Title != "", "title", "must be provided")
+	v.Check(input.Body != "", "body", "must be provided")
+	v.Check(input.ExpiresAt == nil || input.ExpiresAt.After(*input.PublishAt), "expires_at", "must be after publish_at")
+
+	audiences, err := app.validateAnnouncementAudiences(v, input.Audiences)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	announcement := &data.Announcement{
+		UserID:      &sessionUser.ID,
+		Title:       &input.Title,
+		Body:        &input.Body,
+		PublishAt:   input.PublishAt,
+		ExpiresAt:   input.ExpiresAt,
+		RequiresAck: &input.RequiresAck,
+		CreatedAt:   &currentTime,
+		UpdatedAt:   &currentTime,
+	}
+
+	tx, err := app.models.Announcements.DB.Begin()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = app.models.Announcements.InsertAnnouncement(tx, announcement)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.models.Announcements.SetAnnouncementAudiences(tx, announcement.ID, audiences)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"announcement": announcement})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcement, ok := app.getAnnouncementForAuthor(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title       *string           `json:"title"`
+		Body        *string           `json:"body"`
+		Audiences   *[]*audienceInput `json:"audiences"`
+		PublishAt   *time.Time        `json:"publish_at"`
+		ExpiresAt   *time.Time        `json:"expires_at"`
+		RequiresAck *bool             `json:"requires_ack"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.Title != nil {
+		announcement.Title = helpers.ToPtr(strings.TrimSpace(*input.Title))
+	}
+	if input.Body != nil {
+		announcement.Body = input.Body
+	}
+	if input.PublishAt != nil {
+		announcement.PublishAt = input.PublishAt
+	}
+	if input.ExpiresAt != nil {
+		announcement.ExpiresAt = input.ExpiresAt
+	}
+	if input.RequiresAck != nil {
+		announcement.RequiresAck = input.RequiresAck
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*announcement.Title != "", "title", "must be provided")
+	v.Check(*announcement.Body != "", "body", "must be provided")
+	v.Check(announcement.ExpiresAt == nil || announcement.ExpiresAt.After(*announcement.PublishAt), "expires_at", "must be after publish_at")
+
+	var audiences []*data.AnnouncementAudience
+	if input.Audiences != nil {
+		audiences, err = app.validateAnnouncementAudiences(v, *input.Audiences)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	announcement.UpdatedAt = helpers.ToPtr(time.Now().UTC())
+
+	tx, err := app.models.Announcements.DB.Begin()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = app.models.Announcements.UpdateAnnouncement(tx, &announcement.Announcement)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if input.Audiences != nil {
+		err = app.models.Announcements.SetAnnouncementAudiences(tx, announcement.ID, audiences)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcement, ok := app.getAnnouncementForAuthor(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.models.Announcements.DeleteAnnouncement(announcement.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// administrators see all announcements, teachers the ones they made
+func (app *application) getAnnouncements(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	var authorID *int
+	if *sessionUser.Role != data.RoleAdministrator {
+		authorID = &sessionUser.ID
+	}
+
+	announcements, err := app.models.Announcements.GetAnnouncements(authorID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"announcements": announcements})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getAnnouncementReceipts(w http.ResponseWriter, r *http.Request) {
+	announcement, ok := app.getAnnouncementForAuthor(w, r)
+	if !ok {
+		return
+	}
+
+	receipts, err := app.models.Announcements.GetAnnouncementReceipts(announcement.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"announcement": announcement, "receipts": receipts})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getAnnouncementsForUser(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	announcements, err := app.models.Announcements.GetAnnouncementsForUser(sessionUser.ID, unreadOnly)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	unread, err := app.models.Announcements.CountUnreadAnnouncementsForUser(sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"announcements": announcements, "unread": unread})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// fetches the announcement from the URL and checks that it is shown to the user
+func (app *application) getAnnouncementForAudience(w http.ResponseWriter, r *http.Request) (*data.AnnouncementExt, bool) {
+	sessionUser := app.getUserFromContext(r)
+
+	announcementID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if announcementID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAnnouncement.Error())
+		return nil, false
+	}
+
+	announcement, err := app.models.Announcements.GetAnnouncementByID(announcementID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchAnnouncement):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	ok, err := app.models.Announcements.IsAnnouncementForUser(announcement.ID, sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return nil, false
+	}
+	if !ok {
+		app.notAllowed(w, r)
+		return nil, false
+	}
+
+	return announcement, true
+}
+
+func (app *application) markAnnouncementRead(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	announcement, ok := app.getAnnouncementForAudience(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.models.Announcements.SetAnnouncementRead(announcement.ID, sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) acknowledgeAnnouncement(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	announcement, ok := app.getAnnouncementForAudience(w, r)
+	if !ok {
+		return
+	}
+
+	if !*announcement.RequiresAck {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, data.ErrAcknowledgementRequired.Error())
+		return
+	}
+
+	err := app.models.Announcements.SetAnnouncementAcknowledged(announcement.ID, sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -18,6 +19,31 @@ type AssignmentsByDate struct {
 	Assignments []*data.AssignmentExt `json:"assignments"`
 }
 
+// target students must be in the journal and target groups must belong to it
+func (app *application) validateAssignmentTargets(v *validator.Validator, journalID int, studentIDs, groupIDs []int) error {
+	if len(studentIDs) > 0 {
+		allStudentIDs, err := app.models.Journals.GetStudentIDsForJournal(journalID)
+		if err != nil {
+			return err
+		}
+
+		badStudentIDs := helpers.VerifyExistsInSlice(studentIDs, allStudentIDs)
+		v.Check(badStudentIDs == nil, "student_ids", fmt.Sprintf("%s: %v", data.ErrUserNotInJournal.Error(), badStudentIDs))
+	}
+
+	if len(groupIDs) > 0 {
+		allGroupIDs, err := app.models.JournalGroups.GetJournalGroupIDsForJournal(journalID)
+		if err != nil {
+			return err
+		}
+
+		badGroupIDs := helpers.VerifyExistsInSlice(groupIDs, allGroupIDs)
+		v.Check(badGroupIDs == nil, "group_ids", fmt.Sprintf("%s: %v", data.ErrNoSuchJournalGroup.Error(), badGroupIDs))
+	}
+
+	return nil
+}
+
 func (app *application) getAssignment(w http.ResponseWriter, r *http.Request) {
 	sessionUser := app.getUserFromContext(r)
 
@@ -68,6 +94,10 @@ func (app *application) createAssignment(w http.ResponseWriter, r *http.Request)
 		Description string     `json:"description"`
 		Deadline    types.Date `json:"deadline"`
 		Type        string     `json:"type"`
+		StudentIDs  []int      `json:"student_ids"`
+		GroupIDs    []int      `json:"group_ids"`
+		Draft       bool       `json:"draft"`
+		PublishAt   *time.Time `json:"publish_at"`
 	}
 
 	err := app.inputJSON(w, r, &input)
@@ -80,6 +110,9 @@ func (app *application) createAssignment(w http.ResponseWriter, r *http.Request)
 	v.Check(input.JournalID > 0, "journal_id", "must be provided and valid")
 	v.Check(input.Type == data.AssignmentHomework || input.Type == data.AssignmentTest, "type", "must be provided and valid")
 	v.Check(input.Deadline.After(time.Now().UTC()), "deadline", "must not be in the past")
+	if input.PublishAt != nil && input.Deadline.Time != nil {
+		v.Check(input.PublishAt.Before(input.Deadline.AddDate(0, 0, 1)), "publish_at", "must not be after deadline")
+	}
 
 	if !v.Valid() {
 		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
@@ -96,6 +129,8 @@ func (app *application) createAssignment(w http.ResponseWriter, r *http.Request)
 			Type:        &input.Type,
 			CreatedAt:   &time,
 			UpdatedAt:   &time,
+			Draft:       &input.Draft,
+			PublishAt:   input.PublishAt,
 		},
 	}
 
@@ -120,7 +155,51 @@ func (app *application) createAssignment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = app.models.Assignments.InsertAssignment(assignment)
+	err = app.validateAssignmentTargets(v, journal.ID, input.StudentIDs, input.GroupIDs)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	targetIDs, err := app.getTargetStudentIDs(journal.ID, input.StudentIDs, input.GroupIDs)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	warnings, ok := app.checkAssignmentWorkload(w, r, &assignment.Assignment, targetIDs)
+	if !ok {
+		return
+	}
+
+	// inserted together with its targets, so the scheduler can't publish it to the whole journal in between
+	tx, err := app.models.Assignments.DB.Begin()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = app.models.Assignments.InsertAssignment(tx, assignment)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if len(input.StudentIDs) > 0 || len(input.GroupIDs) > 0 {
+		err = app.models.Assignments.SetAssignmentTargets(tx, assignment.ID, input.StudentIDs, input.GroupIDs)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
@@ -132,7 +211,18 @@ func (app *application) createAssignment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = app.outputJSON(w, http.StatusCreated, envelope{"message": "success"})
+	// assignments without a publishing time go out right away, the scheduler retries on failure
+	err = app.publishAssignments()
+	if err != nil {
+		app.errorLogger.Println(r.Method, redactedURI(r.URL), err)
+	}
+
+	env := envelope{"message": "success"}
+	if len(warnings) > 0 {
+		env["workload_warnings"] = warnings
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, env)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 	}
@@ -184,6 +274,10 @@ func (app *application) updateAssignment(w http.ResponseWriter, r *http.Request)
 		Description *string     `json:"description"`
 		Deadline    *types.Date `json:"deadline"`
 		Type        *string     `json:"type"`
+		StudentIDs  *[]int      `json:"student_ids"`
+		GroupIDs    *[]int      `json:"group_ids"`
+		Draft       *bool       `json:"draft"`
+		PublishAt   *time.Time  `json:"publish_at"`
 	}
 
 	err = app.inputJSON(w, r, &input)
@@ -192,6 +286,12 @@ func (app *application) updateAssignment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// the workload is only checked again when the edit moves the test or makes one
+	workloadChanged := (input.Deadline != nil && !input.Deadline.Equal(*assignment.Deadline.Time)) ||
+		(input.Type != nil && *input.Type != *assignment.Type) ||
+		(input.Draft != nil && *input.Draft != *assignment.Draft) ||
+		input.StudentIDs != nil || input.GroupIDs != nil
+
 	if input.Description != nil {
 		assignment.Description = input.Description
 	}
@@ -201,20 +301,87 @@ func (app *application) updateAssignment(w http.ResponseWriter, r *http.Request)
 	if input.Type != nil {
 		assignment.Type = input.Type
 	}
+	if input.Draft != nil {
+		assignment.Draft = input.Draft
+	}
+	if input.PublishAt != nil {
+		assignment.PublishAt = input.PublishAt
+	}
 
 	v := validator.NewValidator()
 
+	v.Check(assignment.PublishedAt == nil || (input.Draft == nil && input.PublishAt == nil), "publish_at", "assignment has already been published")
+	if assignment.PublishAt != nil {
+		v.Check(assignment.PublishAt.Before(assignment.Deadline.AddDate(0, 0, 1)), "publish_at", "must not be after deadline")
+	}
+
 	v.Check(*assignment.Type == data.AssignmentHomework || *assignment.Type == data.AssignmentTest, "type", "must be provided and valid")
 	v.Check(assignment.Deadline.After(time.Now().UTC()), "deadline", "must not be in the past")
 
+	var studentIDs, groupIDs []int
+	if input.StudentIDs != nil {
+		studentIDs = *input.StudentIDs
+	}
+	if input.GroupIDs != nil {
+		groupIDs = *input.GroupIDs
+	}
+
+	err = app.validateAssignmentTargets(v, journal.ID, studentIDs, groupIDs)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
 	if !v.Valid() {
 		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
 		return
 	}
 
+	var warnings []*WorkloadViolation
+	if workloadChanged {
+		var targetIDs []int
+		if input.StudentIDs != nil || input.GroupIDs != nil {
+			targetIDs, err = app.getTargetStudentIDs(journal.ID, studentIDs, groupIDs)
+		} else {
+			targetIDs, err = app.models.Assignments.GetStudentIDsForAssignment(assignment.ID)
+		}
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		var ok bool
+		warnings, ok = app.checkAssignmentWorkload(w, r, &assignment.Assignment, targetIDs)
+		if !ok {
+			return
+		}
+	}
+
 	assignment.UpdatedAt = helpers.ToPtr(time.Now().UTC())
 
-	err = app.models.Assignments.UpdateAssignment(assignment)
+	tx, err := app.models.Assignments.DB.Begin()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = app.models.Assignments.UpdateAssignment(tx, assignment)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	// leaving both lists out keeps the current targets, empty lists target the whole journal again
+	if input.StudentIDs != nil || input.GroupIDs != nil {
+		err = app.models.Assignments.SetAssignmentTargets(tx, assignment.ID, studentIDs, groupIDs)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
@@ -226,7 +393,19 @@ func (app *application) updateAssignment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if assignment.PublishedAt == nil {
+		err = app.publishAssignments()
+		if err != nil {
+			app.errorLogger.Println(r.Method, redactedURI(r.URL), err)
+		}
+	}
+
+	env := envelope{"message": "success"}
+	if len(warnings) > 0 {
+		env["workload_warnings"] = warnings
+	}
+
+	err = app.outputJSON(w, http.StatusOK, env)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 	}
@@ -447,7 +626,7 @@ func (app *application) setAssignmentDoneForStudent(w http.ResponseWriter, r *ht
 		return
 	}
 
-	ok, err := app.models.Journals.IsUserInJournal(sessionUser.ID, *assignment.JournalID)
+	ok, err := app.models.Assignments.IsAssignmentForStudent(sessionUser.ID, assignment.ID)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
@@ -505,7 +684,7 @@ func (app *application) removeAssignmentDoneForStudent(w http.ResponseWriter, r
 		return
 	}
 
-	ok, err := app.models.Journals.IsUserInJournal(sessionUser.ID, *assignment.JournalID)
+	ok, err := app.models.Assignments.IsAssignmentForStudent(sessionUser.ID, assignment.ID)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
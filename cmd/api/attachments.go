@@ -0,0 +1,590 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/storage"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slices"
+)
+
+func (app *application) canViewJournal(user *data.UserExt, journal *data.JournalExt) (bool, error) {
+	if *user.Role == data.RoleAdministrator || journal.IsUserTeacherOfJournal(user.ID) {
+		return true, nil
+	}
+
+	switch *user.Role {
+	case data.RoleStudent:
+		return app.models.Journals.IsUserInJournal(user.ID, journal.ID)
+	case data.RoleParent:
+		return app.models.Journals.DoesParentHaveChildInJournal(user.ID, journal.ID)
+	}
+
+	return false, nil
+}
+
+// whether the assignment is given to the student, or to one of the parent's children
+func (app *application) isAssignmentForUser(user *data.UserExt, assignmentID int) (bool, error) {
+	switch *user.Role {
+	case data.RoleStudent:
+		return app.models.Assignments.IsAssignmentForStudent(user.ID, assignmentID)
+	case data.RoleParent:
+		children, err := app.models.Users.GetChildrenForParent(user.ID)
+		if err != nil {
+			return false, err
+		}
+
+		for _, c := range children {
+			ok, err := app.models.Assignments.IsAssignmentForStudent(c.ID, assignmentID)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// checks what the user can do with attachments of the target the attachment is linked to
+func (app *application) attachmentPermissions(user *data.UserExt, a *data.Attachment) (canView, canUpload bool, err error) {
+	isAdmin := *user.Role == data.RoleAdministrator
+
+	switch {
+	case a.ExcuseID != nil:
+		mark, err := app.models.Marks.GetMarkAndExcuseByID(*a.ExcuseID)
+		if err != nil {
+			return false, false, err
+		}
+		if *mark.Type != data.MarkAbsent {
+			return false, false, data.ErrNoSuchMark
+		}
+		if mark.Excuse == nil {
+			return false, false, data.ErrNoSuchExcuse
+		}
+
+		if isAdmin {
+			return true, true, nil
+		}
+
+		ok, err := app.models.Users.IsUserTeacherOrParentOfStudent(*mark.UserID, user.ID)
+		if err != nil {
+			return false, false, err
+		}
+
+		return ok || user.ID == *mark.UserID, ok, nil
+	case a.AssignmentID != nil, a.LessonID != nil:
+		var journalID int
+		published := true
+
+		if a.AssignmentID != nil {
+			assignment, err := app.models.Assignments.GetAssignmentByID(*a.AssignmentID)
+			if err != nil {
+				return false, false, err
+			}
+			journalID = *assignment.JournalID
+			published = assignment.PublishedAt != nil
+		} else {
+			lesson, err := app.models.Lessons.GetLessonByID(*a.LessonID)
+			if err != nil {
+				return false, false, err
+			}
+			journalID = lesson.Journal.ID
+		}
+
+		journal, err := app.models.Journals.GetJournalByID(journalID)
+		if err != nil {
+			return false, false, err
+		}
+
+		if a.LessonID != nil {
+			canUpload, err = app.canTeachLesson(user, journal, *a.LessonID)
+			if err != nil {
+				return false, false, err
+			}
+		} else {
+			canUpload = isAdmin || journal.IsUserTeacherOfJournal(user.ID)
+		}
+		if canUpload {
+			return true, true, nil
+		}
+
+		// students and parents don't see files of unpublished assignments,
+		// nor of ones given to other students of the journal
+		if a.AssignmentID != nil {
+			if !published {
+				return false, false, nil
+			}
+
+			canView, err = app.isAssignmentForUser(user, *a.AssignmentID)
+			if err != nil {
+				return false, false, err
+			}
+
+			return canView, false, nil
+		}
+
+		canView, err = app.canViewJournal(user, journal)
+		if err != nil {
+			return false, false, err
+		}
+
+		return canView, false, nil
+	case a.MessageID != nil:
+		message, err := app.models.Messaging.GetMessageByID(*a.MessageID)
+		if err != nil {
+			return false, false, err
+		}
+
+		// files of hidden messages are hidden along with the text
+		if message.HiddenAt != nil {
+			return *user.Role == data.RoleAdministrator, false, nil
+		}
+
+		ok, err := app.models.Messaging.IsUserInThread(user.ID, *message.ThreadID)
+		if err != nil {
+			return false, false, err
+		}
+
+		return ok, ok && *message.UserID == user.ID, nil
+	case a.SubmissionID != nil:
+		submission, err := app.models.Submissions.GetSubmissionByID(*a.SubmissionID)
+		if err != nil {
+			return false, false, err
+		}
+
+		// only the student adds files to their own submission
+		if user.ID == *submission.StudentID {
+			return true, true, nil
+		}
+
+		canView, err = app.canViewSubmission(user, submission)
+		if err != nil {
+			return false, false, err
+		}
+
+		return canView, false, nil
+	}
+
+	return false, false, data.ErrNoSuchAttachment
+}
+
+func (app *application) writeAttachmentTargetError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, data.ErrNoSuchMark),
+		errors.Is(err, data.ErrNoSuchExcuse),
+		errors.Is(err, data.ErrNoSuchAssignment),
+		errors.Is(err, data.ErrNoSuchLesson),
+		errors.Is(err, data.ErrNoSuchJournal),
+		errors.Is(err, data.ErrNoSuchMessage),
+		errors.Is(err, data.ErrNoSuchSubmission),
+		errors.Is(err, data.ErrNoSuchAttachment):
+		app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+	default:
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) uploadAttachment(w http.ResponseWriter, r *http.Request, target *data.Attachment) {
+	sessionUser := app.getUserFromContext(r)
+
+	_, canUpload, err := app.attachmentPermissions(sessionUser, target)
+	if err != nil {
+		app.writeAttachmentTargetError(w, r, err)
+		return
+	}
+	if !canUpload {
+		app.notAllowed(w, r)
+		return
+	}
+
+	maxSize := app.config.Storage.MaxUploadSize << 20
+
+	// leave some room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+
+	err = r.ParseMultipartForm(1 << 20)
+	if err != nil {
+		switch {
+		case errors.As(err, new(*http.MaxBytesError)):
+			app.writeErrorResponse(w, r, http.StatusRequestEntityTooLarge, data.ErrAttachmentTooLarge.Error())
+		default:
+			app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, envelope{"file": "must be provided"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxSize {
+		app.writeErrorResponse(w, r, http.StatusRequestEntityTooLarge, data.ErrAttachmentTooLarge.Error())
+		return
+	}
+
+	fileName := filepath.Base(filepath.Clean("/" + header.Filename))
+
+	v := validator.NewValidator()
+
+	v.Check(header.Size > 0, "file", "must not be empty")
+	v.Check(fileName != "/" && fileName != ".", "file", "must have a name")
+	v.Check(len(fileName) <= 255, "file", "name must not be longer than 255 bytes")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	// don't trust the client-provided content type, sniff it instead
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	contentType := http.DetectContentType(sniff[:n])
+	if !slices.Contains(app.config.Storage.AllowedContentTypes, contentType) {
+		app.writeErrorResponse(w, r, http.StatusUnsupportedMediaType, data.ErrAttachmentTypeForbidden.Error())
+		return
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	key, err := storage.NewKey()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.storage.Put(r.Context(), key, file, header.Size, contentType)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	attachment := &data.Attachment{
+		UserID:       &sessionUser.ID,
+		FileName:     &fileName,
+		ContentType:  &contentType,
+		Size:         helpers.ToPtr(int(header.Size)),
+		StorageKey:   &key,
+		ExcuseID:     target.ExcuseID,
+		AssignmentID: target.AssignmentID,
+		LessonID:     target.LessonID,
+		MessageID:    target.MessageID,
+		SubmissionID: target.SubmissionID,
+		CreatedAt:    helpers.ToPtr(time.Now().UTC()),
+	}
+
+	err = app.models.Attachments.InsertAttachment(attachment)
+	if err != nil {
+		if err := app.storage.Delete(r.Context(), key); err != nil {
+			app.errorLogger.Println(r.Method, redactedURI(r.URL), err)
+		}
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if target.SubmissionID != nil {
+		err = app.models.Submissions.SetSubmissionSubmitted(*target.SubmissionID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"attachment": attachment})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) listAttachments(w http.ResponseWriter, r *http.Request, target *data.Attachment) {
+	sessionUser := app.getUserFromContext(r)
+
+	canView, _, err := app.attachmentPermissions(sessionUser, target)
+	if err != nil {
+		app.writeAttachmentTargetError(w, r, err)
+		return
+	}
+	if !canView {
+		app.notAllowed(w, r)
+		return
+	}
+
+	var attachments []*data.AttachmentExt
+
+	switch {
+	case target.ExcuseID != nil:
+		attachments, err = app.models.Attachments.GetAttachmentsForExcuse(*target.ExcuseID)
+	case target.AssignmentID != nil:
+		attachments, err = app.models.Attachments.GetAttachmentsForAssignment(*target.AssignmentID)
+	case target.LessonID != nil:
+		attachments, err = app.models.Attachments.GetAttachmentsForLesson(*target.LessonID)
+	case target.MessageID != nil:
+		attachments, err = app.models.Attachments.GetAttachmentsForMessage(*target.MessageID)
+	case target.SubmissionID != nil:
+		attachments, err = app.models.Attachments.GetAttachmentsForSubmission(*target.SubmissionID)
+	}
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"attachments": attachments})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) uploadExcuseAttachment(w http.ResponseWriter, r *http.Request) {
+	markID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if markID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMark.Error())
+		return
+	}
+
+	app.uploadAttachment(w, r, &data.Attachment{ExcuseID: &markID})
+}
+
+func (app *application) getExcuseAttachments(w http.ResponseWriter, r *http.Request) {
+	markID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if markID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMark.Error())
+		return
+	}
+
+	app.listAttachments(w, r, &data.Attachment{ExcuseID: &markID})
+}
+
+func (app *application) uploadAssignmentAttachment(w http.ResponseWriter, r *http.Request) {
+	assignmentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if assignmentID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAssignment.Error())
+		return
+	}
+
+	app.uploadAttachment(w, r, &data.Attachment{AssignmentID: &assignmentID})
+}
+
+func (app *application) getAssignmentAttachments(w http.ResponseWriter, r *http.Request) {
+	assignmentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if assignmentID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAssignment.Error())
+		return
+	}
+
+	app.listAttachments(w, r, &data.Attachment{AssignmentID: &assignmentID})
+}
+
+func (app *application) uploadLessonAttachment(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if lessonID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchLesson.Error())
+		return
+	}
+
+	app.uploadAttachment(w, r, &data.Attachment{LessonID: &lessonID})
+}
+
+func (app *application) getLessonAttachments(w http.ResponseWriter, r *http.Request) {
+	lessonID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if lessonID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchLesson.Error())
+		return
+	}
+
+	app.listAttachments(w, r, &data.Attachment{LessonID: &lessonID})
+}
+
+func (app *application) uploadMessageAttachment(w http.ResponseWriter, r *http.Request) {
+	messageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if messageID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMessage.Error())
+		return
+	}
+
+	app.uploadAttachment(w, r, &data.Attachment{MessageID: &messageID})
+}
+
+func (app *application) getMessageAttachments(w http.ResponseWriter, r *http.Request) {
+	messageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if messageID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMessage.Error())
+		return
+	}
+
+	app.listAttachments(w, r, &data.Attachment{MessageID: &messageID})
+}
+
+func (app *application) uploadSubmissionAttachment(w http.ResponseWriter, r *http.Request) {
+	submissionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if submissionID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchSubmission.Error())
+		return
+	}
+
+	app.uploadAttachment(w, r, &data.Attachment{SubmissionID: &submissionID})
+}
+
+func (app *application) getSubmissionAttachments(w http.ResponseWriter, r *http.Request) {
+	submissionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if submissionID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchSubmission.Error())
+		return
+	}
+
+	app.listAttachments(w, r, &data.Attachment{SubmissionID: &submissionID})
+}
+
+func (app *application) downloadAttachment(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	attachmentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if attachmentID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAttachment.Error())
+		return
+	}
+
+	attachment, err := app.models.Attachments.GetAttachmentByID(attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchAttachment):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	canView, _, err := app.attachmentPermissions(sessionUser, attachment)
+	if err != nil {
+		app.writeAttachmentTargetError(w, r, err)
+		return
+	}
+	if !canView {
+		app.notAllowed(w, r)
+		return
+	}
+
+	// backends that support it hand out a short-lived URL, so the file doesn't go through us
+	url, err := app.storage.PresignGet(*attachment.StorageKey, *attachment.FileName, 5*time.Minute)
+	if err == nil {
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, url, http.StatusFound)
+		return
+	}
+	if !errors.Is(err, storage.ErrPresignNotSupported) {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	file, err := app.storage.Get(r.Context(), *attachment.StorageKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAttachment.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", *attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(*attachment.Size))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": *attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = io.Copy(w, file)
+	if err != nil {
+		app.errorLogger.Println(r.Method, redactedURI(r.URL), err)
+	}
+}
+
+func (app *application) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	attachmentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if attachmentID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAttachment.Error())
+		return
+	}
+
+	attachment, err := app.models.Attachments.GetAttachmentByID(attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchAttachment):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if *attachment.UserID != sessionUser.ID && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	// the file is removed from storage by the scheduler
+	err = app.models.Attachments.DeleteAttachment(attachment.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// removes the files of deleted attachments from storage, ones that fail are tried again next time
+func (app *application) removeDeletedAttachmentFiles() error {
+	deleted, err := app.models.Attachments.GetDeletedAttachments(100)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deleted {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = app.storage.Delete(ctx, *d.StorageKey)
+		cancel()
+		if err != nil {
+			app.errorLogger.Println("attachments:", err)
+			continue
+		}
+
+		err = app.models.Attachments.RemoveDeletedAttachment(d.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
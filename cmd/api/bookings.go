@@ -0,0 +1,484 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+func (app *application) listAllResources(w http.ResponseWriter, r *http.Request) {
+	resources, err := app.models.Bookings.AllResources()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"resources": resources})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) createResource(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name        string  `json:"name"`
+		Description *string `json:"description"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resource := &data.Resource{
+		Name:        &input.Name,
+		Description: input.Description,
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*resource.Name != "", "name", "must be provided")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.Bookings.InsertResource(resource)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrResourceNameExists):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"resource": resource})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getResourceFromURL(w http.ResponseWriter, r *http.Request) (*data.Resource, bool) {
+	resourceID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if resourceID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchResource.Error())
+		return nil, false
+	}
+
+	resource, err := app.models.Bookings.GetResourceByID(resourceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchResource):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	return resource, true
+}
+
+func (app *application) updateResource(w http.ResponseWriter, r *http.Request) {
+	resource, ok := app.getResourceFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.Name != nil {
+		resource.Name = input.Name
+	}
+	if input.Description != nil {
+		if *input.Description == "" {
+			resource.Description = nil
+		} else {
+			resource.Description = input.Description
+		}
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*resource.Name != "", "name", "must be provided")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.Bookings.UpdateResource(resource)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrResourceNameExists):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteResource(w http.ResponseWriter, r *http.Request) {
+	resource, ok := app.getResourceFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.models.Bookings.DeleteResource(resource.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) createBooking(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	var input struct {
+		RoomID     *int       `json:"room_id"`
+		ResourceID *int       `json:"resource_id"`
+		LessonID   *int       `json:"lesson_id"`
+		ClassID    *int       `json:"class_id"`
+		Title      *string    `json:"title"`
+		Date       types.Date `json:"date"`
+		StartTime  types.Time `json:"start_time"`
+		EndTime    types.Time `json:"end_time"`
+		// book even if the teacher or class is busy at that time
+		Force bool `json:"force"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// a booking for a lesson takes the lesson's date and times
+	if input.LessonID != nil {
+		lesson, err := app.models.Lessons.GetLessonByID(*input.LessonID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrNoSuchLesson):
+				app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+			default:
+				app.writeInternalServerError(w, r, err)
+			}
+			return
+		}
+
+		journal, err := app.models.Journals.GetJournalByID(lesson.Journal.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		ok, err := app.canTeachLesson(sessionUser, journal, lesson.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+
+		input.Date = *lesson.Date
+		if input.StartTime.Time == nil && input.EndTime.Time == nil && lesson.StartTime != nil {
+			input.StartTime = *lesson.StartTime
+			input.EndTime = *lesson.EndTime
+		}
+	}
+
+	booking := &data.Booking{
+		RoomID:     input.RoomID,
+		ResourceID: input.ResourceID,
+		LessonID:   input.LessonID,
+		ClassID:    input.ClassID,
+		UserID:     &sessionUser.ID,
+		Title:      input.Title,
+		Date:       &input.Date,
+		StartTime:  &input.StartTime,
+		EndTime:    &input.EndTime,
+		CreatedAt:  helpers.ToPtr(time.Now().UTC()),
+	}
+
+	v := validator.NewValidator()
+
+	v.Check((booking.RoomID == nil) != (booking.ResourceID == nil), "room_id", "either room or resource must be provided")
+	v.Check(booking.Date.Time != nil, "date", "must be provided")
+	v.Check(booking.StartTime.Time != nil, "start_time", "must be provided")
+	v.Check(booking.EndTime.Time != nil, "end_time", "must be provided")
+	if booking.StartTime.Time != nil && booking.EndTime.Time != nil {
+		v.Check(booking.StartTime.Before(*booking.EndTime.Time), "end_time", "must be after start time")
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	if booking.RoomID != nil {
+		_, err = app.models.Timetable.GetRoomByID(*booking.RoomID)
+	} else {
+		_, err = app.models.Bookings.GetResourceByID(*booking.ResourceID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchRoom), errors.Is(err, data.ErrNoSuchResource):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if booking.ClassID != nil {
+		_, err = app.models.Classes.GetClassByID(*booking.ClassID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrNoSuchClass):
+				app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+			default:
+				app.writeInternalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	conflicts, err := app.models.Bookings.GetConflicts(booking, sessionUser.ID, booking.LessonID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	// double bookings can't be forced
+	var blocking []*data.Conflict
+	for _, c := range conflicts {
+		if !input.Force || c.Type == data.ConflictRoom || c.Type == data.ConflictResource {
+			blocking = append(blocking, c)
+		}
+	}
+
+	if len(blocking) > 0 {
+		err = app.outputJSON(w, http.StatusConflict, envelope{"error": data.ErrBookingConflict.Error(), "conflicts": blocking})
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	// a booking made for the same room or resource in the meantime is refused by the database
+	err = app.models.Bookings.InsertBooking(booking)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrBookingConflict):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"booking": booking, "conflicts": conflicts})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteBooking(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	bookingID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if bookingID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchBooking.Error())
+		return
+	}
+
+	booking, err := app.models.Bookings.GetBookingByID(bookingID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchBooking):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if *booking.UserID != sessionUser.ID && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	err = app.models.Bookings.DeleteBooking(booking.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBookingsForUser(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	from, until, err := readDateRange(r, 7)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bookings, err := app.models.Bookings.GetBookingsForUser(sessionUser.ID, from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"bookings": bookings})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBookingsForRoom(w http.ResponseWriter, r *http.Request) {
+	roomID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if roomID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchRoom.Error())
+		return
+	}
+
+	room, err := app.models.Timetable.GetRoomByID(roomID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchRoom):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	from, until, err := readDateRange(r, 7)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bookings, err := app.models.Bookings.GetBookingsForRoom(room.ID, from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	lessons, err := app.models.Bookings.GetLessonsForRoom(room.ID, from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"room": room, "bookings": bookings, "lessons": lessons})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBookingsForResource(w http.ResponseWriter, r *http.Request) {
+	resource, ok := app.getResourceFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	from, until, err := readDateRange(r, 7)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bookings, err := app.models.Bookings.GetBookingsForResource(resource.ID, from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"resource": resource, "bookings": bookings})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// rooms and resources free for the whole time range
+func (app *application) getAvailability(w http.ResponseWriter, r *http.Request) {
+	date, err := types.ParseDate(r.URL.Query().Get("date"))
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	start, err := types.ParseTime(r.URL.Query().Get("start_time"))
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	end, err := types.ParseTime(r.URL.Query().Get("end_time"))
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !start.Before(*end.Time) {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, "end time must be after start time")
+		return
+	}
+
+	rooms, err := app.models.Bookings.GetAvailableRooms(date, start, end)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	resources, err := app.models.Bookings.GetAvailableResources(date, start, end)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"rooms": rooms, "resources": resources})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
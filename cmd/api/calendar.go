@@ -0,0 +1,323 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+// administrators see events of every class, teachers those of the classes they teach
+// or are class teacher of, students and parents only those of their own classes
+func (app *application) getCalendarClassIDs(user *data.UserExt, yearID int) ([]int, error) {
+	switch *user.Role {
+	case data.RoleAdministrator:
+		return nil, nil
+	case data.RoleTeacher:
+		return app.models.Classes.GetClassIDsForTeacher(user.ID, yearID)
+	case data.RoleStudent:
+		if user.ClassID == nil {
+			return []int{}, nil
+		}
+		return []int{*user.ClassID}, nil
+	case data.RoleParent:
+		children, err := app.models.Users.GetChildrenForParent(user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		classIDs := []int{}
+		for _, c := range children {
+			if c.ClassID != nil {
+				classIDs = append(classIDs, *c.ClassID)
+			}
+		}
+		return classIDs, nil
+	}
+
+	return []int{}, nil
+}
+
+func (app *application) outputCalendar(w http.ResponseWriter, r *http.Request, year *data.YearExt) {
+	sessionUser := app.getUserFromContext(r)
+
+	classIDs, err := app.getCalendarClassIDs(sessionUser, year.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	entries, err := app.models.Calendar.GetEntriesForYear(year.ID, classIDs)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"year": year, "entries": entries})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getCalendarForYear(w http.ResponseWriter, r *http.Request) {
+	yearID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if yearID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchYear.Error())
+		return
+	}
+
+	year, err := app.models.Years.GetYearByID(yearID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchYear):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.outputCalendar(w, r, year)
+}
+
+func (app *application) getCalendarForCurrentYear(w http.ResponseWriter, r *http.Request) {
+	year, err := app.models.Years.GetCurrentYear()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	} else if year == nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoCurrentYear.Error())
+		return
+	}
+
+	app.outputCalendar(w, r, year)
+}
+
+func (app *application) validateCalendarEntry(v *validator.Validator, e *data.CalendarEntry, year *data.YearExt) {
+	v.Check(*e.Type == data.CalendarHoliday || *e.Type == data.CalendarVacation || *e.Type == data.CalendarEvent, "type", "must be provided and valid")
+	v.Check(*e.Title != "", "title", "must be provided")
+	v.Check(e.ClassID == nil || *e.Type == data.CalendarEvent, "class_id", "only events can be class-specific")
+	v.Check(e.StartDate.Time != nil, "start_date", "must be provided")
+	v.Check(e.EndDate.Time != nil, "end_date", "must be provided")
+
+	if e.StartDate.Time == nil || e.EndDate.Time == nil {
+		return
+	}
+
+	v.Check(!e.EndDate.Before(*e.StartDate.Time), "end_date", "must not be before start date")
+
+	if year.StartDate != nil && year.EndDate != nil {
+		v.Check(!e.StartDate.Before(*year.StartDate.Time) && !e.EndDate.After(*year.EndDate.Time), "start_date", "must be within the year")
+	}
+}
+
+func (app *application) createCalendarEntry(w http.ResponseWriter, r *http.Request) {
+	yearID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if yearID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchYear.Error())
+		return
+	}
+
+	year, err := app.models.Years.GetYearByID(yearID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchYear):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Type        string     `json:"type"`
+		Title       string     `json:"title"`
+		Description *string    `json:"description"`
+		ClassID     *int       `json:"class_id"`
+		StartDate   types.Date `json:"start_date"`
+		EndDate     types.Date `json:"end_date"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry := &data.CalendarEntry{
+		YearID:      &year.ID,
+		ClassID:     input.ClassID,
+		Type:        &input.Type,
+		Title:       &input.Title,
+		Description: input.Description,
+		StartDate:   &input.StartDate,
+		EndDate:     &input.EndDate,
+	}
+
+	v := validator.NewValidator()
+
+	app.validateCalendarEntry(v, entry, year)
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	if entry.ClassID != nil {
+		_, err = app.models.Classes.GetClassByID(*entry.ClassID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrNoSuchClass):
+				app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+			default:
+				app.writeInternalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	err = app.models.Calendar.InsertEntry(entry)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"entry": entry})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateCalendarEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if entryID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchCalendarEntry.Error())
+		return
+	}
+
+	entry, err := app.models.Calendar.GetEntryByID(entryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchCalendarEntry):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	year, err := app.models.Years.GetYearByID(*entry.YearID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	var input struct {
+		Type        *string     `json:"type"`
+		Title       *string     `json:"title"`
+		Description *string     `json:"description"`
+		ClassID     *int        `json:"class_id"`
+		StartDate   *types.Date `json:"start_date"`
+		EndDate     *types.Date `json:"end_date"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.Type != nil {
+		entry.Type = input.Type
+	}
+	if input.Title != nil {
+		entry.Title = input.Title
+	}
+	if input.Description != nil {
+		if *input.Description == "" {
+			entry.Description = nil
+		} else {
+			entry.Description = input.Description
+		}
+	}
+	if input.ClassID != nil {
+		if *input.ClassID == 0 {
+			entry.ClassID = nil
+		} else {
+			entry.ClassID = input.ClassID
+		}
+	}
+	if input.StartDate != nil && input.StartDate.Time != nil {
+		entry.StartDate = input.StartDate
+	}
+	if input.EndDate != nil && input.EndDate.Time != nil {
+		entry.EndDate = input.EndDate
+	}
+
+	v := validator.NewValidator()
+
+	app.validateCalendarEntry(v, entry, year)
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	if input.ClassID != nil && entry.ClassID != nil {
+		_, err = app.models.Classes.GetClassByID(*entry.ClassID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrNoSuchClass):
+				app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+			default:
+				app.writeInternalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	err = app.models.Calendar.UpdateEntry(entry)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteCalendarEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if entryID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchCalendarEntry.Error())
+		return
+	}
+
+	entry, err := app.models.Calendar.GetEntryByID(entryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchCalendarEntry):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.Calendar.DeleteEntry(entry.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+const completionDefaultDays = 90
+
+func (app *application) getCompletionForAssignment(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	assignmentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if assignmentID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAssignment.Error())
+		return
+	}
+
+	assignment, err := app.models.Assignments.GetAssignmentByID(assignmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchAssignment):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(*assignment.JournalID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	// class teachers only see their own students
+	var classTeacherID *int
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		ok, err := app.models.Journals.IsClassTeacherInJournal(sessionUser.ID, journal.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+		classTeacherID = &sessionUser.ID
+	}
+
+	students, err := app.models.Assignments.GetCompletionForAssignment(assignment.ID, classTeacherID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	rate := data.CompletionRate{Assigned: len(students)}
+	for _, s := range students {
+		if s.Done {
+			rate.Done++
+		}
+	}
+	if rate.Assigned > 0 {
+		rate.Rate = float64(rate.Done) / float64(rate.Assigned)
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"assignment": assignment, "completion": rate, "students": students})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getCompletionForJournal(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	journalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if journalID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchJournal.Error())
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(journalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	// class teachers only see their own students
+	var classTeacherID *int
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		ok, err := app.models.Journals.IsClassTeacherInJournal(sessionUser.ID, journal.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+		classTeacherID = &sessionUser.ID
+	}
+
+	// defaults to assignments due in the last 90 days
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := &types.Date{Time: helpers.ToPtr(today.AddDate(0, 0, -completionDefaultDays))}
+	until := &types.Date{Time: &today}
+
+	fromDate := r.URL.Query().Get("from")
+	if fromDate != "" {
+		from, err = types.ParseDate(fromDate)
+		if err != nil {
+			app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	untilDate := r.URL.Query().Get("until")
+	if untilDate != "" {
+		until, err = types.ParseDate(untilDate)
+		if err != nil {
+			app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if until.Before(*from.Time) {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, "until must not be before from")
+		return
+	}
+
+	assignments, err := app.models.Assignments.GetAssignmentCompletionRatesForJournal(journal.ID, classTeacherID, from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	students, err := app.models.Assignments.GetStudentCompletionRatesForJournal(journal.ID, classTeacherID, from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	// completion over time, by the week the assignments were due
+	weeks := []*data.WeekCompletionRate{}
+
+	monday, _ := weekBounds(*from.Time)
+	for d := monday; !d.After(*until.Time); d = d.AddDate(0, 0, 7) {
+		week := &data.WeekCompletionRate{Start: &types.Date{Time: helpers.ToPtr(d)}}
+		sunday := d.AddDate(0, 0, 6)
+
+		for _, a := range assignments {
+			if !a.Deadline.Before(d) && !a.Deadline.After(sunday) {
+				week.Add(a.CompletionRate)
+			}
+		}
+
+		weeks = append(weeks, week)
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"from": from, "until": until, "assignments": assignments, "students": students, "weeks": weeks})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getCompletionForStudent(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	student, err := app.models.Users.GetStudentByID(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUser):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	// class teachers see all of the student's journals, other teachers only the ones they teach
+	var teacherID *int
+	if *sessionUser.Role != data.RoleAdministrator {
+		ok, err := app.models.Users.IsUserTeacherOfStudent(student.ID, sessionUser.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			teacherID = &sessionUser.ID
+		}
+	}
+
+	journals, err := app.models.Assignments.GetJournalCompletionRatesForStudent(student.ID, teacherID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"student": student, "journals": journals})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -5,13 +5,20 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
 type configuration struct {
-	Web      web      `toml:"web"`
-	Database database `toml:"database"`
+	Web       web         `toml:"web"`
+	Database  database    `toml:"database"`
+	Storage   fileStorage `toml:"storage"`
+	Workload  workload    `toml:"workload"`
+	Email     email       `toml:"email"`
+	Push      push        `toml:"push"`
+	Messaging messaging   `toml:"messaging"`
+	School    school      `toml:"school"`
 }
 
 type web struct {
@@ -27,6 +34,57 @@ type database struct {
 	Name     string `toml:"dbname"`
 }
 
+type workload struct {
+	MaxTestsPerDay  int  `toml:"max_tests_per_day"`
+	MaxTestsPerWeek int  `toml:"max_tests_per_week"`
+	Enforce         bool `toml:"enforce"`
+}
+
+type email struct {
+	Enabled      bool   `toml:"enabled"`
+	SMTPHost     string `toml:"smtp_host"`
+	SMTPPort     int    `toml:"smtp_port"`
+	SMTPUsername string `toml:"smtp_username"`
+	SMTPPassword string `toml:"smtp_password"`
+	From         string `toml:"from"`
+	DigestHour   int    `toml:"digest_hour"`
+	ReplyDomain  string `toml:"reply_domain"`
+	ReplySecret  string `toml:"reply_secret"`
+	InboundToken string `toml:"inbound_token"`
+	// Authentication-Results from this server are trusted to have checked the sender
+	InboundAuthservID string `toml:"inbound_authserv_id"`
+}
+
+type push struct {
+	Enabled         bool   `toml:"enabled"`
+	VAPIDPrivateKey string `toml:"vapid_private_key"`
+	Subject         string `toml:"subject"`
+	AllowHTTP       bool   `toml:"allow_http"`
+}
+
+type messaging struct {
+	SearchLanguage string `toml:"search_language"`
+}
+
+type school struct {
+	Timezone string `toml:"timezone"`
+
+	location *time.Location
+}
+
+type fileStorage struct {
+	Backend             string   `toml:"backend"`
+	MaxUploadSize       int64    `toml:"max_upload_size"`
+	AllowedContentTypes []string `toml:"allowed_content_types"`
+	LocalDir            string   `toml:"local_dir"`
+	S3Endpoint          string   `toml:"s3_endpoint"`
+	S3Region            string   `toml:"s3_region"`
+	S3Bucket            string   `toml:"s3_bucket"`
+	S3AccessKey         string   `toml:"s3_access_key"`
+	S3SecretKey         string   `toml:"s3_secret_key"`
+	S3PathStyle         bool     `toml:"s3_path_style"`
+}
+
 func parseConfig() configuration {
 	// default config
 	cfg := configuration{
@@ -41,6 +99,45 @@ func parseConfig() configuration {
 			Password: "password",
 			Name:     "database_name",
 		},
+		fileStorage{
+			Backend:       "local",
+			MaxUploadSize: 10,
+			AllowedContentTypes: []string{
+				"application/pdf",
+				"application/zip",
+				"image/gif",
+				"image/jpeg",
+				"image/png",
+				"image/webp",
+				"text/plain; charset=utf-8",
+			},
+			LocalDir:    "uploads",
+			S3Region:    "us-east-1",
+			S3PathStyle: true,
+		},
+		workload{
+			MaxTestsPerDay:  1,
+			MaxTestsPerWeek: 3,
+			Enforce:         false,
+		},
+		email{
+			Enabled:    false,
+			SMTPHost:   "localhost",
+			SMTPPort:   587,
+			From:       "Lavurso <noreply@localhost>",
+			DigestHour: 16,
+		},
+		push{
+			Enabled:   false,
+			Subject:   "mailto:noreply@localhost",
+			AllowHTTP: false,
+		},
+		messaging{
+			SearchLanguage: "simple",
+		},
+		school{
+			Timezone: "Local",
+		},
 	}
 
 	configData, err := os.ReadFile("config.toml")
@@ -113,4 +210,247 @@ func (cfg *configuration) checkEnvironment() {
 		log.Println("INFO using environment variable DATABASE_NAME")
 		cfg.Database.Name = val
 	}
+
+	val, ok = os.LookupEnv("STORAGE_BACKEND")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_BACKEND")
+		cfg.Storage.Backend = val
+	}
+
+	val, ok = os.LookupEnv("STORAGE_MAX_UPLOAD_SIZE")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_MAX_UPLOAD_SIZE")
+		size, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable STORAGE_MAX_UPLOAD_SIZE, skipping it")
+		} else {
+			cfg.Storage.MaxUploadSize = size
+		}
+	}
+
+	val, ok = os.LookupEnv("STORAGE_ALLOWED_CONTENT_TYPES")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_ALLOWED_CONTENT_TYPES")
+		list := strings.Split(val, ",")
+		for i, v := range list {
+			list[i] = strings.TrimSpace(v)
+		}
+		cfg.Storage.AllowedContentTypes = list
+	}
+
+	val, ok = os.LookupEnv("STORAGE_LOCAL_DIR")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_LOCAL_DIR")
+		cfg.Storage.LocalDir = val
+	}
+
+	val, ok = os.LookupEnv("STORAGE_S3_ENDPOINT")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_S3_ENDPOINT")
+		cfg.Storage.S3Endpoint = val
+	}
+
+	val, ok = os.LookupEnv("STORAGE_S3_REGION")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_S3_REGION")
+		cfg.Storage.S3Region = val
+	}
+
+	val, ok = os.LookupEnv("STORAGE_S3_BUCKET")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_S3_BUCKET")
+		cfg.Storage.S3Bucket = val
+	}
+
+	val, ok = os.LookupEnv("STORAGE_S3_ACCESS_KEY")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_S3_ACCESS_KEY")
+		cfg.Storage.S3AccessKey = val
+	}
+
+	val, ok = os.LookupEnv("STORAGE_S3_SECRET_KEY")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_S3_SECRET_KEY")
+		cfg.Storage.S3SecretKey = val
+	}
+
+	val, ok = os.LookupEnv("STORAGE_S3_PATH_STYLE")
+	if ok {
+		log.Println("INFO using environment variable STORAGE_S3_PATH_STYLE")
+		pathStyle, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable STORAGE_S3_PATH_STYLE, skipping it")
+		} else {
+			cfg.Storage.S3PathStyle = pathStyle
+		}
+	}
+
+	val, ok = os.LookupEnv("WORKLOAD_MAX_TESTS_PER_DAY")
+	if ok {
+		log.Println("INFO using environment variable WORKLOAD_MAX_TESTS_PER_DAY")
+		limit, err := strconv.Atoi(val)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable WORKLOAD_MAX_TESTS_PER_DAY, skipping it")
+		} else {
+			cfg.Workload.MaxTestsPerDay = limit
+		}
+	}
+
+	val, ok = os.LookupEnv("WORKLOAD_MAX_TESTS_PER_WEEK")
+	if ok {
+		log.Println("INFO using environment variable WORKLOAD_MAX_TESTS_PER_WEEK")
+		limit, err := strconv.Atoi(val)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable WORKLOAD_MAX_TESTS_PER_WEEK, skipping it")
+		} else {
+			cfg.Workload.MaxTestsPerWeek = limit
+		}
+	}
+
+	val, ok = os.LookupEnv("WORKLOAD_ENFORCE")
+	if ok {
+		log.Println("INFO using environment variable WORKLOAD_ENFORCE")
+		enforce, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable WORKLOAD_ENFORCE, skipping it")
+		} else {
+			cfg.Workload.Enforce = enforce
+		}
+	}
+
+	val, ok = os.LookupEnv("EMAIL_ENABLED")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_ENABLED")
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable EMAIL_ENABLED, skipping it")
+		} else {
+			cfg.Email.Enabled = enabled
+		}
+	}
+
+	val, ok = os.LookupEnv("EMAIL_SMTP_HOST")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_SMTP_HOST")
+		cfg.Email.SMTPHost = val
+	}
+
+	val, ok = os.LookupEnv("EMAIL_SMTP_PORT")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_SMTP_PORT")
+		port, err := strconv.Atoi(val)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable EMAIL_SMTP_PORT, skipping it")
+		} else {
+			cfg.Email.SMTPPort = port
+		}
+	}
+
+	val, ok = os.LookupEnv("EMAIL_SMTP_USERNAME")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_SMTP_USERNAME")
+		cfg.Email.SMTPUsername = val
+	}
+
+	val, ok = os.LookupEnv("EMAIL_SMTP_PASSWORD")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_SMTP_PASSWORD")
+		cfg.Email.SMTPPassword = val
+	}
+
+	val, ok = os.LookupEnv("EMAIL_FROM")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_FROM")
+		cfg.Email.From = val
+	}
+
+	val, ok = os.LookupEnv("EMAIL_DIGEST_HOUR")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_DIGEST_HOUR")
+		hour, err := strconv.Atoi(val)
+		if err != nil || hour < 0 || hour > 23 {
+			log.Println("ERROR failed reading environment variable EMAIL_DIGEST_HOUR, skipping it")
+		} else {
+			cfg.Email.DigestHour = hour
+		}
+	}
+
+	val, ok = os.LookupEnv("EMAIL_REPLY_DOMAIN")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_REPLY_DOMAIN")
+		cfg.Email.ReplyDomain = val
+	}
+
+	val, ok = os.LookupEnv("EMAIL_REPLY_SECRET")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_REPLY_SECRET")
+		cfg.Email.ReplySecret = val
+	}
+
+	val, ok = os.LookupEnv("EMAIL_INBOUND_TOKEN")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_INBOUND_TOKEN")
+		cfg.Email.InboundToken = val
+	}
+
+	val, ok = os.LookupEnv("EMAIL_INBOUND_AUTHSERV_ID")
+	if ok {
+		log.Println("INFO using environment variable EMAIL_INBOUND_AUTHSERV_ID")
+		cfg.Email.InboundAuthservID = val
+	}
+
+	val, ok = os.LookupEnv("PUSH_ENABLED")
+	if ok {
+		log.Println("INFO using environment variable PUSH_ENABLED")
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable PUSH_ENABLED, skipping it")
+		} else {
+			cfg.Push.Enabled = enabled
+		}
+	}
+
+	val, ok = os.LookupEnv("PUSH_VAPID_PRIVATE_KEY")
+	if ok {
+		log.Println("INFO using environment variable PUSH_VAPID_PRIVATE_KEY")
+		cfg.Push.VAPIDPrivateKey = val
+	}
+
+	val, ok = os.LookupEnv("PUSH_SUBJECT")
+	if ok {
+		log.Println("INFO using environment variable PUSH_SUBJECT")
+		cfg.Push.Subject = val
+	}
+
+	val, ok = os.LookupEnv("PUSH_ALLOW_HTTP")
+	if ok {
+		log.Println("INFO using environment variable PUSH_ALLOW_HTTP")
+		allow, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Println("ERROR failed reading environment variable PUSH_ALLOW_HTTP, skipping it")
+		} else {
+			cfg.Push.AllowHTTP = allow
+		}
+	}
+
+	val, ok = os.LookupEnv("MESSAGING_SEARCH_LANGUAGE")
+	if ok {
+		log.Println("INFO using environment variable MESSAGING_SEARCH_LANGUAGE")
+		cfg.Messaging.SearchLanguage = val
+	}
+
+	val, ok = os.LookupEnv("SCHOOL_TIMEZONE")
+	if ok {
+		log.Println("INFO using environment variable SCHOOL_TIMEZONE")
+		cfg.School.Timezone = val
+	}
+}
+
+// deadlines and digests follow the school's day, not UTC
+func (config *school) loadLocation() {
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		log.Fatalln("school:", err)
+	}
+	config.location = location
 }
@@ -10,8 +10,12 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
+func (config database) connectionString() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s", config.Host, config.Port, config.User, config.Password, config.Name)
+}
+
 func (config database) openConnection() *sql.DB {
-	db, err := sql.Open("pgx", fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s", config.Host, config.Port, config.User, config.Password, config.Name))
+	db, err := sql.Open("pgx", config.connectionString())
 	if err != nil {
 		log.Fatalln(err)
 	}
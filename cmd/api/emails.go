@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/mailer"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	mailerInterval = time.Minute
+	emailBatchSize = 50
+	// users whose digests are sent in one go
+	digestBatchSize = 50
+)
+
+// queues an email about each of the users for everyone that wants to receive it,
+// failures are only logged as the change itself has already been made
+func (app *application) queueEmails(eventType string, userIDs []int, withParents bool, dedupeKey, subject, body string) {
+	if app.mailer == nil || len(userIDs) == 0 {
+		return
+	}
+
+	err := app.insertEmails(eventType, userIDs, withParents, dedupeKey, nil, subject, body)
+	if err != nil {
+		app.errorLogger.Println("emails:", err)
+	}
+}
+
+// emails about a thread can be replied to
+func (app *application) queueThreadEmails(threadID int, userIDs []int, subject, body string) {
+	if app.mailer == nil || len(userIDs) == 0 {
+		return
+	}
+
+	err := app.insertEmails(data.EmailEventMessage, userIDs, false, "", &threadID, subject, body)
+	if err != nil {
+		app.errorLogger.Println("emails:", err)
+	}
+}
+
+func (app *application) insertEmails(eventType string, userIDs []int, withParents bool, dedupeKey string, threadID *int, subject, body string) error {
+	recipients, err := app.models.Emails.GetEmailRecipients(eventType, userIDs, withParents)
+	if err != nil {
+		return err
+	}
+
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	currentTime := time.Now().UTC()
+	digestTime := app.nextDigestTime(currentTime)
+
+	var emails []*data.Email
+	for _, rc := range recipients {
+		rc := rc
+
+		email := &data.Email{
+			UserID:    &rc.UserID,
+			ToAddress: &rc.Email,
+			EventType: &eventType,
+			Subject:   &subject,
+			Body:      &body,
+			Digest:    helpers.ToPtr(rc.Delivery == data.DeliveryDigest),
+			SendAfter: &currentTime,
+			CreatedAt: &currentTime,
+			Attempts:  helpers.ToPtr(0),
+			ThreadID:  threadID,
+		}
+
+		// parents get emails about their children
+		if rc.UserID != rc.ForUserID {
+			email.Subject = helpers.ToPtr(fmt.Sprintf("%s: %s", rc.ForName, subject))
+		}
+
+		if *email.Digest {
+			email.SendAfter = &digestTime
+		}
+
+		if dedupeKey != "" {
+			email.DedupeKey = helpers.ToPtr(fmt.Sprintf("%s:%d:%d", dedupeKey, rc.ForUserID, rc.UserID))
+		}
+
+		emails = append(emails, email)
+	}
+
+	return app.models.Emails.InsertEmails(emails)
+}
+
+// the next time digests are sent at, the digest hour is in the configured time zone
+func (app *application) nextDigestTime(after time.Time) time.Time {
+	y, m, d := after.In(app.config.School.location).Date()
+	t := time.Date(y, m, d, app.config.Email.DigestHour, 0, 0, 0, app.config.School.location)
+	if !t.After(after) {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t.UTC()
+}
+
+func (app *application) notifyNewMessage(threadID int, title string, sender *data.User, body string) {
+	if app.mailer == nil && app.push == nil {
+		return
+	}
+
+	memberIDs, err := app.models.Messaging.GetUserIDsInThread(threadID)
+	if err != nil {
+		app.errorLogger.Println("emails:", err)
+		return
+	}
+
+	mutedIDs, err := app.models.Messaging.GetUserIDsMutingThread(threadID)
+	if err != nil {
+		app.errorLogger.Println("emails:", err)
+		return
+	}
+
+	// the sender and the members that muted the thread aren't notified
+	var recipientIDs []int
+	for _, id := range memberIDs {
+		if id != sender.ID && !slices.Contains(mutedIDs, id) {
+			recipientIDs = append(recipientIDs, id)
+		}
+	}
+
+	subject := fmt.Sprintf("New message from %s: %s", *sender.Name, title)
+	app.queueThreadEmails(threadID, recipientIDs, subject, body)
+	app.sendPush(data.EmailEventMessage, recipientIDs, false, subject, body)
+}
+
+// notifies the students and their parents about newly added grades, notices and absences
+func (app *application) notifyNewMarks(journal *data.JournalExt, lesson *data.LessonExt, marks []*data.Mark) {
+	if app.mailer == nil && app.push == nil {
+		return
+	}
+
+	var grades []*data.Grade
+	for _, m := range marks {
+		var eventType, subject, body string
+
+		switch *m.Type {
+		case data.MarkLessonGrade, data.MarkCourseGrade, data.MarkSubjectGrade:
+			if m.GradeID == nil {
+				continue
+			}
+			if grades == nil {
+				var err error
+				grades, err = app.models.Grades.AllGrades()
+				if err != nil {
+					app.errorLogger.Println("emails:", err)
+					return
+				}
+			}
+
+			i := slices.IndexFunc(grades, func(g *data.Grade) bool { return g.ID == *m.GradeID })
+			if i < 0 {
+				continue
+			}
+
+			eventType = data.EmailEventMark
+			subject = fmt.Sprintf("New grade in %s: %s", *journal.Subject.Name, *grades[i].Identifier)
+		case data.MarkNoticeGood, data.MarkNoticeNeutral, data.MarkNoticeBad:
+			eventType = data.EmailEventMark
+			subject = fmt.Sprintf("New notice in %s", *journal.Subject.Name)
+		case data.MarkAbsent:
+			eventType = data.EmailEventAbsence
+			subject = fmt.Sprintf("Absent from %s", *journal.Subject.Name)
+			if lesson != nil {
+				subject = fmt.Sprintf("Absent from %s on %s", *journal.Subject.Name, lesson.Date.String())
+			}
+		default:
+			continue
+		}
+
+		if m.Comment != nil {
+			body = *m.Comment
+		}
+
+		app.notify(eventType, []int{*m.UserID}, true, subject, body)
+	}
+}
+
+// reminds students and their parents of assignments that are due tomorrow and not yet done,
+// queued once the digest hour has been reached so the reminders don't arrive in the middle of the night.
+// they are only emailed, as this runs every minute and only the outbox remembers what has been sent
+func (app *application) queueAssignmentReminders() error {
+	now := time.Now().In(app.config.School.location)
+	if now.Hour() < app.config.Email.DigestHour {
+		return nil
+	}
+
+	y, m, d := now.AddDate(0, 0, 1).Date()
+	tomorrow := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	assignments, err := app.models.Assignments.GetUndoneAssignmentsDueOn(tomorrow)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range assignments {
+		var studentIDs []int
+		for _, s := range a.Students {
+			studentIDs = append(studentIDs, s.ID)
+		}
+
+		var body string
+		if a.Description != nil {
+			body = *a.Description
+		}
+
+		app.queueEmails(data.EmailEventAssignmentDue, studentIDs, true, fmt.Sprintf("assignment_due:%d", a.ID),
+			fmt.Sprintf("%s in %s is due tomorrow", *a.Type, *a.Subject.Name), body)
+	}
+
+	return nil
+}
+
+// sends emails that are due immediately, failed ones are retried later.
+// they are claimed first so no transaction is kept open while talking to the SMTP server
+func (app *application) sendEmails() error {
+	emails, err := app.models.Emails.ClaimDueEmails(false, emailBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range emails {
+		var replyTo string
+		if e.ThreadID != nil && app.config.Email.repliesEnabled() {
+			replyTo = mailer.ReplyAddress(app.config.Email.ReplySecret, app.config.Email.ReplyDomain, *e.ThreadID, *e.UserID)
+		}
+
+		// each one is marked right away, so a crash halfway through doesn't send the rest twice
+		sendErr := app.mailer.Send(*e.ToAddress, replyTo, *e.Subject, *e.Body)
+		if sendErr != nil {
+			app.errorLogger.Println("emails:", sendErr)
+			err = app.models.Emails.SetEmailsFailed([]int{e.ID}, sendErr)
+		} else {
+			err = app.models.Emails.SetEmailsSent([]int{e.ID})
+		}
+		if err != nil {
+			app.errorLogger.Println("emails:", err)
+		}
+	}
+
+	return nil
+}
+
+// combines each user's digest emails into one
+func (app *application) sendDigests() error {
+	emails, err := app.models.Emails.ClaimDueEmails(true, digestBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// emails are ordered by user
+	for len(emails) > 0 {
+		n := 1
+		for n < len(emails) && *emails[n].UserID == *emails[0].UserID {
+			n++
+		}
+		userEmails := emails[:n]
+		emails = emails[n:]
+
+		var body strings.Builder
+		var ids []int
+		for _, e := range userEmails {
+			fmt.Fprintf(&body, "%s\n", *e.Subject)
+			if *e.Body != "" {
+				fmt.Fprintf(&body, "%s\n", *e.Body)
+			}
+			body.WriteString("\n")
+			ids = append(ids, e.ID)
+		}
+
+		last := userEmails[len(userEmails)-1]
+		sendErr := app.mailer.Send(*last.ToAddress, "", "Your daily digest", body.String())
+		if sendErr != nil {
+			app.errorLogger.Println("emails:", sendErr)
+			err = app.models.Emails.SetEmailsFailed(ids, sendErr)
+		} else {
+			err = app.models.Emails.SetEmailsSent(ids)
+		}
+		if err != nil {
+			app.errorLogger.Println("emails:", err)
+		}
+	}
+
+	return nil
+}
+
+func (app *application) processEmails() error {
+	err := app.queueAssignmentReminders()
+	if err != nil {
+		return err
+	}
+
+	err = app.sendEmails()
+	if err != nil {
+		return err
+	}
+
+	return app.sendDigests()
+}
+
+// sends emails on its own, so a slow SMTP server doesn't hold up the scheduler
+func (app *application) runMailer(ctx context.Context) {
+	ticker := time.NewTicker(mailerInterval)
+	defer ticker.Stop()
+
+	for {
+		err := app.processEmails()
+		if err != nil {
+			app.errorLogger.Println("emails:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (app *application) getNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	prefs, err := app.models.Emails.GetNotificationPreferences(sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	preferences := make(map[string]string)
+	for _, t := range data.EmailEventTypes {
+		preferences[t] = data.DeliveryImmediate
+	}
+	for _, p := range prefs {
+		preferences[*p.EventType] = *p.Delivery
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"preferences": preferences})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) setNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	var input map[string]string
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	var prefs []*data.NotificationPreference
+	for eventType, delivery := range input {
+		eventType, delivery := eventType, delivery
+
+		if !slices.Contains(data.EmailEventTypes, eventType) {
+			v.Add(eventType, "no such event type")
+			continue
+		}
+
+		switch delivery {
+		case data.DeliveryImmediate, data.DeliveryDigest, data.DeliveryOff:
+		default:
+			v.Add(eventType, "must be one of immediate, digest or off")
+			continue
+		}
+
+		prefs = append(prefs, &data.NotificationPreference{
+			UserID:    &sessionUser.ID,
+			EventType: &eventType,
+			Delivery:  &delivery,
+		})
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	if len(prefs) > 0 {
+		err = app.models.Emails.SetNotificationPreferences(prefs)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -9,14 +9,14 @@ func (app *application) writeErrorResponse(w http.ResponseWriter, r *http.Reques
 	env := envelope{"error": data}
 	err := app.outputJSON(w, status, env)
 	if err != nil {
-		app.errorLogger.Println(r.Method, r.URL.String(), err)
+		app.errorLogger.Println(r.Method, redactedURI(r.URL), err)
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte("Internal server error"))
 	}
 }
 
 func (app *application) writeInternalServerError(w http.ResponseWriter, r *http.Request, err error) {
-	app.errorLogger.Println(r.Method, r.URL.String(), err)
+	app.errorLogger.Println(r.Method, redactedURI(r.URL), err)
 	debug.PrintStack()
 	app.writeErrorResponse(w, r, http.StatusInternalServerError, "internal server error")
 }
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	eventsHeartbeatInterval = 30 * time.Second
+	eventsReconnectDelay    = 5 * time.Second
+	eventsClientBuffer      = 16
+)
+
+type streamEvent struct {
+	name string
+	data envelope
+}
+
+type eventClient struct {
+	userID int
+	send   chan *streamEvent
+}
+
+// keeps track of the event streams open on this instance
+type eventHub struct {
+	mu      sync.Mutex
+	clients map[int]map[*eventClient]struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		clients: make(map[int]map[*eventClient]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (h *eventHub) subscribe(userID int) *eventClient {
+	c := &eventClient{userID: userID, send: make(chan *streamEvent, eventsClientBuffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*eventClient]struct{})
+	}
+	h.clients[userID][c] = struct{}{}
+
+	return c
+}
+
+func (h *eventHub) unsubscribe(c *eventClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
+
+// which of the given users have a stream open
+func (h *eventHub) connected(userIDs []int) []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var ids []int
+	for _, id := range userIDs {
+		if h.clients[id] != nil {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// every user with a stream open
+func (h *eventHub) userIDs() []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var ids []int
+	for id := range h.clients {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func (h *eventHub) empty() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.clients) == 0
+}
+
+// a client that can't keep up misses the event instead of holding up everyone else
+func (h *eventHub) send(userID int, e *streamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients[userID] {
+		select {
+		case c.send <- e:
+		default:
+		}
+	}
+}
+
+// ends all open streams, so the server can shut down
+func (h *eventHub) close() {
+	h.once.Do(func() {
+		close(h.done)
+	})
+}
+
+// publishes an event to all instances, failures are only logged as the change itself has already been made
+func (app *application) publishEvent(e *data.Event) {
+	err := app.models.Events.PublishEvent(e)
+	if err != nil {
+		app.errorLogger.Println("events:", err)
+	}
+}
+
+// listens for published events until the context is cancelled, reconnecting if the connection is lost
+func (app *application) listenForEvents(ctx context.Context) {
+	for {
+		err := app.receiveEvents(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		app.errorLogger.Println("events:", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventsReconnectDelay):
+		}
+	}
+}
+
+func (app *application) receiveEvents(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, app.config.Database.connectionString())
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+data.EventsChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		if app.events.empty() {
+			continue
+		}
+
+		var e data.Event
+		err = json.Unmarshal([]byte(notification.Payload), &e)
+		if err != nil {
+			app.errorLogger.Println("events:", err)
+			continue
+		}
+
+		err = app.dispatchEvent(&e)
+		if err != nil {
+			app.errorLogger.Println("events:", err)
+		}
+	}
+}
+
+// sends the event to its recipients that are connected to this instance
+func (app *application) dispatchEvent(e *data.Event) error {
+	recipients := app.events.connected(e.UserIDs)
+
+	// only the users connected here are checked, not every member of the thread
+	if e.ThreadMembers && e.ThreadID != nil {
+		members, err := app.models.Messaging.FilterUserIDsInThread(*e.ThreadID, app.events.userIDs())
+		if err != nil {
+			return err
+		}
+		for _, id := range members {
+			if !slices.Contains(recipients, id) {
+				recipients = append(recipients, id)
+			}
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	if e.Type != data.EventUnread {
+		payload := envelope{"thread_id": e.ThreadID}
+
+		switch e.Type {
+		case data.EventMessage, data.EventMessageUpdated:
+			message, err := app.models.Messaging.GetMessageByID(*e.MessageID)
+			if err != nil {
+				return err
+			}
+			// every member gets the same payload, administrators see the content when loading the thread
+			hideMessageContent(message)
+			payload["message"] = message
+		case data.EventMessageDeleted:
+			payload["message_id"] = e.MessageID
+		}
+
+		for _, id := range recipients {
+			app.events.send(id, &streamEvent{name: e.Type, data: payload})
+		}
+	}
+
+	// these change which threads are unread
+	switch e.Type {
+	case data.EventMessage, data.EventMembersAdded, data.EventMembersRemoved, data.EventThreadDeleted, data.EventUnread:
+		for _, id := range recipients {
+			unread, err := app.unreadEvent(id)
+			if err != nil {
+				return err
+			}
+			app.events.send(id, unread)
+		}
+	}
+
+	return nil
+}
+
+func (app *application) unreadEvent(userID int) (*streamEvent, error) {
+	count, err := app.models.Messaging.CountUnreadThreadsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamEvent{name: data.EventUnread, data: envelope{"unread": count > 0, "unread_threads": count}}, nil
+}
+
+func writeStreamEvent(w http.ResponseWriter, e *streamEvent) error {
+	js, err := json.Marshal(e.data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, js)
+	return err
+}
+
+// a ticket for opening the event stream with ?ticket=, it works once and only for a short while
+func (app *application) createEventsTicket(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	ticket := new(types.Token)
+	err := ticket.NewToken()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.models.Sessions.InsertEventsTicket(*sessionUser.SessionID, ticket)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"ticket": ticket.Plaintext, "expires_in": int(data.EventsTicketLifetime.Seconds())})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// ErrInvalidToken once the session has expired or been logged out, otherwise the session is extended
+func (app *application) checkStreamSession(sessionID int) error {
+	_, err := app.models.Users.GetUserBySessionID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return app.models.Sessions.ExtendSession(sessionID)
+}
+
+func (app *application) streamEvents(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		app.writeInternalServerError(w, r, errors.New("streaming not supported"))
+		return
+	}
+
+	unread, err := app.unreadEvent(sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	client := app.events.subscribe(sessionUser.ID)
+	defer app.events.unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// current state first, so the client doesn't have to fetch it separately
+	err = writeStreamEvent(w, unread)
+	if err != nil {
+		return
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-app.events.done:
+			return
+		case e := <-client.send:
+			err = writeStreamEvent(w, e)
+		case <-heartbeat.C:
+			// the stream ends with the session, and keeps an otherwise idle session alive
+			err = app.checkStreamSession(*sessionUser.SessionID)
+			if err != nil {
+				if !errors.Is(err, data.ErrInvalidToken) {
+					app.errorLogger.Println("events:", err)
+				}
+				return
+			}
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/ical"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	feedPastDays   = 30
+	feedFutureDays = 90
+)
+
+func feedURL(token string) string {
+	return "/calendar-feeds/" + token
+}
+
+func (app *application) getCalendarFeedsForUser(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	feeds, err := app.models.Feeds.GetFeedsForUser(sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"feeds": feeds})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) createCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	var input struct {
+		StudentID *int `json:"student_id"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch *sessionUser.Role {
+	case data.RoleStudent:
+		if input.StudentID != nil && *input.StudentID != sessionUser.ID {
+			app.notAllowed(w, r)
+			return
+		}
+		input.StudentID = nil
+	case data.RoleParent:
+		// no student means a feed for all children
+		if input.StudentID != nil {
+			ok, err := app.models.Users.IsUserParentOfStudent(*input.StudentID, sessionUser.ID)
+			if err != nil {
+				app.writeInternalServerError(w, r, err)
+				return
+			}
+			if !ok {
+				app.notAllowed(w, r)
+				return
+			}
+		}
+	default:
+		app.notAllowed(w, r)
+		return
+	}
+
+	token := new(types.Token)
+	err = token.NewToken()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	feed := &data.CalendarFeed{
+		UserID:    &sessionUser.ID,
+		StudentID: input.StudentID,
+		Token:     token,
+		CreatedAt: helpers.ToPtr(time.Now().UTC()),
+	}
+
+	err = app.models.Feeds.InsertFeed(feed)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"feed": feed, "token": token.Plaintext, "url": feedURL(token.Plaintext)})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getOwnCalendarFeed(w http.ResponseWriter, r *http.Request) (*data.CalendarFeed, bool) {
+	sessionUser := app.getUserFromContext(r)
+
+	feedID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if feedID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchCalendarFeed.Error())
+		return nil, false
+	}
+
+	feed, err := app.models.Feeds.GetFeedByID(feedID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchCalendarFeed):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	if *feed.UserID != sessionUser.ID {
+		app.notAllowed(w, r)
+		return nil, false
+	}
+
+	return feed, true
+}
+
+func (app *application) rotateCalendarFeedToken(w http.ResponseWriter, r *http.Request) {
+	feed, ok := app.getOwnCalendarFeed(w, r)
+	if !ok {
+		return
+	}
+
+	token := new(types.Token)
+	err := token.NewToken()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.models.Feeds.SetFeedToken(feed.ID, token)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	feed.LastAccessed = nil
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"feed": feed, "token": token.Plaintext, "url": feedURL(token.Plaintext)})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	feed, ok := app.getOwnCalendarFeed(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.models.Feeds.DeleteFeed(feed.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// students whose assignments and lessons are included in the feed
+func (app *application) getFeedStudents(feed *data.CalendarFeed) ([]*data.UserExt, error) {
+	owner, err := app.models.Users.GetUserByID(*feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch *owner.Role {
+	case data.RoleStudent:
+		return []*data.UserExt{owner}, nil
+	case data.RoleParent:
+		children, err := app.models.Users.GetChildrenForParent(owner.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if feed.StudentID == nil {
+			return children, nil
+		}
+
+		// the parent may have been removed from the student since the feed was created
+		for _, c := range children {
+			if c.ID == *feed.StudentID {
+				return []*data.UserExt{c}, nil
+			}
+		}
+	}
+
+	return []*data.UserExt{}, nil
+}
+
+func (app *application) getCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := app.models.Feeds.GetFeedByToken(chi.URLParam(r, "token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidToken):
+			app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchCalendarFeed.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	students, err := app.getFeedStudents(feed)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := &types.Date{Time: helpers.ToPtr(today.AddDate(0, 0, -feedPastDays))}
+	until := &types.Date{Time: helpers.ToPtr(today.AddDate(0, 0, feedFutureDays))}
+
+	cal := &ical.Calendar{
+		ProdID: "-//Lavurso//Calendar feed//EN",
+		Name:   "Lavurso",
+	}
+
+	classIDs := []int{}
+
+	for _, s := range students {
+		// name events after the child when a feed covers several of them
+		prefix := ""
+		if len(students) > 1 {
+			prefix = *s.Name + ": "
+		}
+
+		if s.ClassID != nil {
+			classIDs = append(classIDs, *s.ClassID)
+		}
+
+		assignments, err := app.models.Assignments.GetAssignmentsForStudent(s.ID, from, until)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		for _, a := range assignments {
+			event := &ical.Event{
+				UID:     fmt.Sprintf("assignment-%d-%d@lavurso", a.ID, s.ID),
+				Summary: fmt.Sprintf("%s%s (%s)", prefix, *a.Subject.Name, *a.Type),
+				Start:   *a.Deadline.Time,
+				End:     a.Deadline.AddDate(0, 0, 1),
+				AllDay:  true,
+			}
+			if a.Description != nil {
+				event.Description = *a.Description
+			}
+			cal.Events = append(cal.Events, event)
+		}
+
+		lessons, err := app.models.Lessons.GetLatestLessonsForStudent(s.ID, from, until)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		for _, l := range lessons {
+			event := &ical.Event{
+				UID:     fmt.Sprintf("lesson-%d-%d@lavurso", l.ID, s.ID),
+				Summary: prefix + *l.Subject.Name,
+				Start:   *l.Date.Time,
+				End:     l.Date.AddDate(0, 0, 1),
+				AllDay:  true,
+			}
+			if l.Description != nil {
+				event.Description = *l.Description
+			}
+			// the lesson's own times may have been changed from its period's
+			start, end := l.StartTime, l.EndTime
+			if (start == nil || start.Time == nil || end == nil || end.Time == nil) && l.Period != nil {
+				start, end = l.Period.StartTime, l.Period.EndTime
+			}
+			if start != nil && start.Time != nil && end != nil && end.Time != nil {
+				event.Start = combineDateTime(l.Date, start)
+				event.End = combineDateTime(l.Date, end)
+				event.AllDay = false
+			}
+			cal.Events = append(cal.Events, event)
+		}
+	}
+
+	year, err := app.models.Years.GetCurrentYear()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if year != nil {
+		entries, err := app.models.Calendar.GetEntriesForYear(year.ID, classIDs)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		for _, e := range entries {
+			event := &ical.Event{
+				UID:     fmt.Sprintf("calendar-%d@lavurso", e.ID),
+				Summary: *e.Title,
+				Start:   *e.StartDate.Time,
+				End:     e.EndDate.AddDate(0, 0, 1),
+				AllDay:  true,
+			}
+			if e.Description != nil {
+				event.Description = *e.Description
+			}
+			cal.Events = append(cal.Events, event)
+		}
+	}
+
+	err = app.models.Feeds.SetFeedAccessed(feed.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="lavurso.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=900")
+
+	_, err = cal.WriteTo(w)
+	if err != nil {
+		app.errorLogger.Println(r.Method, "/calendar-feeds", err)
+	}
+}
+
+func combineDateTime(d *types.Date, t *types.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
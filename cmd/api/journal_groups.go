@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+// fetches the journal group from the URL and checks that the user teaches its journal
+func (app *application) getJournalGroupForTeacher(w http.ResponseWriter, r *http.Request) (*data.JournalGroupExt, bool) {
+	sessionUser := app.getUserFromContext(r)
+
+	groupID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if groupID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchJournalGroup.Error())
+		return nil, false
+	}
+
+	group, err := app.models.JournalGroups.GetJournalGroupByID(groupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournalGroup):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(*group.JournalID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return nil, false
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return nil, false
+	}
+
+	return group, true
+}
+
+func (app *application) validateJournalGroupStudents(v *validator.Validator, journalID int, studentIDs []int) error {
+	if len(studentIDs) == 0 {
+		return nil
+	}
+
+	allStudentIDs, err := app.models.Journals.GetStudentIDsForJournal(journalID)
+	if err != nil {
+		return err
+	}
+
+	badStudentIDs := helpers.VerifyExistsInSlice(studentIDs, allStudentIDs)
+	v.Check(badStudentIDs == nil, "student_ids", fmt.Sprintf("%s: %v", data.ErrUserNotInJournal.Error(), badStudentIDs))
+
+	return nil
+}
+
+func (app *application) getJournalGroupsForJournal(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	journalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if journalID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchJournal.Error())
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(journalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	groups, err := app.models.JournalGroups.GetJournalGroupsForJournal(journal.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"groups": groups})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) createJournalGroup(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	journalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if journalID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchJournal.Error())
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(journalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	var input struct {
+		Name       string `json:"name"`
+		StudentIDs []int  `json:"student_ids"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+
+	v := validator.NewValidator()
+
+	v.Check(input.Name != "", "name", "must be provided")
+
+	err = app.validateJournalGroupStudents(v, journal.ID, input.StudentIDs)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	group := &data.JournalGroup{
+		JournalID: &journal.ID,
+		Name:      &input.Name,
+	}
+
+	err = app.models.JournalGroups.InsertJournalGroup(group)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrJournalGroupNameExists):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.JournalGroups.SetJournalGroupStudents(group.ID, input.StudentIDs)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"group": group})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateJournalGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := app.getJournalGroupForTeacher(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name       *string `json:"name"`
+		StudentIDs *[]int  `json:"student_ids"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.Name != nil {
+		group.Name = helpers.ToPtr(strings.TrimSpace(*input.Name))
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*group.Name != "", "name", "must be provided")
+
+	if input.StudentIDs != nil {
+		err = app.validateJournalGroupStudents(v, *group.JournalID, *input.StudentIDs)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.JournalGroups.UpdateJournalGroup(&group.JournalGroup)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrJournalGroupNameExists):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if input.StudentIDs != nil {
+		err = app.models.JournalGroups.SetJournalGroupStudents(group.ID, *input.StudentIDs)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteJournalGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := app.getJournalGroupForTeacher(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.models.JournalGroups.DeleteJournalGroup(group.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrJournalGroupInUse):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -13,6 +13,22 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+func validateLessonTimes(v *validator.Validator, l *data.Lesson) {
+	if l.StartTime == nil && l.EndTime == nil {
+		return
+	}
+
+	hasStart := l.StartTime != nil && l.StartTime.Time != nil
+	hasEnd := l.EndTime != nil && l.EndTime.Time != nil
+
+	v.Check(hasStart, "start_time", "must be provided with end time")
+	v.Check(hasEnd, "end_time", "must be provided with start time")
+
+	if hasStart && hasEnd {
+		v.Check(l.StartTime.Before(*l.EndTime.Time), "end_time", "must be after start time")
+	}
+}
+
 func (app *application) createLesson(w http.ResponseWriter, r *http.Request) {
 	sessionUser := app.getUserFromContext(r)
 
@@ -21,6 +37,8 @@ func (app *application) createLesson(w http.ResponseWriter, r *http.Request) {
 		Description string     `json:"description"`
 		Date        types.Date `json:"date"`
 		Course      int        `json:"course"`
+		StartTime   types.Time `json:"start_time"`
+		EndTime     types.Time `json:"end_time"`
 	}
 
 	err := app.inputJSON(w, r, &input)
@@ -42,6 +60,11 @@ func (app *application) createLesson(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	if input.StartTime.Time != nil || input.EndTime.Time != nil {
+		lesson.StartTime = &input.StartTime
+		lesson.EndTime = &input.EndTime
+	}
+
 	if lesson.Date.Time.IsZero() {
 		app.writeErrorResponse(w, r, http.StatusBadRequest, types.ErrInvalidDateFormat.Error())
 		return
@@ -50,6 +73,7 @@ func (app *application) createLesson(w http.ResponseWriter, r *http.Request) {
 	v := validator.NewValidator()
 
 	v.Check(*lesson.Course > 0, "course", "must be provided and valid")
+	validateLessonTimes(v, &lesson.Lesson)
 
 	if !v.Valid() {
 		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
@@ -72,6 +96,16 @@ func (app *application) createLesson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	dayOff, err := app.models.Calendar.IsDayOff(*journal.YearID, lesson.Date)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	if dayOff {
+		app.writeErrorResponse(w, r, http.StatusConflict, data.ErrDateIsDayOff.Error())
+		return
+	}
+
 	err = app.models.Lessons.InsertLesson(lesson)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -122,7 +156,12 @@ func (app *application) getLesson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+	ok, err := app.canTeachLesson(sessionUser, journal, lesson.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	if !ok {
 		app.notAllowed(w, r)
 		return
 	}
@@ -165,14 +204,24 @@ func (app *application) updateLesson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
-		app.notAllowed(w, r)
-		return
+	isTeacher := journal.IsUserTeacherOfJournal(sessionUser.ID) || *sessionUser.Role == data.RoleAdministrator
+	if !isTeacher {
+		ok, err := app.models.Substitutions.IsUserSubstituteForLesson(sessionUser.ID, lesson.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
 	}
 
 	var input struct {
 		Description *string     `json:"description"`
 		Date        *types.Date `json:"date"`
+		StartTime   *types.Time `json:"start_time"`
+		EndTime     *types.Time `json:"end_time"`
 	}
 
 	err = app.inputJSON(w, r, &input)
@@ -185,7 +234,54 @@ func (app *application) updateLesson(w http.ResponseWriter, r *http.Request) {
 		lesson.Description = input.Description
 	}
 	if input.Date != nil {
+		// substitutes can only teach the lesson on the day it was planned
+		if !isTeacher {
+			app.notAllowed(w, r)
+			return
+		}
+
 		lesson.Date = input.Date
+
+		if lesson.Date.Time != nil {
+			dayOff, err := app.models.Calendar.IsDayOff(*journal.YearID, lesson.Date)
+			if err != nil {
+				app.writeInternalServerError(w, r, err)
+				return
+			}
+			if dayOff {
+				app.writeErrorResponse(w, r, http.StatusConflict, data.ErrDateIsDayOff.Error())
+				return
+			}
+		}
+	}
+
+	if input.StartTime != nil || input.EndTime != nil {
+		if !isTeacher {
+			app.notAllowed(w, r)
+			return
+		}
+
+		// an empty time clears both
+		if input.StartTime != nil && input.StartTime.Time == nil || input.EndTime != nil && input.EndTime.Time == nil {
+			lesson.StartTime = nil
+			lesson.EndTime = nil
+		} else {
+			if input.StartTime != nil {
+				lesson.StartTime = input.StartTime
+			}
+			if input.EndTime != nil {
+				lesson.EndTime = input.EndTime
+			}
+		}
+
+		v := validator.NewValidator()
+
+		validateLessonTimes(v, &lesson.Lesson)
+
+		if !v.Valid() {
+			app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+			return
+		}
 	}
 
 	lesson.UpdatedAt = helpers.ToPtr(time.Now().UTC())
@@ -281,15 +377,31 @@ func (app *application) getLessonsForJournal(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
-		app.notAllowed(w, r)
-		return
-	}
+	var lessons []*data.LessonExt
 
-	lessons, err := app.models.Lessons.GetLessonsByJournalID(journal.ID, course)
-	if err != nil {
-		app.writeInternalServerError(w, r, err)
-		return
+	if journal.IsUserTeacherOfJournal(sessionUser.ID) || *sessionUser.Role == data.RoleAdministrator {
+		lessons, err = app.models.Lessons.GetLessonsByJournalID(journal.ID, course)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	} else {
+		ok, err := app.models.Substitutions.IsUserSubstituteInJournal(sessionUser.ID, journal.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+
+		// substitutes only see the lessons they cover
+		lessons, err = app.models.Substitutions.GetSubstituteLessonsByJournalID(sessionUser.ID, journal.ID, course)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
 	}
 
 	err = app.outputJSON(w, http.StatusOK, envelope{"lessons": lessons})
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"log"
+
+	"github.com/annusingmar/lavurso-backend/internal/mailer"
+)
+
+func (config email) openMailer() *mailer.SMTP {
+	if !config.Enabled {
+		return nil
+	}
+
+	m, err := mailer.NewSMTP(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.From)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	return m
+}
+
+func (config email) repliesEnabled() bool {
+	return config.ReplyDomain != "" && config.ReplySecret != ""
+}
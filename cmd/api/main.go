@@ -11,6 +11,9 @@ import (
 	"time"
 
 	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/mailer"
+	"github.com/annusingmar/lavurso-backend/internal/storage"
+	"github.com/annusingmar/lavurso-backend/internal/webpush"
 )
 
 type application struct {
@@ -18,6 +21,10 @@ type application struct {
 	infoLogger  *log.Logger
 	errorLogger *log.Logger
 	models      data.Models
+	storage     storage.Storage
+	events      *eventHub
+	mailer      *mailer.SMTP
+	push        *webpush.Client
 }
 
 func main() {
@@ -26,14 +33,24 @@ func main() {
 	infoLogger := log.New(os.Stdout, "INFO ", log.Ltime|log.Ldate)
 	errorLogger := log.New(os.Stderr, "ERROR ", log.Ltime|log.Ldate)
 
+	config.School.loadLocation()
 	db := config.Database.openConnection()
 	models := data.NewModel(db)
+	models.Submissions.Location = config.School.location
+	config.Messaging.checkSearchLanguage(models)
+	storage := config.Storage.openStorage()
+	mailer := config.Email.openMailer()
+	push := config.Push.openPushClient()
 
 	app := &application{
 		config:      config,
 		infoLogger:  infoLogger,
 		errorLogger: errorLogger,
 		models:      models,
+		storage:     storage,
+		events:      newEventHub(),
+		mailer:      mailer,
+		push:        push,
 	}
 
 	server := &http.Server{
@@ -41,6 +58,14 @@ func main() {
 		ErrorLog: errorLogger,
 		Handler:  app.routes(),
 	}
+	server.RegisterOnShutdown(app.events.close)
+
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	go app.runScheduler(backgroundCtx)
+	if app.mailer != nil {
+		go app.runMailer(backgroundCtx)
+	}
+	go app.listenForEvents(backgroundCtx)
 
 	catchSignal := make(chan os.Signal, 1)
 	signal.Notify(catchSignal, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT)
@@ -59,6 +84,7 @@ func main() {
 	defer cancel()
 
 	app.infoLogger.Println("shutting down...")
+	stopBackground()
 	err := server.Shutdown(ctx)
 	if err != nil {
 		app.errorLogger.Fatalln(err)
@@ -196,7 +196,12 @@ func (app *application) getMarksForLesson(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+	ok, err := app.canTeachLesson(sessionUser, journal, lesson.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	if !ok {
 		app.notAllowed(w, r)
 		return
 	}
@@ -321,7 +326,12 @@ func (app *application) setMarksForLesson(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+	ok, err := app.canTeachLesson(sessionUser, journal, lesson.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	if !ok {
 		app.notAllowed(w, r)
 		return
 	}
@@ -416,6 +426,7 @@ func (app *application) setMarksForLesson(w http.ResponseWriter, r *http.Request
 	var deletedMarkIDs []int
 
 	var deletedMarksByLessonStudentType []data.MarkByLessonStudentType
+	var markedAbsent bool
 
 student:
 	for _, s := range input {
@@ -430,6 +441,7 @@ student:
 		if s.Absent != nil {
 			if *s.Absent {
 				insertMarks = append(insertMarks, newMark(0, 0, s.StudentID, data.MarkAbsent, nil, nil))
+				markedAbsent = true
 			} else {
 				deletedMarksByLessonStudentType = append(deletedMarksByLessonStudentType, data.MarkByLessonStudentType{LessonID: lesson.ID, StudentID: s.StudentID, Type: data.MarkAbsent})
 			}
@@ -521,12 +533,22 @@ student:
 		}
 	}
 
+	if markedAbsent {
+		err := app.models.Absences.ExcuseLessonAbsencesFromNotices(tx, lesson.ID, sessionUser.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
 	}
 
+	app.notifyNewMarks(journal, lesson, insertMarks)
+
 	err = app.outputJSON(w, http.StatusCreated, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -703,6 +725,8 @@ student:
 		return
 	}
 
+	app.notifyNewMarks(journal, nil, insertMarks)
+
 	err = app.outputJSON(w, http.StatusCreated, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -872,6 +896,8 @@ student:
 		return
 	}
 
+	app.notifyNewMarks(journal, nil, insertMarks)
+
 	err = app.outputJSON(w, http.StatusCreated, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
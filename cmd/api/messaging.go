@@ -14,6 +14,26 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// reads the page size from the 'limit' query parameter
+func readPageLimit(r *http.Request) (int, error) {
+	param := r.URL.Query().Get("limit")
+	if param == "" {
+		return defaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(param)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+	}
+
+	return limit, nil
+}
+
 func (app *application) verifyUserAndGroupIDs(userIDs, groupIDs []int, userID int, userRole string) ([]int, error) {
 	if len(userIDs) > 0 {
 		allUserIDs, err := app.models.Users.GetAllUserIDs()
@@ -50,68 +70,127 @@ func (app *application) verifyUserAndGroupIDs(userIDs, groupIDs []int, userID in
 	return nil, nil
 }
 
-func (app *application) createThread(w http.ResponseWriter, r *http.Request) {
-	sessionUser := app.getUserFromContext(r)
+type threadRuleInput struct {
+	Type      string  `json:"type"`
+	Role      *string `json:"role"`
+	ClassID   *int    `json:"class_id"`
+	JournalID *int    `json:"journal_id"`
+}
 
-	var input struct {
-		Title    string `json:"title"`
-		Body     string `json:"body"`
-		UserIDs  []int  `json:"user_ids"`
-		GroupIDs []int  `json:"group_ids"`
-	}
+// checks the rules and turns them into the stored form, keeping only the field the type uses.
+// rules are for teachers and administrators, and only administrators can address a whole role
+func (app *application) validateThreadRules(v *validator.Validator, input []*threadRuleInput, user *data.UserExt) ([]*data.ThreadRule, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	if *user.Role != data.RoleAdministrator && *user.Role != data.RoleTeacher {
+		v.Add("rules", "not allowed")
+		return nil, nil
+	}
+
+	var allClassIDs []int
+	var err error
+
+	var rules []*data.ThreadRule
+
+	for i, in := range input {
+		rule := &data.ThreadRule{Type: helpers.ToPtr(in.Type)}
+		field := fmt.Sprintf("rules[%d]", i)
+
+		switch in.Type {
+		case data.ThreadRuleRole:
+			if *user.Role != data.RoleAdministrator {
+				v.Add(field, "not allowed")
+				continue
+			}
+			v.Check(in.Role != nil && slices.Contains([]string{data.RoleAdministrator, data.RoleTeacher, data.RoleParent, data.RoleStudent}, *in.Role), field, "invalid role")
+			rule.Role = in.Role
+		case data.ThreadRuleClassParents, data.ThreadRuleClassTeachers:
+			if allClassIDs == nil {
+				allClassIDs, err = app.models.Classes.GetAllClassIDs()
+				if err != nil {
+					return nil, err
+				}
+			}
+			v.Check(in.ClassID != nil && slices.Contains(allClassIDs, *in.ClassID), field, data.ErrNoSuchClass.Error())
+			rule.ClassID = in.ClassID
+		case data.ThreadRuleJournalStudents:
+			if in.JournalID == nil {
+				v.Add(field, data.ErrNoSuchJournal.Error())
+				continue
+			}
+			journal, err := app.models.Journals.GetJournalByID(*in.JournalID)
+			if err != nil {
+				switch {
+				case errors.Is(err, data.ErrNoSuchJournal):
+					v.Add(field, err.Error())
+					continue
+				default:
+					return nil, err
+				}
+			}
+			v.Check(journal.IsUserTeacherOfJournal(user.ID) || *user.Role == data.RoleAdministrator, field, "not allowed")
+			rule.JournalID = in.JournalID
+		default:
+			v.Add(field, "invalid type")
+		}
 
-	err := app.inputJSON(w, r, &input)
-	if err != nil {
-		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
-		return
+		rules = append(rules, rule)
 	}
 
-	v := validator.NewValidator()
-
-	v.Check(input.Title != "", "title", "must be present")
-	v.Check(input.Body != "", "body", "must be present")
-
-	if !v.Valid() {
-		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
-		return
-	}
+	return rules, nil
+}
 
-	currentTime := time.Now().UTC()
+type threadRecipients struct {
+	UserIDs  []int              `json:"user_ids"`
+	GroupIDs []int              `json:"group_ids"`
+	Rules    []*threadRuleInput `json:"rules"`
+}
 
-	thread := &data.Thread{
-		UserID:    &sessionUser.ID,
-		Title:     &input.Title,
-		Locked:    helpers.ToPtr(false),
-		CreatedAt: &currentTime,
-		UpdatedAt: &currentTime,
+// creates the thread with its first message and lets the members know, for createThread and the scheduler.
+// The rules have to be validated already
+func (app *application) startThread(sender *data.UserExt, title, body string, recipients *threadRecipients, rules []*data.ThreadRule) (*data.Thread, error) {
+	err := app.checkMessagingMute(sender.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	if !slices.Contains(input.UserIDs, sessionUser.ID) {
-		input.UserIDs = append(input.UserIDs, sessionUser.ID)
+	userIDs := recipients.UserIDs
+	if !slices.Contains(userIDs, sender.ID) {
+		userIDs = append(userIDs, sender.ID)
 	}
 
-	badIDs, err := app.verifyUserAndGroupIDs(input.UserIDs, input.GroupIDs, sessionUser.ID, *sessionUser.Role)
+	badIDs, err := app.verifyUserAndGroupIDs(userIDs, recipients.GroupIDs, sender.ID, *sender.Role)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrNoSuchUsers) || errors.Is(err, data.ErrNoSuchGroups):
-			app.writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("%s: %v", err.Error(), badIDs))
-			return
+			return nil, fmt.Errorf("%w: %v", err, badIDs)
 		default:
-			app.writeInternalServerError(w, r, err)
-			return
+			return nil, err
 		}
 	}
 
+	currentTime := time.Now().UTC()
+
+	thread := &data.Thread{
+		UserID:        &sender.ID,
+		Title:         &title,
+		Locked:        helpers.ToPtr(false),
+		LockedByAdmin: helpers.ToPtr(false),
+		CreatedAt:     &currentTime,
+		UpdatedAt:     &currentTime,
+	}
+
 	err = app.models.Messaging.InsertThread(thread)
 	if err != nil {
-		app.writeInternalServerError(w, r, err)
-		return
+		return nil, err
 	}
 
 	threadMessage := &data.Message{
 		ThreadID:  &thread.ID,
-		UserID:    &sessionUser.ID,
-		Body:      &input.Body,
+		UserID:    &sender.ID,
+		Body:      &body,
 		Type:      helpers.ToPtr(data.MsgTypeThreadStart),
 		CreatedAt: &currentTime,
 		UpdatedAt: &currentTime,
@@ -119,24 +198,89 @@ func (app *application) createThread(w http.ResponseWriter, r *http.Request) {
 
 	err = app.models.Messaging.InsertMessage(threadMessage)
 	if err != nil {
-		app.writeInternalServerError(w, r, err)
-		return
+		return nil, err
 	}
 
-	if len(input.UserIDs) > 0 {
-		err = app.models.Messaging.AddUsersToThread(thread.ID, input.UserIDs)
+	err = app.models.Messaging.AddUsersToThread(thread.ID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recipients.GroupIDs) > 0 {
+		err = app.models.Messaging.AddGroupsToThread(thread.ID, recipients.GroupIDs)
 		if err != nil {
-			app.writeInternalServerError(w, r, err)
-			return
+			return nil, err
 		}
 	}
 
-	if len(input.GroupIDs) > 0 {
-		err = app.models.Messaging.AddGroupsToThread(thread.ID, input.GroupIDs)
+	if len(rules) > 0 {
+		err = app.models.Messaging.AddRulesToThread(thread.ID, rules)
 		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = app.models.Messaging.SetMessagesReadForUser(thread.ID, sender.ID, threadMessage.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	app.publishEvent(&data.Event{Type: data.EventMessage, ThreadID: &thread.ID, MessageID: &threadMessage.ID, ThreadMembers: true})
+	app.notifyNewMessage(thread.ID, title, &sender.User, body)
+
+	return thread, nil
+}
+
+// with draft or send_at set the thread is kept for the scheduler instead
+func (app *application) createThread(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	var input struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		threadRecipients
+		Draft  bool       `json:"draft"`
+		SendAt *time.Time `json:"send_at"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(input.Title != "", "title", "must be present")
+	v.Check(input.Body != "", "body", "must be present")
+
+	rules, err := app.validateThreadRules(v, input.Rules, sessionUser)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	if input.Draft || input.SendAt != nil {
+		app.scheduleMessage(w, r, sessionUser, nil, &input.Title, input.Body, &input.threadRecipients, input.Draft, input.SendAt)
+		return
+	}
+
+	thread, err := app.startThread(sessionUser, input.Title, input.Body, &input.threadRecipients, rules)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrMutedFromMessaging):
+			app.writeErrorResponse(w, r, http.StatusForbidden, err.Error())
+		case errors.Is(err, data.ErrNoSuchUsers) || errors.Is(err, data.ErrNoSuchGroups):
+			app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
 			app.writeInternalServerError(w, r, err)
-			return
 		}
+		return
 	}
 
 	err = app.outputJSON(w, http.StatusCreated, envelope{"thread": thread})
@@ -171,12 +315,21 @@ func (app *application) deleteThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// members have to be known before the thread is gone
+	memberIDs, err := app.models.Messaging.GetUserIDsInThread(thread.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
 	err = app.models.Messaging.DeleteThread(thread.ID)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
 	}
 
+	app.publishEvent(&data.Event{Type: data.EventThreadDeleted, ThreadID: &thread.ID, UserIDs: memberIDs})
+
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -203,22 +356,28 @@ func (app *application) lockThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if *thread.UserID != sessionUser.ID {
+	// administrators lock threads when moderating
+	if *thread.UserID != sessionUser.ID && *sessionUser.Role != data.RoleAdministrator {
 		app.notAllowed(w, r)
 		return
 	}
 
-	if *thread.Locked {
+	isAdmin := *sessionUser.Role == data.RoleAdministrator
+
+	// an administrator can still take over a lock set by the creator
+	if *thread.Locked && (*thread.LockedByAdmin || !isAdmin) {
 		app.writeErrorResponse(w, r, http.StatusConflict, data.ErrThreadAlreadyLocked.Error())
 		return
 	}
 
-	err = app.models.Messaging.SetThreadLocked(thread.ID, true)
+	err = app.models.Messaging.SetThreadLocked(thread.ID, true, isAdmin)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
 	}
 
+	app.publishEvent(&data.Event{Type: data.EventThreadLocked, ThreadID: &thread.ID, ThreadMembers: true})
+
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -245,7 +404,10 @@ func (app *application) unlockThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if *thread.UserID != sessionUser.ID {
+	isAdmin := *sessionUser.Role == data.RoleAdministrator
+
+	// the creator can't lift a moderation lock
+	if (*thread.UserID != sessionUser.ID || *thread.LockedByAdmin) && !isAdmin {
 		app.notAllowed(w, r)
 		return
 	}
@@ -255,12 +417,14 @@ func (app *application) unlockThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = app.models.Messaging.SetThreadLocked(thread.ID, false)
+	err = app.models.Messaging.SetThreadLocked(thread.ID, false, false)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
 	}
 
+	app.publishEvent(&data.Event{Type: data.EventThreadUnlocked, ThreadID: &thread.ID, ThreadMembers: true})
+
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -293,8 +457,9 @@ func (app *application) addMembersToThread(w http.ResponseWriter, r *http.Reques
 	}
 
 	var input struct {
-		UserIDs  []int `json:"user_ids"`
-		GroupIDs []int `json:"group_ids"`
+		UserIDs  []int              `json:"user_ids"`
+		GroupIDs []int              `json:"group_ids"`
+		Rules    []*threadRuleInput `json:"rules"`
 	}
 
 	err = app.inputJSON(w, r, &input)
@@ -303,6 +468,19 @@ func (app *application) addMembersToThread(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	v := validator.NewValidator()
+
+	rules, err := app.validateThreadRules(v, input.Rules, sessionUser)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
 	badIDs, err := app.verifyUserAndGroupIDs(input.UserIDs, input.GroupIDs, sessionUser.ID, *sessionUser.Role)
 	if err != nil {
 		switch {
@@ -331,6 +509,16 @@ func (app *application) addMembersToThread(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	if len(rules) > 0 {
+		err = app.models.Messaging.AddRulesToThread(thread.ID, rules)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	app.publishEvent(&data.Event{Type: data.EventMembersAdded, ThreadID: &thread.ID, ThreadMembers: true})
+
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -365,6 +553,7 @@ func (app *application) removeMembersFromThread(w http.ResponseWriter, r *http.R
 	var input struct {
 		UserIDs  []int `json:"user_ids"`
 		GroupIDs []int `json:"group_ids"`
+		RuleIDs  []int `json:"rule_ids"`
 	}
 
 	err = app.inputJSON(w, r, &input)
@@ -373,6 +562,13 @@ func (app *application) removeMembersFromThread(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// removed members are told too
+	memberIDs, err := app.models.Messaging.GetUserIDsInThread(thread.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
 	var removeUserIDs []int
 
 	for _, id := range input.UserIDs {
@@ -398,6 +594,30 @@ func (app *application) removeMembersFromThread(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	if len(input.RuleIDs) > 0 {
+		err = app.models.Messaging.RemoveRulesFromThread(thread.ID, input.RuleIDs)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	// the ones still in the thread are found by the listener, only the removed ones are sent along
+	remainingIDs, err := app.models.Messaging.GetUserIDsInThread(thread.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	var removedIDs []int
+	for _, id := range memberIDs {
+		if !slices.Contains(remainingIDs, id) {
+			removedIDs = append(removedIDs, id)
+		}
+	}
+
+	app.publishEvent(&data.Event{Type: data.EventMembersRemoved, ThreadID: &thread.ID, UserIDs: removedIDs, ThreadMembers: true})
+
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -409,19 +629,50 @@ func (app *application) getThreadsForUser(w http.ResponseWriter, r *http.Request
 
 	search := r.URL.Query().Get("search")
 
-	threads, err := app.models.Messaging.GetThreadsForUser(sessionUser.ID, search)
+	// archived threads are only listed with a filter asking for them
+	filter := r.URL.Query().Get("filter")
+	if filter != "" && !slices.Contains(data.ThreadFilters, filter) {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, "invalid filter")
+		return
+	}
+
+	limit, err := readPageLimit(r)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var cursor *data.ThreadCursor
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		cursor, err = data.ParseThreadCursor(c)
+		if err != nil {
+			app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// one extra to know whether there is another page
+	threads, err := app.models.Messaging.GetThreadsForUser(sessionUser.ID, search, app.config.Messaging.SearchLanguage, filter, cursor, limit+1)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
 	}
 
-	err = app.outputJSON(w, http.StatusOK, envelope{"threads": threads})
+	var nextCursor *string
+	if len(threads) > limit {
+		threads = threads[:limit]
+		last := threads[limit-1]
+		nextCursor = helpers.ToPtr(data.ThreadCursor{Pinned: *last.Pinned, UpdatedAt: *last.UpdatedAt, ID: last.ID}.String())
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"threads": threads, "next_cursor": nextCursor})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 	}
 }
 
-func (app *application) createMessage(w http.ResponseWriter, r *http.Request) {
+// sets the user's own archived, muted and pinned flags for the thread, the ones not given are kept
+func (app *application) setThreadState(w http.ResponseWriter, r *http.Request) {
 	sessionUser := app.getUserFromContext(r)
 
 	threadID, err := strconv.Atoi(chi.URLParam(r, "id"))
@@ -452,7 +703,9 @@ func (app *application) createMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var input struct {
-		Body string `json:"body"`
+		Archived *bool `json:"archived"`
+		Muted    *bool `json:"muted"`
+		Pinned   *bool `json:"pinned"`
 	}
 
 	err = app.inputJSON(w, r, &input)
@@ -461,21 +714,85 @@ func (app *application) createMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	v := validator.NewValidator()
+	state, err := app.models.Messaging.GetThreadState(thread.ID, sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
 
-	v.Check(input.Body != "", "body", "must be provided")
+	if input.Archived != nil {
+		state.Archived = input.Archived
+	}
+	if input.Muted != nil {
+		state.Muted = input.Muted
+	}
+	if input.Pinned != nil {
+		state.Pinned = input.Pinned
+	}
 
-	if !v.Valid() {
-		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+	err = app.models.Messaging.SetThreadState(state)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
 		return
 	}
 
+	// muting changes what counts as unread
+	if input.Muted != nil {
+		app.publishEvent(&data.Event{Type: data.EventUnread, UserIDs: []int{sessionUser.ID}})
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"state": state})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// a thread locked by its creator only takes messages from the creator,
+// one locked by an administrator only from administrators
+func canPostInThread(user *data.User, thread *data.ThreadExt) bool {
+	switch {
+	case !*thread.Locked:
+		return true
+	case *thread.LockedByAdmin:
+		return *user.Role == data.RoleAdministrator
+	default:
+		return thread.User.ID == user.ID
+	}
+}
+
+// ErrMutedFromMessaging, with the time the mute runs out, if the user can't post right now
+func (app *application) checkMessagingMute(userID int) error {
+	mute, err := app.models.Moderation.GetActiveMessagingMute(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNotMuted):
+			return nil
+		default:
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w until %s", data.ErrMutedFromMessaging, mute.MutedUntil.Format(time.RFC3339))
+}
+
+// adds a normal message to the thread and lets the other members know about it,
+// every way of posting goes through here so locks and mutes hold for all of them
+func (app *application) addMessageToThread(thread *data.ThreadExt, sender *data.User, body string) (*data.Message, error) {
+	if !canPostInThread(sender, thread) {
+		return nil, data.ErrThreadLocked
+	}
+
+	err := app.checkMessagingMute(sender.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	currentTime := time.Now().UTC()
 
 	message := &data.Message{
 		ThreadID:  &thread.ID,
-		UserID:    &sessionUser.ID,
-		Body:      &input.Body,
+		UserID:    &sender.ID,
+		Body:      &body,
 		Type:      helpers.ToPtr(data.MsgTypeNormal),
 		CreatedAt: &currentTime,
 		UpdatedAt: &currentTime,
@@ -483,27 +800,103 @@ func (app *application) createMessage(w http.ResponseWriter, r *http.Request) {
 
 	err = app.models.Messaging.InsertMessage(message)
 	if err != nil {
-		app.writeInternalServerError(w, r, err)
-		return
+		return nil, err
 	}
 
 	err = app.models.Messaging.SetThreadUpdatedAt(thread.ID)
 	if err != nil {
-		app.writeInternalServerError(w, r, err)
-		return
+		return nil, err
 	}
 
 	err = app.models.Messaging.SetThreadAsUnreadForAll(thread.ID)
 	if err != nil {
-		app.writeInternalServerError(w, r, err)
+		return nil, err
+	}
+
+	err = app.models.Messaging.SetThreadAsReadForUser(thread.ID, sender.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.models.Messaging.SetMessagesReadForUser(thread.ID, sender.ID, message.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	app.publishEvent(&data.Event{Type: data.EventMessage, ThreadID: &thread.ID, MessageID: &message.ID, ThreadMembers: true})
+	app.notifyNewMessage(thread.ID, *thread.Title, sender, body)
+
+	return message, nil
+}
+
+func (app *application) createMessage(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	threadID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if threadID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchThread.Error())
 		return
 	}
 
-	err = app.models.Messaging.SetThreadAsReadForUser(thread.ID, sessionUser.ID)
+	thread, err := app.models.Messaging.GetThreadByID(threadID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchThread):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	ok, err := app.models.Messaging.IsUserInThread(sessionUser.ID, thread.ID)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
 	}
+	if !ok {
+		app.notAllowed(w, r)
+		return
+	}
+
+	var input struct {
+		Body   string     `json:"body"`
+		Draft  bool       `json:"draft"`
+		SendAt *time.Time `json:"send_at"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(input.Body != "", "body", "must be provided")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	if input.Draft || input.SendAt != nil {
+		app.scheduleMessage(w, r, sessionUser, &thread.ID, nil, input.Body, nil, input.Draft, input.SendAt)
+		return
+	}
+
+	_, err = app.addMessageToThread(thread, &sessionUser.User, input.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrThreadLocked):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		case errors.Is(err, data.ErrMutedFromMessaging):
+			app.writeErrorResponse(w, r, http.StatusForbidden, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
 
 	err = app.outputJSON(w, http.StatusCreated, envelope{"message": "success"})
 	if err != nil {
@@ -537,7 +930,7 @@ func (app *application) updateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if *message.UserID != sessionUser.ID || !ok {
+	if *message.UserID != sessionUser.ID || !ok || message.HiddenAt != nil {
 		app.notAllowed(w, r)
 		return
 	}
@@ -562,14 +955,54 @@ func (app *application) updateMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if *message.Body != input.Body {
+		err = app.checkMessagingMute(sessionUser.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrMutedFromMessaging):
+				app.writeErrorResponse(w, r, http.StatusForbidden, err.Error())
+			default:
+				app.writeInternalServerError(w, r, err)
+			}
+			return
+		}
+
+		currentTime := time.Now().UTC()
+
+		edit := &data.MessageEdit{
+			MessageID: &message.ID,
+			Body:      message.Body,
+			EditedAt:  &currentTime,
+		}
+
 		message.Body = &input.Body
-		message.UpdatedAt = helpers.ToPtr(time.Now().UTC())
+		message.UpdatedAt = &currentTime
+
+		tx, err := app.models.Messaging.DB.Begin()
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		defer tx.Rollback()
 
-		err = app.models.Messaging.UpdateMessage(message)
+		err = app.models.Messaging.InsertMessageEdit(tx, edit)
 		if err != nil {
 			app.writeInternalServerError(w, r, err)
 			return
 		}
+
+		err = app.models.Messaging.UpdateMessage(tx, message)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		app.publishEvent(&data.Event{Type: data.EventMessageUpdated, ThreadID: message.ThreadID, MessageID: &message.ID, ThreadMembers: true})
 	}
 
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
@@ -619,12 +1052,43 @@ func (app *application) deleteMessage(w http.ResponseWriter, r *http.Request) {
 		app.writeInternalServerError(w, r, err)
 	}
 
+	app.publishEvent(&data.Event{Type: data.EventMessageDeleted, ThreadID: message.ThreadID, MessageID: &message.ID, ThreadMembers: true})
+
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 	}
 }
 
+// reads a message ID from the query parameter, the message has to be in the thread
+func (app *application) readMessageCursor(r *http.Request, param string, threadID int) (*data.Message, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	messageID, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, data.ErrInvalidCursor
+	}
+
+	message, err := app.models.Messaging.GetMessageByID(messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchMessage):
+			return nil, data.ErrInvalidCursor
+		default:
+			return nil, err
+		}
+	}
+
+	if *message.ThreadID != threadID {
+		return nil, data.ErrInvalidCursor
+	}
+
+	return message, nil
+}
+
 func (app *application) getThread(w http.ResponseWriter, r *http.Request) {
 	sessionUser := app.getUserFromContext(r)
 
@@ -655,19 +1119,196 @@ func (app *application) getThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	messages, err := app.models.Messaging.GetAllMessagesByThreadID(thread.ID)
+	limit, err := readPageLimit(r)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 'before' pages back through older messages, 'after' syncs newer ones
+	before, err := app.readMessageCursor(r, "before", thread.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidCursor):
+			app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	after, err := app.readMessageCursor(r, "after", thread.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidCursor):
+			app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if before != nil && after != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, "only one of before and after can be given")
+		return
+	}
+
+	// one extra to know whether there are more in that direction
+	messages, err := app.models.Messaging.GetMessagesByThreadID(thread.ID, before, after, limit+1)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
 	}
 
+	hasMore := len(messages) > limit
+	if hasMore {
+		if after != nil {
+			messages = messages[:limit]
+		} else {
+			messages = messages[1:]
+		}
+	}
+
+	if *sessionUser.Role != data.RoleAdministrator {
+		for _, m := range messages {
+			hideMessageContent(&m.Message)
+		}
+	}
+
 	err = app.models.Messaging.SetThreadAsReadForUser(thread.ID, sessionUser.ID)
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 		return
 	}
 
-	err = app.outputJSON(w, http.StatusOK, envelope{"thread": thread, "messages": messages})
+	if len(messages) > 0 {
+		err = app.models.Messaging.SetMessagesReadForUser(thread.ID, sessionUser.ID, messages[len(messages)-1].ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	app.publishEvent(&data.Event{Type: data.EventUnread, UserIDs: []int{sessionUser.ID}})
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"thread": thread, "messages": messages, "has_more": hasMore})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// members other than the creator and the last message each of them has read
+func (app *application) getReceiptsForThread(thread *data.ThreadExt) ([]*data.ThreadReceipt, error) {
+	memberIDs, err := app.models.Messaging.GetUserIDsInThread(thread.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipientIDs []int
+	for _, id := range memberIDs {
+		if id != *thread.UserID {
+			recipientIDs = append(recipientIDs, id)
+		}
+	}
+
+	if len(recipientIDs) == 0 {
+		return []*data.ThreadReceipt{}, nil
+	}
+
+	return app.models.Messaging.GetThreadReceipts(thread.ID, recipientIDs)
+}
+
+// a message has been read by everyone whose last read message is that one or a later one
+func (app *application) getThreadReceipts(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	threadID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if threadID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchThread.Error())
+		return
+	}
+
+	thread, err := app.models.Messaging.GetThreadByID(threadID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchThread):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if *thread.UserID != sessionUser.ID {
+		app.notAllowed(w, r)
+		return
+	}
+
+	receipts, err := app.getReceiptsForThread(thread)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"thread": thread, "receipts": receipts})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getMessageReceipts(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	messageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if messageID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMessage.Error())
+		return
+	}
+
+	message, err := app.models.Messaging.GetMessageByID(messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchMessage):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	thread, err := app.models.Messaging.GetThreadByID(*message.ThreadID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if *thread.UserID != sessionUser.ID {
+		app.notAllowed(w, r)
+		return
+	}
+
+	receipts, err := app.getReceiptsForThread(thread)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	read := []*data.ThreadReceipt{}
+	unread := []*data.ThreadReceipt{}
+
+	for _, rc := range receipts {
+		if rc.LastReadMessageID != nil && *rc.LastReadMessageID >= message.ID {
+			read = append(read, rc)
+		} else {
+			unread = append(unread, rc)
+		}
+	}
+
+	if *sessionUser.Role != data.RoleAdministrator {
+		hideMessageContent(message)
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": message, "read": read, "unread": unread})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 	}
@@ -715,7 +1356,13 @@ func (app *application) getThreadMembers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = app.outputJSON(w, http.StatusOK, envelope{"thread": thread, "users": users, "groups": groups})
+	rules, err := app.models.Messaging.GetRulesInThread(thread.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"thread": thread, "users": users, "groups": groups, "rules": rules})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
 	}
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -19,6 +20,12 @@ func (app *application) authenticateSession(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 
+		// EventSource can't set headers, so the event stream is opened with a ticket in the query instead
+		if authHeader == "" && strings.TrimSuffix(r.URL.Path, "/") == "/me/events" && r.URL.Query().Has("ticket") {
+			app.authenticateEventsTicket(next, w, r)
+			return
+		}
+
 		if authHeader == "" {
 			next.ServeHTTP(w, r)
 			return
@@ -52,6 +59,22 @@ func (app *application) authenticateSession(next http.Handler) http.Handler {
 	})
 }
 
+func (app *application) authenticateEventsTicket(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	user, err := app.models.Users.GetUserByEventsTicket(r.URL.Query().Get("ticket"))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidToken):
+			app.writeErrorResponse(w, r, http.StatusUnauthorized, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	r = app.setUserForContext(user, r)
+	next.ServeHTTP(w, r)
+}
+
 func (app *application) requireAuthenticatedUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := app.getUserFromContext(r)
@@ -94,9 +117,11 @@ func (app *application) log(next http.Handler) http.Handler {
 
 		t1 := time.Now()
 
+		target := redactedPath(r.URL)
+
 		log := &data.Log{
 			Method: &r.Method,
-			Target: helpers.ToPtr(r.URL.EscapedPath()),
+			Target: &target,
 			IP:     helpers.ToPtr(app.getIP(r)),
 			At:     helpers.ToPtr(time.Now().UTC()),
 		}
@@ -117,3 +142,40 @@ func (app *application) log(next http.Handler) http.Handler {
 		app.models.Logs.InsertLog(log)
 	})
 }
+
+// the request path as it can be logged, with secrets in it replaced
+func redactedPath(u *url.URL) string {
+	path := u.EscapedPath()
+
+	// feed tokens are secrets, keep them out of the logs
+	if strings.HasPrefix(path, "/calendar-feeds/") {
+		return "/calendar-feeds/REDACTED"
+	}
+
+	return path
+}
+
+// the request path and query as they can be logged
+func redactedURI(u *url.URL) string {
+	uri := redactedPath(u)
+	if u.RawQuery != "" {
+		// the event stream ticket is in the query
+		if strings.TrimSuffix(uri, "/") == "/me/events" {
+			return uri + "?REDACTED"
+		}
+		uri += "?" + u.RawQuery
+	}
+
+	return uri
+}
+
+// chi's request logger, but with the secrets in urls replaced
+type redactingLogFormatter struct {
+	middleware.DefaultLogFormatter
+}
+
+func (f *redactingLogFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	redacted := *r
+	redacted.RequestURI = redactedURI(r.URL)
+	return f.DefaultLogFormatter.NewLogEntry(&redacted)
+}
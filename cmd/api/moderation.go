@@ -0,0 +1,392 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+// members see that a hidden message was there, but not what it said
+func hideMessageContent(m *data.Message) {
+	if m.HiddenAt != nil {
+		m.Body = nil
+		m.HiddenBy = nil
+	}
+}
+
+// the message from the URL, if the user is in its thread
+func (app *application) getMessageForMember(w http.ResponseWriter, r *http.Request, sessionUser *data.UserExt) (*data.Message, bool) {
+	messageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if messageID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMessage.Error())
+		return nil, false
+	}
+
+	message, err := app.models.Messaging.GetMessageByID(messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchMessage):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	ok, err := app.models.Messaging.IsUserInThread(sessionUser.ID, *message.ThreadID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return nil, false
+	}
+	if !ok {
+		app.notAllowed(w, r)
+		return nil, false
+	}
+
+	return message, true
+}
+
+func (app *application) getMessageEdits(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	message, ok := app.getMessageForMember(w, r, sessionUser)
+	if !ok {
+		return
+	}
+
+	if message.HiddenAt != nil && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	edits, err := app.models.Messaging.GetMessageEdits(message.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": message, "edits": edits})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) reportMessage(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	message, ok := app.getMessageForMember(w, r, sessionUser)
+	if !ok {
+		return
+	}
+
+	if *message.UserID == sessionUser.ID {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, "can't report own message")
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(input.Reason != "", "reason", "must be provided")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	currentTime := time.Now().UTC()
+
+	report := &data.MessageReport{
+		MessageID: &message.ID,
+		UserID:    &sessionUser.ID,
+		Reason:    &input.Reason,
+		CreatedAt: &currentTime,
+	}
+
+	err = app.models.Moderation.InsertMessageReport(report)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getMessageReports(w http.ResponseWriter, r *http.Request) {
+	reports, err := app.models.Moderation.GetUnresolvedMessageReports()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"reports": reports})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// closes a report without hiding the message
+func (app *application) resolveMessageReport(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	reportID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if reportID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchReport.Error())
+		return
+	}
+
+	report, err := app.models.Moderation.GetMessageReportByID(reportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchReport):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.Moderation.ResolveMessageReport(report.ID, sessionUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrReportAlreadyResolved):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// hides the message from thread members and resolves its reports
+func (app *application) hideMessage(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	messageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if messageID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMessage.Error())
+		return
+	}
+
+	message, err := app.models.Messaging.GetMessageByID(messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchMessage):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if message.HiddenAt != nil {
+		app.writeErrorResponse(w, r, http.StatusConflict, data.ErrMessageAlreadyHidden.Error())
+		return
+	}
+
+	tx, err := app.models.Messaging.DB.Begin()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = app.models.Messaging.SetMessageHidden(tx, message.ID, &sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.models.Moderation.ResolveReportsForMessage(tx, message.ID, sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	app.publishEvent(&data.Event{Type: data.EventMessageUpdated, ThreadID: message.ThreadID, MessageID: &message.ID, ThreadMembers: true})
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) unhideMessage(w http.ResponseWriter, r *http.Request) {
+	messageID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if messageID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchMessage.Error())
+		return
+	}
+
+	message, err := app.models.Messaging.GetMessageByID(messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchMessage):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if message.HiddenAt == nil {
+		app.writeErrorResponse(w, r, http.StatusConflict, data.ErrMessageNotHidden.Error())
+		return
+	}
+
+	tx, err := app.models.Messaging.DB.Begin()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = app.models.Messaging.SetMessageHidden(tx, message.ID, nil)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	app.publishEvent(&data.Event{Type: data.EventMessageUpdated, ThreadID: message.ThreadID, MessageID: &message.ID, ThreadMembers: true})
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getMessagingMutes(w http.ResponseWriter, r *http.Request) {
+	mutes, err := app.models.Moderation.GetActiveMessagingMutes()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"mutes": mutes})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// keeps the user from creating threads and posting or editing messages until the given time
+func (app *application) muteUser(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	user, err := app.models.Users.GetUserByID(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUser):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Until  *time.Time `json:"until"`
+		Reason *string    `json:"reason"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	currentTime := time.Now().UTC()
+
+	v := validator.NewValidator()
+
+	v.Check(input.Until != nil, "until", "must be provided")
+	v.Check(input.Until == nil || input.Until.After(currentTime), "until", "must be in the future")
+	v.Check(user.ID != sessionUser.ID, "user", "can't mute yourself")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	mute := &data.MessagingMute{
+		UserID:     &user.ID,
+		MutedBy:    &sessionUser.ID,
+		Reason:     input.Reason,
+		MutedUntil: input.Until,
+		CreatedAt:  &currentTime,
+	}
+
+	err = app.models.Moderation.SetMessagingMute(mute)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"mute": mute})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) unmuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	err = app.models.Moderation.DeleteMessagingMute(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNotMuted):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
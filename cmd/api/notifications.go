@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/go-chi/chi/v5"
+)
+
+func (app *application) getNotificationsForUser(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, err := app.models.Notifications.GetNotificationsForUser(sessionUser.ID, unreadOnly)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"notifications": notifications})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) markNotificationRead(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	notificationID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if notificationID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchNotification.Error())
+		return
+	}
+
+	notification, err := app.models.Notifications.GetNotificationByID(notificationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchNotification):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if *notification.UserID != sessionUser.ID {
+		app.notAllowed(w, r)
+		return
+	}
+
+	err = app.models.Notifications.SetNotificationRead(notification.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) markAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	err := app.models.Notifications.SetAllNotificationsRead(sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/annusingmar/lavurso-backend/internal/webpush"
+)
+
+const (
+	pushTTL          = 24 * time.Hour
+	pushMaxBodyRunes = 500
+)
+
+func (config push) openPushClient() *webpush.Client {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.VAPIDPrivateKey == "" {
+		key, err := webpush.GeneratePrivateKey()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		log.Fatalf("push: vapid_private_key must be set, for example to this newly generated key: %s\n", key)
+	}
+
+	c, err := webpush.NewClient(config.VAPIDPrivateKey, config.Subject)
+	if err != nil {
+		log.Fatalln("push:", err)
+	}
+	c.AllowPrivate = config.AllowHTTP
+
+	return c
+}
+
+// delivers a notification by email and web push to the users, and their parents with withParents set
+func (app *application) notify(eventType string, userIDs []int, withParents bool, subject, body string) {
+	app.queueEmails(eventType, userIDs, withParents, "", subject, body)
+	app.sendPush(eventType, userIDs, withParents, subject, body)
+}
+
+// pushes are sent in the background, failures are only logged
+func (app *application) sendPush(eventType string, userIDs []int, withParents bool, title, body string) {
+	if app.push == nil || len(userIDs) == 0 {
+		return
+	}
+
+	go func() {
+		err := app.deliverPush(eventType, userIDs, withParents, title, body)
+		if err != nil {
+			app.errorLogger.Println("push:", err)
+		}
+	}()
+}
+
+func (app *application) deliverPush(eventType string, userIDs []int, withParents bool, title, body string) error {
+	recipients, err := app.models.Push.GetPushRecipients(eventType, userIDs, withParents)
+	if err != nil {
+		return err
+	}
+
+	if runes := []rune(body); len(runes) > pushMaxBodyRunes {
+		body = string(runes[:pushMaxBodyRunes]) + "…"
+	}
+
+	for _, rc := range recipients {
+		recipientTitle := title
+		if rc.UserID != rc.ForUserID {
+			recipientTitle = fmt.Sprintf("%s: %s", rc.ForName, title)
+		}
+
+		payload, err := json.Marshal(envelope{"type": eventType, "title": recipientTitle, "body": body})
+		if err != nil {
+			return err
+		}
+
+		err = app.push.Send(&webpush.Subscription{Endpoint: rc.Endpoint, P256dh: rc.P256dh, Auth: rc.Auth}, payload, pushTTL)
+		switch {
+		case errors.Is(err, webpush.ErrSubscriptionExpired):
+			err = app.models.Push.DeletePushSubscriptionByID(rc.ID)
+			if err != nil {
+				return err
+			}
+		case err != nil:
+			app.errorLogger.Println("push:", err)
+		}
+	}
+
+	return nil
+}
+
+func (app *application) getPushKey(w http.ResponseWriter, r *http.Request) {
+	if app.push == nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, "push notifications not enabled")
+		return
+	}
+
+	err := app.outputJSON(w, http.StatusOK, envelope{"public_key": app.push.PublicKey()})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) subscribePush(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	if app.push == nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, "push notifications not enabled")
+		return
+	}
+
+	// same shape as PushSubscription.toJSON() in the browser
+	var input struct {
+		Endpoint string `json:"endpoint"`
+		Keys     struct {
+			P256dh string `json:"p256dh"`
+			Auth   string `json:"auth"`
+		} `json:"keys"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	endpoint, err := url.Parse(input.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		v.Add("endpoint", "must be a valid URL")
+	} else if endpoint.Scheme != "https" && !(endpoint.Scheme == "http" && app.config.Push.AllowHTTP) {
+		v.Add("endpoint", "must be a https URL")
+	} else if app.push.CheckEndpoint(input.Endpoint) != nil {
+		v.Add("endpoint", "must be a public push service")
+	}
+
+	subscription := &webpush.Subscription{Endpoint: input.Endpoint, P256dh: input.Keys.P256dh, Auth: input.Keys.Auth}
+	v.Check(subscription.Validate() == nil, "keys", "must be valid")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	currentTime := time.Now().UTC()
+
+	s := &data.PushSubscription{
+		UserID:    &sessionUser.ID,
+		SessionID: sessionUser.SessionID,
+		Endpoint:  &subscription.Endpoint,
+		P256dh:    &subscription.P256dh,
+		Auth:      &subscription.Auth,
+		CreatedAt: &currentTime,
+	}
+
+	err = app.models.Push.InsertPushSubscription(s)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) unsubscribePush(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	var input struct {
+		Endpoint string `json:"endpoint"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(input.Endpoint != "", "endpoint", "must be provided")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.Push.DeletePushSubscriptionForSession(*sessionUser.SessionID, input.Endpoint)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/mailer"
+)
+
+const (
+	maxInboundEmailSize = 10 << 20 // 10 MiB
+	inboundTokenHeader  = "X-Inbound-Token"
+)
+
+// takes a raw email sent to a thread's reply address and adds it to the thread as a message from the user.
+// The token is sent in a header so it doesn't end up in access logs
+func (app *application) receiveEmailReply(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get(inboundTokenHeader)
+	if !app.config.Email.repliesEnabled() || app.config.Email.InboundToken == "" || app.config.Email.InboundAuthservID == "" ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(app.config.Email.InboundToken)) != 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxInboundEmailSize)
+
+	msg, err := mailer.ParseInbound(r.Body)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	threadID, userID, err := msg.ReplyTarget(app.config.Email.ReplySecret, app.config.Email.ReplyDomain)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := app.models.Users.GetUserByID(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUser):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	// the address was sent to this user only, a reply from anyone else was forwarded.
+	// From is only trusted when our mail server checked it
+	if user.Email == nil || !strings.EqualFold(*user.Email, msg.From) || !*user.Active || *user.Archived ||
+		!msg.SenderVerified(app.config.Email.InboundAuthservID) {
+		app.notAllowed(w, r)
+		return
+	}
+
+	thread, err := app.models.Messaging.GetThreadByID(threadID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchThread):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	ok, err := app.models.Messaging.IsUserInThread(user.ID, thread.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	if !ok {
+		app.notAllowed(w, r)
+		return
+	}
+
+	body := mailer.StripReply(msg.Text)
+	if body == "" {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, "empty reply")
+		return
+	}
+
+	_, err = app.addMessageToThread(thread, &user.User, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrThreadLocked):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		case errors.Is(err, data.ErrMutedFromMessaging):
+			app.writeErrorResponse(w, r, http.StatusForbidden, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
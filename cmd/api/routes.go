@@ -1,7 +1,10 @@
 package main
 
 import (
+	"log"
 	"net/http"
+	"os"
+	"runtime"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -11,7 +14,10 @@ import (
 func (app *application) routes() http.Handler {
 	mux := chi.NewRouter()
 
-	mux.Use(middleware.Logger)
+	mux.Use(middleware.RequestLogger(&redactingLogFormatter{middleware.DefaultLogFormatter{
+		Logger:  log.New(os.Stdout, "", log.LstdFlags),
+		NoColor: runtime.GOOS == "windows",
+	}}))
 	mux.Use(middleware.Recoverer)
 	mux.Use(middleware.StripSlashes)
 
@@ -32,6 +38,12 @@ func (app *application) routes() http.Handler {
 	// authenticate user
 	mux.Post("/authenticate", app.authenticateUser)
 
+	// iCalendar feed, authenticated by the secret token in the url
+	mux.Get("/calendar-feeds/{token}", app.getCalendarFeed)
+
+	// replies to thread emails as raw messages, authenticated by the inbound token in the X-Inbound-Token header
+	mux.Post("/inbound-email", app.receiveEmailReply)
+
 	// requires auth
 	mux.Group(func(mux chi.Router) {
 		mux.Use(app.requireAuthenticatedUser)
@@ -119,6 +131,9 @@ func (app *application) routes() http.Handler {
 
 			mux.Put("/classes/{id}/years", app.setYearsForClass)
 
+			// set the class's own test limits
+			mux.Put("/classes/{id}/workload", app.setWorkloadForClass)
+
 			// get all sessions for user
 			mux.Get("/users/{id}/sessions", app.allSessionsForUser)
 
@@ -126,6 +141,75 @@ func (app *application) routes() http.Handler {
 			mux.Delete("/users/{id}/sessions", app.expireAllSessionsForUser)
 
 			mux.Get("/logs", app.getLogs)
+
+			// update year name and dates
+			mux.Patch("/years/{id}", app.updateYear)
+
+			// add holiday, vacation or event to year's calendar
+			mux.Post("/years/{id}/calendar", app.createCalendarEntry)
+
+			// update calendar entry
+			mux.Patch("/calendar/{id}", app.updateCalendarEntry)
+
+			// delete calendar entry
+			mux.Delete("/calendar/{id}", app.deleteCalendarEntry)
+
+			// create room
+			mux.Post("/rooms", app.createRoom)
+
+			// update room
+			mux.Patch("/rooms/{id}", app.updateRoom)
+
+			// delete room
+			mux.Delete("/rooms/{id}", app.deleteRoom)
+
+			// create bell schedule period
+			mux.Post("/periods", app.createPeriod)
+
+			// update bell schedule period
+			mux.Patch("/periods/{id}", app.updatePeriod)
+
+			// delete bell schedule period
+			mux.Delete("/periods/{id}", app.deletePeriod)
+
+			// create bookable resource
+			mux.Post("/resources", app.createResource)
+
+			// update resource
+			mux.Patch("/resources/{id}", app.updateResource)
+
+			// delete resource
+			mux.Delete("/resources/{id}", app.deleteResource)
+
+			// assign substitute teacher for journal or lesson
+			mux.Post("/substitutions", app.createSubstitution)
+
+			// delete substitution
+			mux.Delete("/substitutions/{id}", app.deleteSubstitution)
+
+			// substituted lessons per teacher for date range
+			mux.Get("/substitutions/report", app.getSubstitutionReport)
+
+			// unresolved message reports
+			mux.Get("/moderation/reports", app.getMessageReports)
+
+			// resolve message report without hiding the message
+			mux.Put("/moderation/reports/{id}/resolve", app.resolveMessageReport)
+
+			// hide message from thread members
+			mux.Put("/messages/{id}/hide", app.hideMessage)
+
+			// show hidden message again
+			mux.Put("/messages/{id}/unhide", app.unhideMessage)
+
+			// get users currently muted from messaging
+			mux.Get("/moderation/mutes", app.getMessagingMutes)
+
+			// mute user from messaging until given time
+			mux.Put("/users/{id}/messaging-mute", app.muteUser)
+
+			// unmute user
+			mux.Delete("/users/{id}/messaging-mute", app.unmuteUser)
 		})
 
 		// requires at least role 'teacher'
@@ -147,6 +231,9 @@ func (app *application) routes() http.Handler {
 			// get classes for teacher
 			mux.Get("/teachers/{id}/classes", app.getClassesForTeacher)
 
+			// get excused absences for class teacher's students, filtered by excuse status
+			mux.Get("/teachers/{id}/excuses", app.getExcusesForTeacher)
+
 			// get students in class
 			mux.Get("/classes/{id}/students", app.getStudentsInClass)
 
@@ -192,6 +279,84 @@ func (app *application) routes() http.Handler {
 			// delete assignment
 			mux.Delete("/assignments/{id}", app.deleteAssignment)
 
+			// get student subgroups of journal
+			mux.Get("/journals/{id}/groups", app.getJournalGroupsForJournal)
+
+			// create student subgroup in journal
+			mux.Post("/journals/{id}/groups", app.createJournalGroup)
+
+			// update journal subgroup name or students
+			mux.Patch("/journal-groups/{id}", app.updateJournalGroup)
+
+			// delete journal subgroup
+			mux.Delete("/journal-groups/{id}", app.deleteJournalGroup)
+
+			// upload attachment for assignment
+			mux.Post("/assignments/{id}/attachments", app.uploadAssignmentAttachment)
+
+			// upload attachment for lesson
+			mux.Post("/lessons/{id}/attachments", app.uploadLessonAttachment)
+
+			// get which students have completed assignment
+			mux.Get("/assignments/{id}/completion", app.getCompletionForAssignment)
+
+			// get assignment and student completion rates for journal
+			mux.Get("/journals/{id}/completion", app.getCompletionForJournal)
+
+			// get student's assignment completion rates per journal
+			mux.Get("/students/{id}/completion", app.getCompletionForStudent)
+
+			// get students' submissions for assignment
+			mux.Get("/assignments/{id}/submissions", app.getSubmissionsForAssignment)
+
+			// return submission with feedback and optional mark
+			mux.Put("/submissions/{id}/feedback", app.returnSubmission)
+
+			// list all rooms
+			mux.Get("/rooms", app.listAllRooms)
+
+			// get bookings and timetable lessons for room
+			mux.Get("/rooms/{id}/bookings", app.getBookingsForRoom)
+
+			// list all bookable resources
+			mux.Get("/resources", app.listAllResources)
+
+			// get bookings for resource
+			mux.Get("/resources/{id}/bookings", app.getBookingsForResource)
+
+			// get rooms and resources free at given date and time
+			mux.Get("/bookings/availability", app.getAvailability)
+
+			// book room or resource
+			mux.Post("/bookings", app.createBooking)
+
+			// delete booking
+			mux.Delete("/bookings/{id}", app.deleteBooking)
+
+			// get own bookings
+			mux.Get("/me/bookings", app.getBookingsForUser)
+
+			// get weekly timetable slots for journal
+			mux.Get("/journals/{id}/timetable", app.getTimetableForJournal)
+
+			// add weekly timetable slot for journal
+			mux.Post("/journals/{id}/timetable", app.createTimetableSlot)
+
+			// delete timetable slot
+			mux.Delete("/timetable/{id}", app.deleteTimetableSlot)
+
+			// generate lessons from journal's timetable for date range
+			mux.Post("/journals/{id}/timetable/lessons", app.generateLessonsForJournal)
+
+			// get weekly timetable for teacher
+			mux.Get("/teachers/{id}/timetable", app.getTimetableForTeacher)
+
+			// get substitutions for journal
+			mux.Get("/journals/{id}/substitutions", app.getSubstitutionsForJournal)
+
+			// get substitutions teacher is doing or needs
+			mux.Get("/teachers/{id}/substitutions", app.getSubstitutionsForTeacher)
+
 			// get students and marks for lesson
 			mux.Get("/lessons/{id}/marks", app.getMarksForLesson)
 
@@ -215,6 +380,24 @@ func (app *application) routes() http.Handler {
 
 			// list all classes
 			mux.Get("/classes", app.listAllClasses)
+
+			// get tests per day and week for class
+			mux.Get("/classes/{id}/workload", app.getWorkloadForClass)
+
+			// list announcements, all for administrators and own ones for teachers
+			mux.Get("/announcements", app.getAnnouncements)
+
+			// create announcement
+			mux.Post("/announcements", app.createAnnouncement)
+
+			// update announcement
+			mux.Patch("/announcements/{id}", app.updateAnnouncement)
+
+			// delete announcement
+			mux.Delete("/announcements/{id}", app.deleteAnnouncement)
+
+			// get who has read and acknowledged announcement
+			mux.Get("/announcements/{id}/receipts", app.getAnnouncementReceipts)
 		})
 
 		// search for user with query param 'name' (minimum 4 characters)
@@ -229,6 +412,12 @@ func (app *application) routes() http.Handler {
 		// remove assignment done for student
 		mux.Delete("/students/{sid}/assignments/{aid}/done", app.removeAssignmentDoneForStudent)
 
+		// submit work for assignment
+		mux.Put("/students/{sid}/assignments/{aid}/submission", app.submitAssignment)
+
+		// get student's submission for assignment
+		mux.Get("/students/{sid}/assignments/{aid}/submission", app.getSubmissionForStudent)
+
 		// get current marks for student
 		mux.Get("/students/{id}/marks", app.getMarksForStudent)
 
@@ -244,18 +433,120 @@ func (app *application) routes() http.Handler {
 		// delete excuse for student
 		mux.Delete("/absences/{id}/excuse", app.deleteExcuseForStudent)
 
+		// accept or reject excuse
+		mux.Put("/absences/{id}/excuse/review", app.reviewExcuseForStudent)
+
+		// get substitute teachers for student's lessons
+		mux.Get("/students/{id}/substitutions", app.getSubstitutionsForStudent)
+
+		// get attendance summary for student
+		mux.Get("/students/{id}/attendance", app.getAttendanceForStudent)
+
+		// announce upcoming absence for student
+		mux.Post("/students/{id}/absence-notices", app.createAbsenceNotice)
+
+		// get absence notices for student
+		mux.Get("/students/{id}/absence-notices", app.getAbsenceNoticesForStudent)
+
+		// delete absence notice
+		mux.Delete("/absence-notices/{id}", app.deleteAbsenceNotice)
+
+		// upload attachment for excuse
+		mux.Post("/absences/{id}/excuse/attachments", app.uploadExcuseAttachment)
+
+		// get attachments for excuse
+		mux.Get("/absences/{id}/excuse/attachments", app.getExcuseAttachments)
+
+		// get attachments for assignment
+		mux.Get("/assignments/{id}/attachments", app.getAssignmentAttachments)
+
+		// get attachments for lesson
+		mux.Get("/lessons/{id}/attachments", app.getLessonAttachments)
+
+		// get own notifications, only unread ones with 'unread=true'
+		mux.Get("/me/notifications", app.getNotificationsForUser)
+
+		// mark all own notifications as read
+		mux.Put("/me/notifications/read", app.markAllNotificationsRead)
+
+		// mark notification as read
+		mux.Put("/me/notifications/{id}/read", app.markNotificationRead)
+
+		// get own email notification preferences
+		mux.Get("/me/notifications/preferences", app.getNotificationPreferences)
+
+		// set own email notification preferences, 'immediate', 'digest' or 'off' per event type
+		mux.Put("/me/notifications/preferences", app.setNotificationPreferences)
+
+		// get the public key to subscribe to push notifications with
+		mux.Get("/push/key", app.getPushKey)
+
+		// register a push subscription for the current session
+		mux.Post("/me/push/subscriptions", app.subscribePush)
+
+		// unregister a push subscription of the current session
+		mux.Delete("/me/push/subscriptions", app.unsubscribePush)
+
+		// get announcements for user, only unread ones with 'unread=true'
+		mux.Get("/me/announcements", app.getAnnouncementsForUser)
+
+		// mark announcement as read
+		mux.Put("/announcements/{id}/read", app.markAnnouncementRead)
+
+		// acknowledge announcement
+		mux.Put("/announcements/{id}/acknowledge", app.acknowledgeAnnouncement)
+
+		// upload attachment for submission
+		mux.Post("/submissions/{id}/attachments", app.uploadSubmissionAttachment)
+
+		// get attachments for submission
+		mux.Get("/submissions/{id}/attachments", app.getSubmissionAttachments)
+
+		// download attachment
+		mux.Get("/attachments/{id}", app.downloadAttachment)
+
+		// delete attachment
+		mux.Delete("/attachments/{id}", app.deleteAttachment)
+
+		// list bell schedule periods
+		mux.Get("/periods", app.listAllPeriods)
+
+		// get weekly timetable for class
+		mux.Get("/classes/{id}/timetable", app.getTimetableForClass)
+
 		// get groups by user id
 		mux.Get("/users/{id}/groups", app.getGroupsForUser)
 
-		// get all threads for user
+		// search messages in user's threads with ranking and highlighted snippets
+		mux.Get("/me/messages/search", app.searchMessages)
+
+		// get threads for user, paginated with 'cursor' and 'limit', 'filter' lists archived, muted, pinned or all threads
 		mux.Get("/me/threads", app.getThreadsForUser)
 
 		// does user have unread
 		mux.Get("/me/unread", app.userHasUnread)
 
-		// create thread
+		// stream of messaging events for user, EventSource opens it with a ticket in 'ticket'
+		mux.Get("/me/events", app.streamEvents)
+
+		// get a single use ticket for opening the event stream
+		mux.Post("/me/events/ticket", app.createEventsTicket)
+
+		// create thread, or with 'draft' or 'send_at' keep it to be sent later
 		mux.Post("/threads", app.createThread)
 
+		// get own drafts and scheduled threads and messages
+		mux.Get("/me/scheduled-messages", app.getScheduledMessagesForUser)
+
+		// edit draft or scheduled message
+		mux.Patch("/scheduled-messages/{id}", app.updateScheduledMessage)
+
+		// cancel scheduled message or delete draft
+		mux.Delete("/scheduled-messages/{id}", app.deleteScheduledMessage)
+
+		// archive, mute or pin thread for user
+		mux.Patch("/threads/{id}/state", app.setThreadState)
+
 		// lock thread
 		mux.Put("/threads/{id}/lock", app.lockThread)
 
@@ -274,7 +565,7 @@ func (app *application) routes() http.Handler {
 		// get members in threads
 		mux.Get("/threads/{id}/members", app.getThreadMembers)
 
-		// create message
+		// create message, or with 'draft' or 'send_at' keep it to be sent later
 		mux.Post("/threads/{id}/messages", app.createMessage)
 
 		// edit message
@@ -283,7 +574,25 @@ func (app *application) routes() http.Handler {
 		// delete message
 		mux.Delete("/messages/{id}", app.deleteMessage)
 
-		// get thread by id
+		// get earlier versions of edited message
+		mux.Get("/messages/{id}/edits", app.getMessageEdits)
+
+		// report message to administrators
+		mux.Post("/messages/{id}/report", app.reportMessage)
+
+		// upload attachment for message
+		mux.Post("/messages/{id}/attachments", app.uploadMessageAttachment)
+
+		// get attachments for message
+		mux.Get("/messages/{id}/attachments", app.getMessageAttachments)
+
+		// get read receipts for thread, for its creator
+		mux.Get("/threads/{id}/receipts", app.getThreadReceipts)
+
+		// get who has and hasn't read message, for the thread creator
+		mux.Get("/messages/{id}/receipts", app.getMessageReceipts)
+
+		// get thread with its latest messages, older ones with 'before' and newer ones with 'after'
 		mux.Get("/threads/{id}", app.getThread)
 
 		// delete session by id
@@ -313,6 +622,24 @@ func (app *application) routes() http.Handler {
 		// years for student's class
 		mux.Get("/students/{id}/years", app.getYearsForStudent)
 
+		// get calendar for current year
+		mux.Get("/calendar", app.getCalendarForCurrentYear)
+
+		// get calendar for year
+		mux.Get("/years/{id}/calendar", app.getCalendarForYear)
+
+		// list own calendar feeds
+		mux.Get("/me/calendar-feeds", app.getCalendarFeedsForUser)
+
+		// create calendar feed
+		mux.Post("/me/calendar-feeds", app.createCalendarFeed)
+
+		// replace calendar feed's token
+		mux.Post("/me/calendar-feeds/{id}/rotate", app.rotateCalendarFeedToken)
+
+		// revoke calendar feed
+		mux.Delete("/me/calendar-feeds/{id}", app.deleteCalendarFeed)
+
 		// start 2fa (generate secret)
 		mux.Post("/me/2fa", app.start2FA)
 
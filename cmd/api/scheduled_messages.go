@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+var errInvalidScheduledRecipients = errors.New("recipients are no longer valid")
+
+type scheduledMessageOutput struct {
+	*data.ScheduledMessage
+	Recipients *threadRecipients `json:"recipients,omitempty"`
+}
+
+func newScheduledMessageOutput(s *data.ScheduledMessage) (*scheduledMessageOutput, error) {
+	out := &scheduledMessageOutput{ScheduledMessage: s}
+
+	if s.Recipients != nil {
+		err := json.Unmarshal([]byte(*s.Recipients), &out.Recipients)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// checks the recipients of a thread that will be started later, the same checks are done again when it's sent
+func (app *application) validateScheduledRecipients(v *validator.Validator, recipients *threadRecipients, user *data.UserExt) error {
+	_, err := app.validateThreadRules(v, recipients.Rules, user)
+	if err != nil {
+		return err
+	}
+
+	badIDs, err := app.verifyUserAndGroupIDs(recipients.UserIDs, recipients.GroupIDs, user.ID, *user.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUsers):
+			v.Add("user_ids", fmt.Sprintf("%s: %v", err.Error(), badIDs))
+		case errors.Is(err, data.ErrNoSuchGroups):
+			v.Add("group_ids", fmt.Sprintf("%s: %v", err.Error(), badIDs))
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keeps a new thread (threadID nil) or a message to a thread for the scheduler to send at sendAt, or as a draft
+func (app *application) scheduleMessage(w http.ResponseWriter, r *http.Request, sessionUser *data.UserExt, threadID *int, title *string, body string, recipients *threadRecipients, draft bool, sendAt *time.Time) {
+	currentTime := time.Now().UTC()
+
+	v := validator.NewValidator()
+
+	v.Check(draft || sendAt.After(currentTime), "send_at", "must be in the future")
+
+	var recipientsJSON *string
+	if recipients != nil {
+		err := app.validateScheduledRecipients(v, recipients, sessionUser)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		b, err := json.Marshal(recipients)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		recipientsJSON = helpers.ToPtr(string(b))
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	scheduled := &data.ScheduledMessage{
+		UserID:     &sessionUser.ID,
+		ThreadID:   threadID,
+		Title:      title,
+		Body:       &body,
+		Recipients: recipientsJSON,
+		Draft:      &draft,
+		SendAt:     sendAt,
+		CreatedAt:  &currentTime,
+		UpdatedAt:  &currentTime,
+	}
+
+	err := app.models.Messaging.InsertScheduledMessage(scheduled)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	out, err := newScheduledMessageOutput(scheduled)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"scheduled_message": out})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getScheduledMessagesForUser(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	scheduled, err := app.models.Messaging.GetScheduledMessagesForUser(sessionUser.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	out := []*scheduledMessageOutput{}
+	for _, s := range scheduled {
+		o, err := newScheduledMessageOutput(s)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		out = append(out, o)
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"scheduled_messages": out})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// the scheduled message from the URL, if it's the user's own
+func (app *application) getScheduledMessageForAuthor(w http.ResponseWriter, r *http.Request) (*data.ScheduledMessage, bool) {
+	sessionUser := app.getUserFromContext(r)
+
+	scheduledID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if scheduledID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchScheduledMessage.Error())
+		return nil, false
+	}
+
+	scheduled, err := app.models.Messaging.GetScheduledMessageByID(scheduledID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchScheduledMessage):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	if *scheduled.UserID != sessionUser.ID {
+		app.notAllowed(w, r)
+		return nil, false
+	}
+
+	return scheduled, true
+}
+
+func (app *application) updateScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	scheduled, ok := app.getScheduledMessageForAuthor(w, r)
+	if !ok {
+		return
+	}
+
+	if scheduled.SentAt != nil {
+		app.writeErrorResponse(w, r, http.StatusConflict, data.ErrScheduledMessageSent.Error())
+		return
+	}
+
+	var input struct {
+		Title    *string             `json:"title"`
+		Body     *string             `json:"body"`
+		UserIDs  *[]int              `json:"user_ids"`
+		GroupIDs *[]int              `json:"group_ids"`
+		Rules    *[]*threadRuleInput `json:"rules"`
+		Draft    *bool               `json:"draft"`
+		SendAt   *time.Time          `json:"send_at"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	newThread := scheduled.ThreadID == nil
+	v.Check(newThread || input.Title == nil, "title", "only for new threads")
+	v.Check(newThread || (input.UserIDs == nil && input.GroupIDs == nil && input.Rules == nil), "recipients", "only for new threads")
+
+	if input.Title != nil {
+		scheduled.Title = input.Title
+	}
+	if input.Body != nil {
+		scheduled.Body = input.Body
+	}
+	if input.Draft != nil {
+		scheduled.Draft = input.Draft
+	}
+	if input.SendAt != nil {
+		scheduled.SendAt = input.SendAt
+	}
+
+	currentTime := time.Now().UTC()
+
+	v.Check(!newThread || *scheduled.Title != "", "title", "must be present")
+	v.Check(*scheduled.Body != "", "body", "must be present")
+	v.Check(*scheduled.Draft || (scheduled.SendAt != nil && scheduled.SendAt.After(currentTime)), "send_at", "must be in the future")
+
+	if newThread && v.Valid() {
+		var recipients threadRecipients
+		if scheduled.Recipients != nil {
+			err = json.Unmarshal([]byte(*scheduled.Recipients), &recipients)
+			if err != nil {
+				app.writeInternalServerError(w, r, err)
+				return
+			}
+		}
+
+		if input.UserIDs != nil {
+			recipients.UserIDs = *input.UserIDs
+		}
+		if input.GroupIDs != nil {
+			recipients.GroupIDs = *input.GroupIDs
+		}
+		if input.Rules != nil {
+			recipients.Rules = *input.Rules
+		}
+
+		err = app.validateScheduledRecipients(v, &recipients, sessionUser)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		b, err := json.Marshal(recipients)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		scheduled.Recipients = helpers.ToPtr(string(b))
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	scheduled.UpdatedAt = &currentTime
+	// a failed message is tried again with the changes
+	scheduled.Error = nil
+
+	err = app.models.Messaging.UpdateScheduledMessage(scheduled)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrScheduledMessageSent):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	out, err := newScheduledMessageOutput(scheduled)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"scheduled_message": out})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// cancels a scheduled message or deletes a draft, failed ones can be cleared too
+func (app *application) deleteScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	scheduled, ok := app.getScheduledMessageForAuthor(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.models.Messaging.DeleteScheduledMessage(scheduled.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrScheduledMessageSent):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+// sends the scheduled messages that are due, a message that can't be sent keeps the reason for its author
+func (app *application) sendScheduledMessages() error {
+	scheduled, err := app.models.Messaging.ClaimDueScheduledMessages()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range scheduled {
+		sendErr := app.deliverScheduledMessage(s)
+		if sendErr != nil {
+			app.errorLogger.Println("scheduled messages:", sendErr)
+			err = app.models.Messaging.SetScheduledMessageError(s.ID, sendErr)
+		} else {
+			err = app.models.Messaging.SetScheduledMessageSent(s.ID)
+		}
+		if err != nil {
+			app.errorLogger.Println("scheduled messages:", err)
+		}
+	}
+
+	return nil
+}
+
+func (app *application) deliverScheduledMessage(s *data.ScheduledMessage) error {
+	author, err := app.models.Users.GetUserByID(*s.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !*author.Active || *author.Archived {
+		return errors.New("author is no longer active")
+	}
+
+	if s.ThreadID == nil {
+		var recipients threadRecipients
+		if s.Recipients != nil {
+			err = json.Unmarshal([]byte(*s.Recipients), &recipients)
+			if err != nil {
+				return err
+			}
+		}
+
+		v := validator.NewValidator()
+
+		rules, err := app.validateThreadRules(v, recipients.Rules, author)
+		if err != nil {
+			return err
+		}
+		if !v.Valid() {
+			return errInvalidScheduledRecipients
+		}
+
+		_, err = app.startThread(author, *s.Title, *s.Body, &recipients, rules)
+		return err
+	}
+
+	thread, err := app.models.Messaging.GetThreadByID(*s.ThreadID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := app.models.Messaging.IsUserInThread(author.ID, thread.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("author is no longer in the thread")
+	}
+
+	_, err = app.addMessageToThread(thread, &author.User, *s.Body)
+	return err
+}
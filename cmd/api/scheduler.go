@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+)
+
+const schedulerInterval = time.Minute
+
+// runs periodic jobs until the context is cancelled
+func (app *application) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		err := app.publishAssignments()
+		if err != nil {
+			app.errorLogger.Println("scheduler:", err)
+		}
+
+		err = app.sendScheduledMessages()
+		if err != nil {
+			app.errorLogger.Println("scheduled messages:", err)
+		}
+
+		err = app.removeDeletedAttachmentFiles()
+		if err != nil {
+			app.errorLogger.Println("attachments:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishes assignments that are due and notifies the students they are for,
+// an assignment whose notifying fails is tried again on the next run
+func (app *application) publishAssignments() error {
+	err := app.models.Assignments.PublishDueAssignments()
+	if err != nil {
+		return err
+	}
+
+	assignments, err := app.models.Assignments.GetUnnotifiedAssignments()
+	if err != nil {
+		return err
+	}
+
+	for _, a := range assignments {
+		err = app.notifyPublishedAssignment(a)
+		if err != nil {
+			app.errorLogger.Println("scheduler:", err)
+		}
+	}
+
+	return nil
+}
+
+// adds the notifications and marks the assignment notified together
+func (app *application) notifyPublishedAssignment(a *data.Assignment) error {
+	journal, err := app.models.Journals.GetJournalByID(*a.JournalID)
+	if err != nil {
+		return err
+	}
+
+	studentIDs, err := app.models.Assignments.GetStudentIDsForAssignment(a.ID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := app.models.Assignments.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ok, err := app.models.Assignments.SetAssignmentNotified(tx, a.ID)
+	if err != nil {
+		return err
+	}
+	if !ok || len(studentIDs) == 0 {
+		return tx.Commit()
+	}
+
+	title := fmt.Sprintf("New %s in %s, due %s", *a.Type, *journal.Subject.Name, a.Deadline.String())
+	currentTime := time.Now().UTC()
+
+	var notifications []*data.Notification
+	for _, sid := range studentIDs {
+		sid := sid
+		notifications = append(notifications, &data.Notification{
+			UserID:       &sid,
+			Type:         helpers.ToPtr(data.NotificationAssignmentPublished),
+			Title:        &title,
+			Body:         a.Description,
+			AssignmentID: &a.ID,
+			CreatedAt:    &currentTime,
+		})
+	}
+
+	err = app.models.Notifications.InsertNotifications(tx, notifications)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"html"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+)
+
+// the language has to look like a configuration name, as the index check looks for it in the index definition
+var searchLanguageRX = regexp.MustCompile(`^[a-z_]+$`)
+
+func (config messaging) checkSearchLanguage(models data.Models) {
+	if !searchLanguageRX.MatchString(config.SearchLanguage) {
+		log.Fatalf("messaging: invalid search_language %q\n", config.SearchLanguage)
+	}
+
+	ok, err := models.Messaging.TextSearchConfigExists(config.SearchLanguage)
+	if err != nil {
+		log.Fatalln("messaging:", err)
+	}
+	if !ok {
+		log.Fatalf("messaging: no text search configuration %q in the database\n", config.SearchLanguage)
+	}
+
+	for _, index := range []struct{ table, column string }{{"messages", "body"}, {"threads", "title"}} {
+		ok, err = models.Messaging.TextSearchIndexExists(index.table, index.column, config.SearchLanguage)
+		if err != nil {
+			log.Fatalln("messaging:", err)
+		}
+		if !ok {
+			log.Fatalf("messaging: no index on %s for search_language %q, create one with: CREATE INDEX ON %s USING GIN (to_tsvector('%s', %s))\n",
+				index.table, config.SearchLanguage, index.table, config.SearchLanguage, index.column)
+		}
+	}
+}
+
+// escapes the snippet for showing as HTML, with the matches in <mark>
+func headlineHTML(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, data.HeadlineStart, "<mark>")
+	return strings.ReplaceAll(escaped, data.HeadlineStop, "</mark>")
+}
+
+// searches the messages of all the user's threads, 'q' takes web search syntax (quotes, or, -word),
+// 'author_id', 'thread_id', 'from' and 'until' narrow it down, pages with 'page' and 'limit'
+func (app *application) searchMessages(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	query := r.URL.Query()
+
+	v := validator.NewValidator()
+
+	search := &data.MessageSearch{
+		Query:    strings.TrimSpace(query.Get("q")),
+		Language: app.config.Messaging.SearchLanguage,
+	}
+
+	v.Check(search.Query != "", "q", "must be provided")
+
+	if param := query.Get("author_id"); param != "" {
+		authorID, err := strconv.Atoi(param)
+		v.Check(err == nil, "author_id", "must be a number")
+		search.AuthorID = &authorID
+	}
+
+	if param := query.Get("thread_id"); param != "" {
+		threadID, err := strconv.Atoi(param)
+		v.Check(err == nil, "thread_id", "must be a number")
+		search.ThreadID = &threadID
+	}
+
+	if param := query.Get("from"); param != "" {
+		from, err := types.ParseDate(param)
+		v.Check(err == nil, "from", "must be a date")
+		if err == nil {
+			search.From = from.Time
+		}
+	}
+
+	// until is inclusive
+	if param := query.Get("until"); param != "" {
+		until, err := types.ParseDate(param)
+		v.Check(err == nil, "until", "must be a date")
+		if err == nil {
+			nextDay := until.AddDate(0, 0, 1)
+			search.Until = &nextDay
+		}
+	}
+
+	if search.From != nil && search.Until != nil {
+		v.Check(search.From.Before(*search.Until), "until", "must not be before from")
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := readPageLimit(r)
+	if err != nil {
+		v.Add("limit", err.Error())
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	// one extra to know whether there is another page
+	search.Offset = (page - 1) * limit
+	search.Limit = limit + 1
+
+	results, err := app.models.Messaging.SearchMessages(sessionUser.ID, search)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	hasMore := len(results) > limit
+	if hasMore {
+		results = results[:limit]
+	}
+
+	for _, res := range results {
+		if res.Headline != nil {
+			res.Headline = helpers.ToPtr(headlineHTML(*res.Headline))
+		}
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"results": results, "page": page, "has_more": hasMore})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
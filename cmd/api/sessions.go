@@ -104,6 +104,12 @@ func (app *application) expireSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = app.models.Push.DeletePushSubscriptionsForSession(session.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -119,6 +125,12 @@ func (app *application) logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = app.models.Push.DeletePushSubscriptionsForSession(*sessionUser.SessionID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
 	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
 	if err != nil {
 		app.writeInternalServerError(w, r, err)
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log"
+
+	"github.com/annusingmar/lavurso-backend/internal/storage"
+)
+
+func (config fileStorage) openStorage() storage.Storage {
+	switch config.Backend {
+	case "local":
+		s, err := storage.NewLocal(config.LocalDir)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return s
+	case "s3":
+		s, err := storage.NewS3(config.S3Endpoint, config.S3Region, config.S3Bucket, config.S3AccessKey, config.S3SecretKey, config.S3PathStyle)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return s
+	default:
+		log.Fatalf("unknown storage backend %q\n", config.Backend)
+	}
+
+	return nil
+}
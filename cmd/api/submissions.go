@@ -0,0 +1,396 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slices"
+)
+
+// the student, their parents, journal teachers and administrators can see a submission
+func (app *application) canViewSubmission(user *data.UserExt, submission *data.SubmissionExt) (bool, error) {
+	if user.ID == *submission.StudentID || *user.Role == data.RoleAdministrator {
+		return true, nil
+	}
+
+	switch *user.Role {
+	case data.RoleTeacher:
+		journal, err := app.models.Journals.GetJournalByID(*submission.Assignment.JournalID)
+		if err != nil {
+			return false, err
+		}
+		return journal.IsUserTeacherOfJournal(user.ID), nil
+	case data.RoleParent:
+		return app.models.Users.IsUserParentOfStudent(*submission.StudentID, user.ID)
+	}
+
+	return false, nil
+}
+
+func (app *application) submitAssignment(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "sid"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	if sessionUser.ID != userID {
+		app.notAllowed(w, r)
+		return
+	}
+
+	if *sessionUser.Role != data.RoleStudent {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, data.ErrNotAStudent.Error())
+		return
+	}
+
+	assignmentID, err := strconv.Atoi(chi.URLParam(r, "aid"))
+	if assignmentID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAssignment.Error())
+		return
+	}
+
+	assignment, err := app.models.Assignments.GetAssignmentByID(assignmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchAssignment):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	ok, err := app.models.Assignments.IsAssignmentForStudent(sessionUser.ID, assignment.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	if !ok {
+		app.notAllowed(w, r)
+		return
+	}
+
+	var input struct {
+		Body *string `json:"body"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.Body != nil && *input.Body == "" {
+		input.Body = nil
+	}
+
+	submission := &data.Submission{
+		AssignmentID: &assignment.ID,
+		StudentID:    &sessionUser.ID,
+		Body:         input.Body,
+		Status:       helpers.ToPtr(data.SubmissionSubmitted),
+		SubmittedAt:  helpers.ToPtr(time.Now().UTC()),
+	}
+
+	err = app.models.Submissions.UpsertSubmission(submission)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.models.Assignments.SetAssignmentDoneForUserID(sessionUser.ID, assignment.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	result, err := app.models.Submissions.GetSubmissionByID(submission.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"submission": result})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getSubmissionForStudent(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "sid"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	assignmentID, err := strconv.Atoi(chi.URLParam(r, "aid"))
+	if assignmentID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAssignment.Error())
+		return
+	}
+
+	submission, err := app.models.Submissions.GetSubmissionForStudent(userID, assignmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchSubmission):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	ok, err := app.canViewSubmission(sessionUser, submission)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	if !ok {
+		app.notAllowed(w, r)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"submission": submission})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getSubmissionsForAssignment(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	assignmentID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if assignmentID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchAssignment.Error())
+		return
+	}
+
+	assignment, err := app.models.Assignments.GetAssignmentByID(assignmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchAssignment):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(*assignment.JournalID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	submissions, err := app.models.Submissions.GetSubmissionsForAssignment(assignment.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	students, err := app.models.Journals.GetStudentsByJournalID(journal.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	targetIDs, err := app.models.Assignments.GetStudentIDsForAssignment(assignment.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	// students the assignment is for who haven't submitted anything yet
+	missing := []*data.UserExt{}
+	for _, s := range students {
+		if slices.Contains(targetIDs, s.ID) && !slices.ContainsFunc(submissions, func(sub *data.SubmissionExt) bool { return *sub.StudentID == s.ID }) {
+			missing = append(missing, s)
+		}
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"submissions": submissions, "missing": missing})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) returnSubmission(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	submissionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if submissionID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchSubmission.Error())
+		return
+	}
+
+	submission, err := app.models.Submissions.GetSubmissionByID(submissionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchSubmission):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(*submission.Assignment.JournalID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	var input struct {
+		Feedback *string `json:"feedback"`
+		LessonID *int    `json:"lesson_id"`
+		Grade    *int    `json:"grade"`
+		Comment  *string `json:"comment"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.Feedback != nil && *input.Feedback == "" {
+		input.Feedback = nil
+	}
+	if input.Comment != nil && *input.Comment == "" {
+		input.Comment = nil
+	}
+
+	currentTime := time.Now().UTC()
+
+	v := validator.NewValidator()
+
+	var mark *data.Mark
+
+	// a grade puts a lesson mark into the journal, or changes the one given before
+	if input.Grade != nil {
+		allGradeIDs, err := app.models.Grades.GetAllGradeIDs()
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		v.Check(slices.Contains(allGradeIDs, *input.Grade), "grade", "invalid grade ID")
+
+		mark = &data.Mark{
+			GradeID:   input.Grade,
+			Comment:   input.Comment,
+			Type:      helpers.ToPtr(data.MarkLessonGrade),
+			TeacherID: &sessionUser.ID,
+			UpdatedAt: &currentTime,
+		}
+
+		if submission.MarkID != nil {
+			mark.ID = *submission.MarkID
+		} else {
+			v.Check(input.LessonID != nil, "lesson_id", "must be provided")
+			if input.LessonID != nil {
+				lesson, err := app.models.Lessons.GetLessonByID(*input.LessonID)
+				if err != nil {
+					switch {
+					case errors.Is(err, data.ErrNoSuchLesson):
+						app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+					default:
+						app.writeInternalServerError(w, r, err)
+					}
+					return
+				}
+
+				v.Check(lesson.Journal.ID == journal.ID, "lesson_id", "must be a lesson of the assignment's journal")
+
+				mark.UserID = submission.StudentID
+				mark.LessonID = &lesson.ID
+				mark.Course = lesson.Course
+				mark.JournalID = &journal.ID
+				mark.CreatedAt = &currentTime
+			}
+		}
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	tx, err := app.models.Marks.DB.Begin()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+	defer tx.Rollback()
+
+	if mark != nil {
+		if mark.ID != 0 {
+			err = app.models.Marks.UpdateMarks(tx, []*data.Mark{mark})
+		} else {
+			err = app.models.Marks.InsertMark(tx, mark)
+		}
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		submission.MarkID = &mark.ID
+	}
+
+	submission.Status = helpers.ToPtr(data.SubmissionReturned)
+	submission.Feedback = input.Feedback
+	submission.FeedbackBy = &sessionUser.ID
+	submission.FeedbackAt = &currentTime
+
+	err = app.models.Submissions.SetSubmissionFeedback(tx, &submission.Submission)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if mark != nil {
+		err = app.models.Journals.SetJournalLastUpdated(journal.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	result, err := app.models.Submissions.GetSubmissionByID(submission.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"submission": result})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
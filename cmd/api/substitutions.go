@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+// journal teachers, administrators and substitutes covering the lesson can teach it
+func (app *application) canTeachLesson(user *data.UserExt, journal *data.JournalExt, lessonID int) (bool, error) {
+	if *user.Role == data.RoleAdministrator || journal.IsUserTeacherOfJournal(user.ID) {
+		return true, nil
+	}
+
+	return app.models.Substitutions.IsUserSubstituteForLesson(user.ID, lessonID)
+}
+
+// reads 'from' and 'until' query params, defaulting to today and the given number of days after 'from'
+func readDateRange(r *http.Request, days int) (*types.Date, *types.Date, error) {
+	var from, until *types.Date
+	var err error
+
+	fromDate := r.URL.Query().Get("from")
+	if fromDate == "" {
+		from = &types.Date{Time: helpers.ToPtr(time.Now().UTC().Truncate(24 * time.Hour))}
+	} else {
+		from, err = types.ParseDate(fromDate)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	untilDate := r.URL.Query().Get("until")
+	if untilDate == "" {
+		until = &types.Date{Time: helpers.ToPtr(from.AddDate(0, 0, days))}
+	} else {
+		until, err = types.ParseDate(untilDate)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return from, until, nil
+}
+
+func (app *application) createSubstitution(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	var input struct {
+		JournalID       int        `json:"journal_id"`
+		LessonID        *int       `json:"lesson_id"`
+		SubstituteID    int        `json:"substitute_id"`
+		AbsentTeacherID *int       `json:"absent_teacher_id"`
+		StartDate       types.Date `json:"start_date"`
+		EndDate         types.Date `json:"end_date"`
+		Note            *string    `json:"note"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(input.JournalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	// substituting a single lesson covers only its date
+	if input.LessonID != nil {
+		lesson, err := app.models.Lessons.GetLessonByID(*input.LessonID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrNoSuchLesson):
+				app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+			default:
+				app.writeInternalServerError(w, r, err)
+			}
+			return
+		}
+
+		if lesson.Journal.ID != journal.ID {
+			app.writeErrorResponse(w, r, http.StatusBadRequest, data.ErrNoSuchLesson.Error())
+			return
+		}
+
+		input.StartDate = *lesson.Date
+		input.EndDate = *lesson.Date
+	}
+
+	if input.AbsentTeacherID == nil && len(journal.Teachers) == 1 {
+		input.AbsentTeacherID = &journal.Teachers[0].ID
+	}
+
+	substitute, err := app.models.Users.GetUserByID(input.SubstituteID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUser):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*substitute.Role == data.RoleTeacher || *substitute.Role == data.RoleAdministrator, "substitute_id", "must be a teacher")
+	v.Check(!journal.IsUserTeacherOfJournal(substitute.ID), "substitute_id", "is already a teacher of the journal")
+	v.Check(input.AbsentTeacherID == nil || journal.IsUserTeacherOfJournal(*input.AbsentTeacherID), "absent_teacher_id", "must be a teacher of the journal")
+	v.Check(input.StartDate.Time != nil, "start_date", "must be provided")
+	v.Check(input.EndDate.Time != nil, "end_date", "must be provided")
+	if input.StartDate.Time != nil && input.EndDate.Time != nil {
+		v.Check(!input.EndDate.Before(*input.StartDate.Time), "end_date", "must not be before start date")
+	}
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	if input.Note != nil && *input.Note == "" {
+		input.Note = nil
+	}
+
+	substitution := &data.Substitution{
+		JournalID:       &journal.ID,
+		LessonID:        input.LessonID,
+		SubstituteID:    &substitute.ID,
+		AbsentTeacherID: input.AbsentTeacherID,
+		StartDate:       &input.StartDate,
+		EndDate:         &input.EndDate,
+		Note:            input.Note,
+		CreatedBy:       &sessionUser.ID,
+		CreatedAt:       helpers.ToPtr(time.Now().UTC()),
+	}
+
+	err = app.models.Substitutions.InsertSubstitution(substitution)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"substitution": substitution})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteSubstitution(w http.ResponseWriter, r *http.Request) {
+	substitutionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if substitutionID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchSubstitution.Error())
+		return
+	}
+
+	substitution, err := app.models.Substitutions.GetSubstitutionByID(substitutionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchSubstitution):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.Substitutions.DeleteSubstitution(substitution.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getSubstitutionsForJournal(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	journalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if journalID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchJournal.Error())
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(journalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	substitutions, err := app.models.Substitutions.GetSubstitutionsForJournal(journal.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"substitutions": substitutions})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getSubstitutionsForTeacher(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	teacherID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if teacherID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	if sessionUser.ID != teacherID && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	from, until, err := readDateRange(r, 30)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	substitutions, err := app.models.Substitutions.GetSubstitutionsForTeacher(teacherID, from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"substitutions": substitutions})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getSubstitutionsForStudent(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if userID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	student, err := app.models.Users.GetStudentByID(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchUser):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if sessionUser.ID != student.ID && *sessionUser.Role != data.RoleAdministrator {
+		ok, err := app.models.Users.IsUserTeacherOrParentOfStudent(student.ID, sessionUser.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+	}
+
+	from, until, err := readDateRange(r, 14)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	substitutions, err := app.models.Substitutions.GetSubstitutionsForStudent(student.ID, from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"substitutions": substitutions})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getSubstitutionReport(w http.ResponseWriter, r *http.Request) {
+	from, until, err := readDateRange(r, 30)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if until.Before(*from.Time) {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, "until must not be before from")
+		return
+	}
+
+	report, err := app.models.Substitutions.GetSubstitutionReport(from, until)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"from": from, "until": until, "report": report})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,705 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+func (app *application) listAllRooms(w http.ResponseWriter, r *http.Request) {
+	rooms, err := app.models.Timetable.AllRooms()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"rooms": rooms})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) createRoom(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	room := &data.Room{
+		Name: &input.Name,
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*room.Name != "", "name", "must be provided")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.Timetable.InsertRoom(room)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRoomNameExists):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"room": room})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateRoom(w http.ResponseWriter, r *http.Request) {
+	roomID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if roomID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchRoom.Error())
+		return
+	}
+
+	room, err := app.models.Timetable.GetRoomByID(roomID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchRoom):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Name *string `json:"name"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.Name != nil {
+		room.Name = input.Name
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*room.Name != "", "name", "must be provided")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.Timetable.UpdateRoom(room)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRoomNameExists):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteRoom(w http.ResponseWriter, r *http.Request) {
+	roomID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if roomID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchRoom.Error())
+		return
+	}
+
+	room, err := app.models.Timetable.GetRoomByID(roomID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchRoom):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.Timetable.DeleteRoom(room.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) listAllPeriods(w http.ResponseWriter, r *http.Request) {
+	periods, err := app.models.Timetable.AllPeriods()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"periods": periods})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) createPeriod(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Number    int        `json:"number"`
+		StartTime types.Time `json:"start_time"`
+		EndTime   types.Time `json:"end_time"`
+	}
+
+	err := app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	period := &data.Period{
+		Number:    &input.Number,
+		StartTime: &input.StartTime,
+		EndTime:   &input.EndTime,
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*period.Number > 0, "number", "must be provided and valid")
+	v.Check(period.StartTime.Time != nil, "start_time", "must be provided")
+	v.Check(period.EndTime.Time != nil, "end_time", "must be provided")
+	v.Check(period.StartTime.Time == nil || period.EndTime.Time == nil || period.StartTime.Before(*period.EndTime.Time), "end_time", "must be after start time")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.Timetable.InsertPeriod(period)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrPeriodNumberExists):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"period": period})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) updatePeriod(w http.ResponseWriter, r *http.Request) {
+	periodID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if periodID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchPeriod.Error())
+		return
+	}
+
+	period, err := app.models.Timetable.GetPeriodByID(periodID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchPeriod):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Number    *int        `json:"number"`
+		StartTime *types.Time `json:"start_time"`
+		EndTime   *types.Time `json:"end_time"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.Number != nil {
+		period.Number = input.Number
+	}
+	if input.StartTime != nil && input.StartTime.Time != nil {
+		period.StartTime = input.StartTime
+	}
+	if input.EndTime != nil && input.EndTime.Time != nil {
+		period.EndTime = input.EndTime
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*period.Number > 0, "number", "must be provided and valid")
+	v.Check(period.StartTime.Before(*period.EndTime.Time), "end_time", "must be after start time")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.Timetable.UpdatePeriod(period)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrPeriodNumberExists):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deletePeriod(w http.ResponseWriter, r *http.Request) {
+	periodID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if periodID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchPeriod.Error())
+		return
+	}
+
+	period, err := app.models.Timetable.GetPeriodByID(periodID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchPeriod):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.Timetable.DeletePeriod(period.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getTimetableForJournal(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	journalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if journalID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchJournal.Error())
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(journalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	timetable, err := app.models.Timetable.GetTimetableForJournal(journal.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"timetable": timetable})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) createTimetableSlot(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	journalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if journalID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchJournal.Error())
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(journalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	var input struct {
+		Weekday   int  `json:"weekday"`
+		PeriodID  int  `json:"period_id"`
+		TeacherID *int `json:"teacher_id"`
+		RoomID    *int `json:"room_id"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.TeacherID == nil {
+		input.TeacherID = &sessionUser.ID
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(input.Weekday >= 1 && input.Weekday <= 7, "weekday", "must be between 1 (Monday) and 7 (Sunday)")
+	v.Check(input.PeriodID > 0, "period_id", "must be provided and valid")
+	v.Check(journal.IsUserTeacherOfJournal(*input.TeacherID), "teacher_id", "must be a teacher of the journal")
+	v.Check(input.RoomID == nil || *input.RoomID > 0, "room_id", "must be valid")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	_, err = app.models.Timetable.GetPeriodByID(input.PeriodID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchPeriod):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if input.RoomID != nil {
+		_, err = app.models.Timetable.GetRoomByID(*input.RoomID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrNoSuchRoom):
+				app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+			default:
+				app.writeInternalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	slot := &data.TimetableSlot{
+		JournalID: &journal.ID,
+		Weekday:   &input.Weekday,
+		PeriodID:  &input.PeriodID,
+		TeacherID: input.TeacherID,
+		RoomID:    input.RoomID,
+		YearID:    journal.YearID,
+	}
+
+	err = app.models.Timetable.InsertSlot(slot)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrJournalSlotTaken),
+			errors.Is(err, data.ErrTeacherSlotTaken),
+			errors.Is(err, data.ErrRoomSlotTaken):
+			app.writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusCreated, envelope{"slot": slot})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteTimetableSlot(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	slotID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if slotID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchTimetableSlot.Error())
+		return
+	}
+
+	slot, err := app.models.Timetable.GetSlotByID(slotID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchTimetableSlot):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(*slot.JournalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	err = app.models.Timetable.DeleteSlot(slot.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) generateLessonsForJournal(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	journalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if journalID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchJournal.Error())
+		return
+	}
+
+	journal, err := app.models.Journals.GetJournalByID(journalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchJournal):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if !journal.IsUserTeacherOfJournal(sessionUser.ID) && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	var input struct {
+		From      types.Date   `json:"from"`
+		Until     types.Date   `json:"until"`
+		Course    int          `json:"course"`
+		SkipDates []types.Date `json:"skip_dates"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(input.From.Time != nil, "from", "must be provided")
+	v.Check(input.Until.Time != nil, "until", "must be provided")
+	v.Check(input.Course > 0, "course", "must be provided and valid")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	v.Check(!input.Until.Before(*input.From.Time), "until", "must not be before from")
+	v.Check(!input.Until.After(input.From.AddDate(1, 0, 0)), "until", "must be at most a year after from")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	year, err := app.models.Years.GetYearByID(*journal.YearID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	// holidays and vacations never get lessons
+	skip, err := app.models.Calendar.GetDaysOff(*journal.YearID, *input.From.Time, *input.Until.Time)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	for _, d := range input.SkipDates {
+		if d.Time != nil {
+			skip = append(skip, *d.Time)
+		}
+	}
+
+	created, err := app.models.Timetable.GenerateLessonsForJournal(journal.ID, input.Course, &year.Year, *input.From.Time, *input.Until.Time, skip)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	if created > 0 {
+		err = app.models.Journals.SetJournalLastUpdated(journal.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"created": created})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getTimetableForTeacher(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	teacherID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if teacherID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchUser.Error())
+		return
+	}
+
+	if teacherID != sessionUser.ID && *sessionUser.Role != data.RoleAdministrator {
+		app.notAllowed(w, r)
+		return
+	}
+
+	year, err := app.models.Years.GetCurrentYear()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	} else if year == nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, data.ErrNoCurrentYear.Error())
+		return
+	}
+
+	timetable, err := app.models.Timetable.GetTimetableForTeacher(teacherID, year.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"timetable": timetable})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) getTimetableForClass(w http.ResponseWriter, r *http.Request) {
+	sessionUser := app.getUserFromContext(r)
+
+	classID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if classID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchClass.Error())
+		return
+	}
+
+	class, err := app.models.Classes.GetClassByID(classID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchClass):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	switch *sessionUser.Role {
+	case data.RoleAdministrator, data.RoleTeacher:
+	case data.RoleStudent:
+		if sessionUser.ClassID == nil || *sessionUser.ClassID != class.ID {
+			app.notAllowed(w, r)
+			return
+		}
+	case data.RoleParent:
+		children, err := app.models.Users.GetChildrenForParent(sessionUser.ID)
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+			return
+		}
+
+		var ok bool
+		for _, c := range children {
+			if c.ClassID != nil && *c.ClassID == class.ID {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			app.notAllowed(w, r)
+			return
+		}
+	default:
+		app.notAllowed(w, r)
+		return
+	}
+
+	year, err := app.models.Years.GetCurrentYear()
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	} else if year == nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, data.ErrNoCurrentYear.Error())
+		return
+	}
+
+	timetable, err := app.models.Timetable.GetTimetableForClass(class.ID, year.ID)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"timetable": timetable})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/annusingmar/lavurso-backend/internal/validator"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	WorkloadDay  = "day"
+	WorkloadWeek = "week"
+)
+
+type WorkloadViolation struct {
+	Class  *data.Class           `json:"class"`
+	Period string                `json:"period"`
+	Limit  int                   `json:"limit"`
+	Tests  []*data.AssignmentExt `json:"tests"`
+}
+
+// monday and sunday of the week the date falls in
+func weekBounds(d time.Time) (time.Time, time.Time) {
+	offset := (int(d.Weekday()) + 6) % 7
+	monday := d.AddDate(0, 0, -offset)
+	return monday, monday.AddDate(0, 0, 6)
+}
+
+// students an assignment with the given targets is for, no targets means the whole journal
+func (app *application) getTargetStudentIDs(journalID int, studentIDs, groupIDs []int) ([]int, error) {
+	if len(studentIDs) == 0 && len(groupIDs) == 0 {
+		return app.models.Journals.GetStudentIDsForJournal(journalID)
+	}
+
+	ids := slices.Clone(studentIDs)
+
+	if len(groupIDs) > 0 {
+		groups, err := app.models.JournalGroups.GetJournalGroupsForJournal(journalID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, g := range groups {
+			if !slices.Contains(groupIDs, g.ID) {
+				continue
+			}
+			for _, s := range g.Students {
+				if !slices.Contains(ids, s.ID) {
+					ids = append(ids, s.ID)
+				}
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// the class's own test limits, falling back to the workload config
+func (app *application) getWorkloadLimits(class *data.Class) (int, int) {
+	perDay, perWeek := app.config.Workload.MaxTestsPerDay, app.config.Workload.MaxTestsPerWeek
+	if class.MaxTestsPerDay != nil {
+		perDay = *class.MaxTestsPerDay
+	}
+	if class.MaxTestsPerWeek != nil {
+		perWeek = *class.MaxTestsPerWeek
+	}
+	return perDay, perWeek
+}
+
+// checks whether adding a test on the deadline breaks the workload policy for any class of the given students
+func (app *application) checkWorkload(assignmentID int, deadline *types.Date, studentIDs []int) ([]*WorkloadViolation, error) {
+	violations := []*WorkloadViolation{}
+
+	classIDs, err := app.models.Classes.GetClassIDsForStudents(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	monday, sunday := weekBounds(*deadline.Time)
+
+	for _, cid := range classIDs {
+		class, err := app.models.Classes.GetClassByID(cid)
+		if err != nil {
+			return nil, err
+		}
+
+		maxPerDay, maxPerWeek := app.getWorkloadLimits(&class.Class)
+		if maxPerDay <= 0 && maxPerWeek <= 0 {
+			continue
+		}
+
+		tests, err := app.models.Assignments.GetTestsForClass(cid, &types.Date{Time: &monday}, &types.Date{Time: &sunday})
+		if err != nil {
+			return nil, err
+		}
+
+		var weekTests, dayTests []*data.AssignmentExt
+		for _, t := range tests {
+			if t.ID == assignmentID {
+				continue
+			}
+			weekTests = append(weekTests, t)
+			if t.Deadline.Equal(*deadline.Time) {
+				dayTests = append(dayTests, t)
+			}
+		}
+
+		if maxPerDay > 0 && len(dayTests) >= maxPerDay {
+			violations = append(violations, &WorkloadViolation{Class: &class.Class, Period: WorkloadDay, Limit: maxPerDay, Tests: dayTests})
+		}
+		if maxPerWeek > 0 && len(weekTests) >= maxPerWeek {
+			violations = append(violations, &WorkloadViolation{Class: &class.Class, Period: WorkloadWeek, Limit: maxPerWeek, Tests: weekTests})
+		}
+	}
+
+	return violations, nil
+}
+
+// checks a test assignment against the workload policy, writing a conflict response if the policy is enforced and broken
+func (app *application) checkAssignmentWorkload(w http.ResponseWriter, r *http.Request, a *data.Assignment, studentIDs []int) ([]*WorkloadViolation, bool) {
+	violations := []*WorkloadViolation{}
+
+	if *a.Type != data.AssignmentTest || *a.Draft {
+		return violations, true
+	}
+
+	violations, err := app.checkWorkload(a.ID, a.Deadline, studentIDs)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return nil, false
+	}
+
+	if len(violations) > 0 && app.config.Workload.Enforce {
+		err = app.outputJSON(w, http.StatusConflict, envelope{"error": data.ErrWorkloadExceeded.Error(), "violations": violations})
+		if err != nil {
+			app.writeInternalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	return violations, true
+}
+
+func (app *application) getWorkloadForClass(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if classID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchClass.Error())
+		return
+	}
+
+	class, err := app.models.Classes.GetClassByID(classID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchClass):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	from, until, err := readDateRange(r, 27)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if until.Before(*from.Time) {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, "until must not be before from")
+		return
+	}
+
+	// whole weeks, so weekly totals are correct
+	monday, _ := weekBounds(*from.Time)
+	_, sunday := weekBounds(*until.Time)
+
+	tests, err := app.models.Assignments.GetTestsForClass(class.ID, &types.Date{Time: &monday}, &types.Date{Time: &sunday})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	type workloadDay struct {
+		Date  string                `json:"date"`
+		Tests []*data.AssignmentExt `json:"tests"`
+	}
+
+	type workloadWeek struct {
+		Start *types.Date    `json:"start"`
+		Tests int            `json:"tests"`
+		Days  []*workloadDay `json:"days"`
+	}
+
+	weeks := []*workloadWeek{}
+
+	for d := monday; !d.After(sunday); d = d.AddDate(0, 0, 7) {
+		week := &workloadWeek{Start: &types.Date{Time: helpers.ToPtr(d)}, Days: []*workloadDay{}}
+
+		for i := 0; i < 7; i++ {
+			day := d.AddDate(0, 0, i)
+			wd := &workloadDay{Date: day.Format("2006-01-02"), Tests: []*data.AssignmentExt{}}
+
+			for _, t := range tests {
+				if t.Deadline.Equal(day) {
+					wd.Tests = append(wd.Tests, t)
+				}
+			}
+
+			week.Tests += len(wd.Tests)
+			week.Days = append(week.Days, wd)
+		}
+
+		weeks = append(weeks, week)
+	}
+
+	maxPerDay, maxPerWeek := app.getWorkloadLimits(&class.Class)
+
+	err = app.outputJSON(w, http.StatusOK, envelope{
+		"class":              class.Class,
+		"max_tests_per_day":  maxPerDay,
+		"max_tests_per_week": maxPerWeek,
+		"weeks":              weeks,
+	})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
+
+func (app *application) setWorkloadForClass(w http.ResponseWriter, r *http.Request) {
+	classID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if classID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchClass.Error())
+		return
+	}
+
+	class, err := app.models.Classes.GetClassByID(classID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchClass):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	// a limit left out goes back to the one of the workload config, 0 means no limit
+	var input struct {
+		MaxTestsPerDay  *int `json:"max_tests_per_day"`
+		MaxTestsPerWeek *int `json:"max_tests_per_week"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v := validator.NewValidator()
+	v.Check(input.MaxTestsPerDay == nil || *input.MaxTestsPerDay >= 0, "max_tests_per_day", "must not be negative")
+	v.Check(input.MaxTestsPerWeek == nil || *input.MaxTestsPerWeek >= 0, "max_tests_per_week", "must not be negative")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	class.MaxTestsPerDay = input.MaxTestsPerDay
+	class.MaxTestsPerWeek = input.MaxTestsPerWeek
+
+	err = app.models.Classes.SetClassWorkloadLimits(&class.Class)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/annusingmar/lavurso-backend/internal/data"
 	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
 	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
 	"github.com/annusingmar/lavurso-backend/internal/validator"
 	"github.com/go-chi/chi/v5"
 	"golang.org/x/exp/slices"
@@ -200,7 +201,9 @@ func (app *application) setYearsForClass(w http.ResponseWriter, r *http.Request)
 
 func (app *application) newYear(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		DisplayName string `json:"display_name"`
+		DisplayName string      `json:"display_name"`
+		StartDate   *types.Date `json:"start_date"`
+		EndDate     *types.Date `json:"end_date"`
 		NewClasses  []struct {
 			Name        string `json:"name"`
 			DisplayName string `json:"display_name"`
@@ -221,6 +224,16 @@ func (app *application) newYear(w http.ResponseWriter, r *http.Request) {
 
 	v.Check(input.DisplayName != "", "display_name", "cannot be empty")
 
+	if input.StartDate != nil && input.StartDate.Time == nil {
+		input.StartDate = nil
+	}
+	if input.EndDate != nil && input.EndDate.Time == nil {
+		input.EndDate = nil
+	}
+
+	v.Check((input.StartDate == nil) == (input.EndDate == nil), "end_date", "start and end date must be provided together")
+	v.Check(input.StartDate == nil || input.EndDate == nil || !input.EndDate.Before(*input.StartDate.Time), "end_date", "must not be before start date")
+
 	var classIDs []int
 
 	for _, tc := range input.TransferredClasses {
@@ -257,6 +270,8 @@ func (app *application) newYear(w http.ResponseWriter, r *http.Request) {
 	year := data.Year{
 		DisplayName: &input.DisplayName,
 		Current:     helpers.ToPtr(false),
+		StartDate:   input.StartDate,
+		EndDate:     input.EndDate,
 	}
 
 	err = app.models.Years.InsertYear(&year)
@@ -324,3 +339,74 @@ func (app *application) newYear(w http.ResponseWriter, r *http.Request) {
 		app.writeInternalServerError(w, r, err)
 	}
 }
+
+func (app *application) updateYear(w http.ResponseWriter, r *http.Request) {
+	yearID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if yearID < 0 || err != nil {
+		app.writeErrorResponse(w, r, http.StatusNotFound, data.ErrNoSuchYear.Error())
+		return
+	}
+
+	year, err := app.models.Years.GetYearByID(yearID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrNoSuchYear):
+			app.writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+		default:
+			app.writeInternalServerError(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		DisplayName *string     `json:"display_name"`
+		StartDate   *types.Date `json:"start_date"`
+		EndDate     *types.Date `json:"end_date"`
+	}
+
+	err = app.inputJSON(w, r, &input)
+	if err != nil {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if input.DisplayName != nil {
+		year.DisplayName = input.DisplayName
+	}
+	if input.StartDate != nil {
+		if input.StartDate.Time == nil {
+			year.StartDate = nil
+		} else {
+			year.StartDate = input.StartDate
+		}
+	}
+	if input.EndDate != nil {
+		if input.EndDate.Time == nil {
+			year.EndDate = nil
+		} else {
+			year.EndDate = input.EndDate
+		}
+	}
+
+	v := validator.NewValidator()
+
+	v.Check(*year.DisplayName != "", "display_name", "cannot be empty")
+	v.Check((year.StartDate == nil) == (year.EndDate == nil), "end_date", "start and end date must be provided together")
+	v.Check(year.StartDate == nil || year.EndDate == nil || !year.EndDate.Before(*year.StartDate.Time), "end_date", "must not be before start date")
+
+	if !v.Valid() {
+		app.writeErrorResponse(w, r, http.StatusBadRequest, v.Errors)
+		return
+	}
+
+	err = app.models.Years.UpdateYear(&year.Year)
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+		return
+	}
+
+	err = app.outputJSON(w, http.StatusOK, envelope{"message": "success"})
+	if err != nil {
+		app.writeInternalServerError(w, r, err)
+	}
+}
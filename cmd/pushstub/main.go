@@ -0,0 +1,43 @@
+// pushstub is a stand-in push service for trying out Web Push locally.
+// Subscribe with an endpoint like http://127.0.0.1:8090/<anything> and set push.allow_http,
+// every push is logged and accepted, endpoints ending in /gone respond as expired subscriptions.
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8090", "address to listen on")
+	flag.Parse()
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, 8192))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("%s: %d bytes, encoding %q, ttl %s, authorization %q",
+			r.URL.Path, len(body), r.Header.Get("Content-Encoding"), r.Header.Get("TTL"), r.Header.Get("Authorization"))
+
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	log.Printf("push service stub listening on %s", *listen)
+	log.Fatalln(http.ListenAndServe(*listen, nil))
+}
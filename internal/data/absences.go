@@ -9,20 +9,47 @@ import (
 	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
 	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
 	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
 )
 
 var (
-	ErrNotValidAbsence = errors.New("not valid absence for user")
-	ErrNoSuchAbsence   = errors.New("no such absence")
-	ErrAbsenceExcused  = errors.New("absence already excused")
-	ErrNoSuchExcuse    = errors.New("no such excuse")
+	ErrNotValidAbsence       = errors.New("not valid absence for user")
+	ErrNoSuchAbsence         = errors.New("no such absence")
+	ErrAbsenceExcused        = errors.New("absence already excused")
+	ErrNoSuchExcuse          = errors.New("no such excuse")
+	ErrExcuseAlreadyReviewed = errors.New("excuse already reviewed")
+	ErrNoSuchExcuseStatus    = errors.New("no such excuse status")
+	ErrNoSuchAbsenceNotice   = errors.New("no such absence notice")
+)
+
+const (
+	ExcusePending  = "pending"
+	ExcuseAccepted = "accepted"
+	ExcuseRejected = "rejected"
 )
 
 type Excuse = model.Excuses
 
 type ExcuseExt struct {
 	Excuse
-	By *User `json:"by,omitempty" alias:"excuser"`
+	By       *User `json:"by,omitempty" alias:"excuser"`
+	Reviewer *User `json:"reviewer,omitempty" alias:"reviewer"`
+}
+
+type AbsenceNotice = model.AbsenceNotices
+
+type AbsenceNoticeExt struct {
+	AbsenceNotice
+	By *User `json:"by,omitempty" alias:"announcer"`
+}
+
+type Attendance struct {
+	Absences  int `json:"absences"`
+	Excused   int `json:"excused"`
+	Pending   int `json:"pending"`
+	Unexcused int `json:"unexcused"`
+	Late      int `json:"late"`
 }
 
 type AbsenceModel struct {
@@ -31,7 +58,17 @@ type AbsenceModel struct {
 
 func (m AbsenceModel) InsertExcuse(excuse *Excuse) error {
 	stmt := table.Excuses.INSERT(table.Excuses.AllColumns).
-		MODEL(excuse)
+		MODEL(excuse).
+		ON_CONFLICT(table.Excuses.MarkID).
+		DO_UPDATE(postgres.SET(
+			table.Excuses.Excuse.SET(table.Excuses.EXCLUDED.Excuse),
+			table.Excuses.UserID.SET(table.Excuses.EXCLUDED.UserID),
+			table.Excuses.At.SET(table.Excuses.EXCLUDED.At),
+			table.Excuses.Status.SET(table.Excuses.EXCLUDED.Status),
+			table.Excuses.ReviewerID.SET(table.Excuses.EXCLUDED.ReviewerID),
+			table.Excuses.ReviewComment.SET(table.Excuses.EXCLUDED.ReviewComment),
+			table.Excuses.ReviewedAt.SET(table.Excuses.EXCLUDED.ReviewedAt),
+		))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -44,6 +81,37 @@ func (m AbsenceModel) InsertExcuse(excuse *Excuse) error {
 	return nil
 }
 
+func (m AbsenceModel) ReviewExcuse(markID, reviewerID int, status string, comment *string) error {
+	stmt := table.Excuses.UPDATE(table.Excuses.Status, table.Excuses.ReviewerID, table.Excuses.ReviewComment, table.Excuses.ReviewedAt).
+		MODEL(Excuse{
+			Status:        &status,
+			ReviewerID:    &reviewerID,
+			ReviewComment: comment,
+			ReviewedAt:    helpers.ToPtr(time.Now().UTC()),
+		}).
+		WHERE(table.Excuses.MarkID.EQ(helpers.PostgresInt(markID)).
+			AND(table.Excuses.Status.EQ(postgres.String(ExcusePending))))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrExcuseAlreadyReviewed
+	}
+
+	return nil
+}
+
 func (m AbsenceModel) DeleteExcuseByMarkID(markID int) error {
 	stmt := table.Excuses.DELETE().
 		WHERE(table.Excuses.MarkID.EQ(helpers.PostgresInt(markID)))
@@ -58,3 +126,189 @@ func (m AbsenceModel) DeleteExcuseByMarkID(markID int) error {
 
 	return nil
 }
+
+func (m AbsenceModel) GetExcusedAbsencesForClassTeacher(teacherID int, status string) ([]*MarkExt, error) {
+	student := table.Users.AS("student")
+	excuser := table.Users.AS("excuser")
+	reviewer := table.Users.AS("reviewer")
+	lesson := table.Lessons.AS("mark_lesson")
+
+	query := postgres.SELECT(
+		table.Marks.AllColumns,
+		lesson.ID, lesson.Date, lesson.Description,
+		table.Subjects.ID, table.Subjects.Name,
+		student.ID, student.Name, student.Role,
+		table.Excuses.AllColumns,
+		excuser.ID, excuser.Name, excuser.Role,
+		reviewer.ID, reviewer.Name, reviewer.Role,
+	).FROM(table.Marks.
+		INNER_JOIN(table.Excuses, table.Excuses.MarkID.EQ(table.Marks.ID)).
+		INNER_JOIN(student, student.ID.EQ(table.Marks.UserID)).
+		INNER_JOIN(table.TeachersClasses, table.TeachersClasses.ClassID.EQ(student.ClassID)).
+		INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.Marks.JournalID)).
+		INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)).
+		LEFT_JOIN(lesson, lesson.ID.EQ(table.Marks.LessonID)).
+		INNER_JOIN(excuser, excuser.ID.EQ(table.Excuses.UserID)).
+		LEFT_JOIN(reviewer, reviewer.ID.EQ(table.Excuses.ReviewerID))).
+		WHERE(postgres.AND(
+			table.TeachersClasses.TeacherID.EQ(helpers.PostgresInt(teacherID)),
+			table.Excuses.Status.EQ(postgres.String(status)),
+		)).
+		ORDER_BY(table.Excuses.At.ASC())
+
+	var marks []*MarkExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &marks)
+	if err != nil {
+		return nil, err
+	}
+
+	return marks, nil
+}
+
+func (m AbsenceModel) GetAttendanceForStudent(studentID, yearID int) (*Attendance, error) {
+	absent := table.Marks.Type.EQ(postgres.String(MarkAbsent))
+
+	countWhen := func(cond postgres.BoolExpression) postgres.Expression {
+		return postgres.COUNT(postgres.CASE().WHEN(cond).THEN(postgres.Int32(1)))
+	}
+
+	query := postgres.SELECT(
+		countWhen(absent).AS("attendance.absences"),
+		countWhen(absent.AND(table.Excuses.Status.EQ(postgres.String(ExcuseAccepted)))).AS("attendance.excused"),
+		countWhen(absent.AND(table.Excuses.Status.EQ(postgres.String(ExcusePending)))).AS("attendance.pending"),
+		countWhen(table.Marks.Type.EQ(postgres.String(MarkLate))).AS("attendance.late"),
+	).FROM(table.Marks.
+		INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.Marks.JournalID)).
+		LEFT_JOIN(table.Excuses, table.Excuses.MarkID.EQ(table.Marks.ID))).
+		WHERE(table.Marks.UserID.EQ(helpers.PostgresInt(studentID)).
+			AND(table.Journals.YearID.EQ(helpers.PostgresInt(yearID))))
+
+	var attendance Attendance
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &attendance)
+	if err != nil {
+		return nil, err
+	}
+
+	// absences with no excuse or a rejected one, pending excuses are counted on their own
+	attendance.Unexcused = attendance.Absences - attendance.Excused - attendance.Pending
+
+	return &attendance, nil
+}
+
+func (m AbsenceModel) GetAbsenceNoticeByID(noticeID int) (*AbsenceNotice, error) {
+	query := postgres.SELECT(table.AbsenceNotices.AllColumns).
+		FROM(table.AbsenceNotices).
+		WHERE(table.AbsenceNotices.ID.EQ(helpers.PostgresInt(noticeID)))
+
+	var notice AbsenceNotice
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &notice)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchAbsenceNotice
+		default:
+			return nil, err
+		}
+	}
+
+	return &notice, nil
+}
+
+func (m AbsenceModel) GetAbsenceNoticesForStudent(studentID int) ([]*AbsenceNoticeExt, error) {
+	announcer := table.Users.AS("announcer")
+
+	query := postgres.SELECT(table.AbsenceNotices.AllColumns, announcer.ID, announcer.Name, announcer.Role).
+		FROM(table.AbsenceNotices.
+			INNER_JOIN(announcer, announcer.ID.EQ(table.AbsenceNotices.UserID))).
+		WHERE(table.AbsenceNotices.StudentID.EQ(helpers.PostgresInt(studentID))).
+		ORDER_BY(table.AbsenceNotices.StartDate.DESC())
+
+	var notices []*AbsenceNoticeExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &notices)
+	if err != nil {
+		return nil, err
+	}
+
+	return notices, nil
+}
+
+func (m AbsenceModel) InsertAbsenceNotice(n *AbsenceNotice) error {
+	stmt := table.AbsenceNotices.INSERT(table.AbsenceNotices.MutableColumns).
+		MODEL(n).
+		RETURNING(table.AbsenceNotices.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, n)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m AbsenceModel) DeleteAbsenceNotice(noticeID int) error {
+	stmt := table.AbsenceNotices.DELETE().
+		WHERE(table.AbsenceNotices.ID.EQ(helpers.PostgresInt(noticeID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m AbsenceModel) ExcuseLessonAbsencesFromNotices(tx *sql.Tx, lessonID, teacherID int) error {
+	currentTime := time.Now().UTC()
+
+	stmt := table.Excuses.INSERT(
+		table.Excuses.MarkID, table.Excuses.Excuse, table.Excuses.UserID, table.Excuses.At,
+		table.Excuses.Status, table.Excuses.ReviewerID, table.Excuses.ReviewedAt,
+	).QUERY(
+		postgres.SELECT(
+			table.Marks.ID, table.AbsenceNotices.Reason, table.AbsenceNotices.UserID, postgres.TimestampzT(currentTime),
+			postgres.String(ExcuseAccepted), helpers.PostgresInt(teacherID), postgres.TimestampzT(currentTime),
+		).DISTINCT(table.Marks.ID).
+			FROM(table.Marks.
+				INNER_JOIN(table.Lessons, table.Lessons.ID.EQ(table.Marks.LessonID)).
+				INNER_JOIN(table.AbsenceNotices, postgres.AND(
+					table.AbsenceNotices.StudentID.EQ(table.Marks.UserID),
+					table.AbsenceNotices.StartDate.LT_EQ(table.Lessons.Date),
+					table.AbsenceNotices.EndDate.GT_EQ(table.Lessons.Date),
+				))).
+			WHERE(table.Marks.LessonID.EQ(helpers.PostgresInt(lessonID)).
+				AND(table.Marks.Type.EQ(postgres.String(MarkAbsent)))).
+			ORDER_BY(table.Marks.ID, table.AbsenceNotices.CreatedAt.ASC()),
+	).ON_CONFLICT(table.Excuses.MarkID).DO_NOTHING()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,384 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+)
+
+var (
+	ErrNoSuchAnnouncement      = errors.New("no such announcement")
+	ErrAcknowledgementRequired = errors.New("announcement does not require acknowledgement")
+)
+
+const (
+	AudienceEveryone     = "everyone"
+	AudienceRole         = "role"
+	AudienceClass        = "class"
+	AudienceGroup        = "group"
+	AudienceClassParents = "class_parents"
+)
+
+type Announcement = model.Announcements
+
+type AnnouncementAudience = model.AnnouncementsAudiences
+
+type AnnouncementExt struct {
+	Announcement
+	Author         *User                   `json:"author,omitempty" alias:"author"`
+	Audiences      []*AnnouncementAudience `json:"audiences,omitempty"`
+	ReadAt         *time.Time              `json:"read_at,omitempty" alias:"announcements_read.read_at"`
+	AcknowledgedAt *time.Time              `json:"acknowledged_at,omitempty" alias:"announcements_read.acknowledged_at"`
+}
+
+// an audience member and whether they have read and acknowledged the announcement
+type AnnouncementReceipt struct {
+	User
+	ReadAt         *time.Time `json:"read_at" alias:"announcements_read.read_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at" alias:"announcements_read.acknowledged_at"`
+}
+
+type AnnouncementModel struct {
+	DB *sql.DB
+}
+
+// whether the member is in any of the announcement's audiences
+func announcementAudienceIncludes(announcementID postgres.IntegerExpression, member *table.UsersTable) postgres.BoolExpression {
+	audience := table.AnnouncementsAudiences.AS("audience_match")
+	child := table.Users.AS("audience_child")
+
+	return postgres.EXISTS(
+		postgres.SELECT(postgres.Int32(1)).
+			FROM(audience).
+			WHERE(audience.AnnouncementID.EQ(announcementID).
+				AND(postgres.OR(
+					audience.Type.EQ(postgres.String(AudienceEveryone)),
+					audience.Type.EQ(postgres.String(AudienceRole)).
+						AND(audience.Role.EQ(member.Role)),
+					audience.Type.EQ(postgres.String(AudienceClass)).
+						AND(audience.ClassID.EQ(member.ClassID)).
+						AND(member.Role.EQ(postgres.String(RoleStudent))),
+					audience.Type.EQ(postgres.String(AudienceGroup)).
+						AND(postgres.EXISTS(
+							postgres.SELECT(postgres.Int32(1)).
+								FROM(table.UsersGroups).
+								WHERE(table.UsersGroups.GroupID.EQ(audience.GroupID).
+									AND(table.UsersGroups.UserID.EQ(member.ID))),
+						)),
+					audience.Type.EQ(postgres.String(AudienceClassParents)).
+						AND(postgres.EXISTS(
+							postgres.SELECT(postgres.Int32(1)).
+								FROM(table.ParentsChildren.
+									INNER_JOIN(child, child.ID.EQ(table.ParentsChildren.ChildID))).
+								WHERE(table.ParentsChildren.ParentID.EQ(member.ID).
+									AND(child.ClassID.EQ(audience.ClassID))),
+						)),
+				))),
+	)
+}
+
+// published and not yet expired
+func announcementIsActive() postgres.BoolExpression {
+	now := postgres.TimestampzT(time.Now().UTC())
+
+	return table.Announcements.PublishAt.LT_EQ(now).
+		AND(table.Announcements.ExpiresAt.IS_NULL().OR(table.Announcements.ExpiresAt.GT(now)))
+}
+
+func (m AnnouncementModel) GetAnnouncementByID(announcementID int) (*AnnouncementExt, error) {
+	author := table.Users.AS("author")
+
+	query := postgres.SELECT(table.Announcements.AllColumns, author.ID, author.Name, author.Role, table.AnnouncementsAudiences.AllColumns).
+		FROM(table.Announcements.
+			LEFT_JOIN(author, author.ID.EQ(table.Announcements.UserID)).
+			LEFT_JOIN(table.AnnouncementsAudiences, table.AnnouncementsAudiences.AnnouncementID.EQ(table.Announcements.ID))).
+		WHERE(table.Announcements.ID.EQ(helpers.PostgresInt(announcementID)))
+
+	var announcement AnnouncementExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &announcement)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchAnnouncement
+		default:
+			return nil, err
+		}
+	}
+
+	return &announcement, nil
+}
+
+// all announcements, or only the ones made by the given author
+func (m AnnouncementModel) GetAnnouncements(authorID *int) ([]*AnnouncementExt, error) {
+	author := table.Users.AS("author")
+
+	query := postgres.SELECT(table.Announcements.AllColumns, author.ID, author.Name, author.Role, table.AnnouncementsAudiences.AllColumns).
+		FROM(table.Announcements.
+			LEFT_JOIN(author, author.ID.EQ(table.Announcements.UserID)).
+			LEFT_JOIN(table.AnnouncementsAudiences, table.AnnouncementsAudiences.AnnouncementID.EQ(table.Announcements.ID))).
+		ORDER_BY(table.Announcements.PublishAt.DESC())
+
+	if authorID != nil {
+		query = query.WHERE(table.Announcements.UserID.EQ(helpers.PostgresInt(*authorID)))
+	}
+
+	var announcements []*AnnouncementExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &announcements)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcements, nil
+}
+
+// active announcements the user is in the audience of
+func (m AnnouncementModel) GetAnnouncementsForUser(userID int, unreadOnly bool) ([]*AnnouncementExt, error) {
+	uid := helpers.PostgresInt(userID)
+	author := table.Users.AS("author")
+	member := table.Users.AS("member")
+
+	where := announcementIsActive().AND(announcementAudienceIncludes(table.Announcements.ID, member))
+	if unreadOnly {
+		where = where.AND(table.AnnouncementsRead.ReadAt.IS_NULL())
+	}
+
+	query := postgres.SELECT(table.Announcements.AllColumns, author.ID, author.Name, author.Role,
+		table.AnnouncementsRead.ReadAt, table.AnnouncementsRead.AcknowledgedAt).
+		FROM(table.Announcements.
+			INNER_JOIN(member, member.ID.EQ(uid)).
+			LEFT_JOIN(author, author.ID.EQ(table.Announcements.UserID)).
+			LEFT_JOIN(table.AnnouncementsRead, table.AnnouncementsRead.AnnouncementID.EQ(table.Announcements.ID).
+				AND(table.AnnouncementsRead.UserID.EQ(uid)))).
+		WHERE(where).
+		ORDER_BY(table.Announcements.PublishAt.DESC())
+
+	var announcements []*AnnouncementExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &announcements)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcements, nil
+}
+
+func (m AnnouncementModel) CountUnreadAnnouncementsForUser(userID int) (int, error) {
+	uid := helpers.PostgresInt(userID)
+	member := table.Users.AS("member")
+
+	query := postgres.SELECT(postgres.COUNT(table.Announcements.ID)).
+		FROM(table.Announcements.
+			INNER_JOIN(member, member.ID.EQ(uid)).
+			LEFT_JOIN(table.AnnouncementsRead, table.AnnouncementsRead.AnnouncementID.EQ(table.Announcements.ID).
+				AND(table.AnnouncementsRead.UserID.EQ(uid)))).
+		WHERE(postgres.AND(
+			announcementIsActive(),
+			announcementAudienceIncludes(table.Announcements.ID, member),
+			table.AnnouncementsRead.ReadAt.IS_NULL(),
+		))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result[0], nil
+}
+
+// whether the announcement is active and the user is in its audience
+func (m AnnouncementModel) IsAnnouncementForUser(announcementID, userID int) (bool, error) {
+	member := table.Users.AS("member")
+
+	query := postgres.SELECT(postgres.COUNT(postgres.Int32(1))).
+		FROM(table.Announcements.
+			INNER_JOIN(member, member.ID.EQ(helpers.PostgresInt(userID)))).
+		WHERE(postgres.AND(
+			table.Announcements.ID.EQ(helpers.PostgresInt(announcementID)),
+			announcementIsActive(),
+			announcementAudienceIncludes(table.Announcements.ID, member),
+		))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result[0] > 0, nil
+}
+
+func (m AnnouncementModel) InsertAnnouncement(tx *sql.Tx, a *Announcement) error {
+	stmt := table.Announcements.INSERT(table.Announcements.MutableColumns).
+		MODEL(a).
+		RETURNING(table.Announcements.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, tx, a)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m AnnouncementModel) UpdateAnnouncement(tx *sql.Tx, a *Announcement) error {
+	stmt := table.Announcements.UPDATE(table.Announcements.Title, table.Announcements.Body, table.Announcements.PublishAt,
+		table.Announcements.ExpiresAt, table.Announcements.RequiresAck, table.Announcements.UpdatedAt).
+		MODEL(a).
+		WHERE(table.Announcements.ID.EQ(helpers.PostgresInt(a.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m AnnouncementModel) DeleteAnnouncement(announcementID int) error {
+	stmt := table.Announcements.DELETE().
+		WHERE(table.Announcements.ID.EQ(helpers.PostgresInt(announcementID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// replaces the audiences of the announcement
+func (m AnnouncementModel) SetAnnouncementAudiences(tx *sql.Tx, announcementID int, audiences []*AnnouncementAudience) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := table.AnnouncementsAudiences.DELETE().
+		WHERE(table.AnnouncementsAudiences.AnnouncementID.EQ(helpers.PostgresInt(announcementID))).
+		ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range audiences {
+		a.AnnouncementID = &announcementID
+	}
+
+	_, err = table.AnnouncementsAudiences.INSERT(table.AnnouncementsAudiences.MutableColumns).
+		MODELS(audiences).
+		ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m AnnouncementModel) SetAnnouncementRead(announcementID, userID int) error {
+	stmt := table.AnnouncementsRead.INSERT(table.AnnouncementsRead.AnnouncementID, table.AnnouncementsRead.UserID, table.AnnouncementsRead.ReadAt).
+		MODEL(model.AnnouncementsRead{
+			AnnouncementID: &announcementID,
+			UserID:         &userID,
+			ReadAt:         helpers.ToPtr(time.Now().UTC()),
+		}).
+		ON_CONFLICT(table.AnnouncementsRead.AnnouncementID, table.AnnouncementsRead.UserID).DO_NOTHING()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// acknowledging also marks the announcement as read
+func (m AnnouncementModel) SetAnnouncementAcknowledged(announcementID, userID int) error {
+	currentTime := time.Now().UTC()
+
+	stmt := table.AnnouncementsRead.INSERT(table.AnnouncementsRead.AllColumns).
+		MODEL(model.AnnouncementsRead{
+			AnnouncementID: &announcementID,
+			UserID:         &userID,
+			ReadAt:         &currentTime,
+			AcknowledgedAt: &currentTime,
+		}).
+		ON_CONFLICT(table.AnnouncementsRead.AnnouncementID, table.AnnouncementsRead.UserID).
+		DO_UPDATE(postgres.SET(
+			table.AnnouncementsRead.AcknowledgedAt.SET(table.AnnouncementsRead.EXCLUDED.AcknowledgedAt),
+		).WHERE(table.AnnouncementsRead.AcknowledgedAt.IS_NULL()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// everyone in the announcement's audience, with when they read and acknowledged it
+func (m AnnouncementModel) GetAnnouncementReceipts(announcementID int) ([]*AnnouncementReceipt, error) {
+	aid := helpers.PostgresInt(announcementID)
+
+	query := postgres.SELECT(table.Users.ID, table.Users.Name, table.Users.Role,
+		table.AnnouncementsRead.ReadAt, table.AnnouncementsRead.AcknowledgedAt).
+		FROM(table.Users.
+			LEFT_JOIN(table.AnnouncementsRead, table.AnnouncementsRead.UserID.EQ(table.Users.ID).
+				AND(table.AnnouncementsRead.AnnouncementID.EQ(aid)))).
+		WHERE(postgres.AND(
+			table.Users.Archived.IS_FALSE(),
+			announcementAudienceIncludes(aid, table.Users),
+		)).
+		ORDER_BY(table.Users.Name.ASC())
+
+	var receipts []*AnnouncementReceipt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &receipts)
+	if err != nil {
+		return nil, err
+	}
+
+	return receipts, nil
+}
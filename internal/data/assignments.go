@@ -22,14 +22,68 @@ const (
 var (
 	ErrNoSuchAssignment     = errors.New("no such assignment")
 	ErrNoSuchAssignmentType = errors.New("no such assignment type")
+	ErrWorkloadExceeded     = errors.New("test workload limit exceeded")
 )
 
 type Assignment = model.Assignments
 
 type AssignmentExt struct {
 	Assignment
-	Done    *bool    `json:"done,omitempty" alias:"assignment.done"`
+	Done           *bool           `json:"done,omitempty" alias:"assignment.done"`
+	Subject        *Subject        `json:"subject,omitempty"`
+	TargetStudents []*User         `json:"target_students,omitempty" alias:"target_students"`
+	TargetGroups   []*JournalGroup `json:"target_groups,omitempty"`
+}
+
+type AssignmentReminder struct {
+	Assignment
+	Subject  *Subject `json:"subject,omitempty"`
+	Students []*User  `json:"students,omitempty" alias:"reminder_students"`
+}
+
+type CompletionStudent struct {
+	User
+	Done bool `json:"done" alias:"completion_student.done"`
+}
+
+type CompletionRate struct {
+	Assigned int     `json:"assigned" alias:"completion_rate.assigned"`
+	Done     int     `json:"done" alias:"completion_rate.done"`
+	Rate     float64 `json:"rate"`
+}
+
+type AssignmentCompletionRate struct {
+	Assignment
+	CompletionRate
+}
+
+type StudentCompletionRate struct {
+	User
+	CompletionRate
+}
+
+// completion over the assignments with the deadline in the week starting on Start
+type WeekCompletionRate struct {
+	Start *types.Date `json:"start"`
+	CompletionRate
+}
+
+type JournalCompletionRate struct {
+	Journal
 	Subject *Subject `json:"subject,omitempty"`
+	CompletionRate
+}
+
+func (c *CompletionRate) Add(o CompletionRate) {
+	c.Assigned += o.Assigned
+	c.Done += o.Done
+	c.setRate()
+}
+
+func (c *CompletionRate) setRate() {
+	if c.Assigned > 0 {
+		c.Rate = float64(c.Done) / float64(c.Assigned)
+	}
 }
 
 type AssignmentModel struct {
@@ -60,7 +114,7 @@ func (m AssignmentModel) GetAssignmentByID(assignmentID int) (*AssignmentExt, er
 	return &assignment, nil
 }
 
-func (m AssignmentModel) InsertAssignment(a *AssignmentExt) error {
+func (m AssignmentModel) InsertAssignment(tx *sql.Tx, a *AssignmentExt) error {
 	stmt := table.Assignments.INSERT(table.Assignments.MutableColumns).
 		MODEL(a).
 		RETURNING(table.Assignments.ID)
@@ -68,7 +122,7 @@ func (m AssignmentModel) InsertAssignment(a *AssignmentExt) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := stmt.QueryContext(ctx, m.DB, a)
+	err := stmt.QueryContext(ctx, tx, a)
 	if err != nil {
 		return err
 	}
@@ -76,15 +130,15 @@ func (m AssignmentModel) InsertAssignment(a *AssignmentExt) error {
 	return nil
 }
 
-func (m AssignmentModel) UpdateAssignment(a *AssignmentExt) error {
-	stmt := table.Assignments.UPDATE(table.Assignments.Description, table.Assignments.Deadline, table.Assignments.Type, table.Assignments.UpdatedAt).
+func (m AssignmentModel) UpdateAssignment(tx *sql.Tx, a *AssignmentExt) error {
+	stmt := table.Assignments.UPDATE(table.Assignments.Description, table.Assignments.Deadline, table.Assignments.Type, table.Assignments.UpdatedAt, table.Assignments.Draft, table.Assignments.PublishAt).
 		MODEL(a).
 		WHERE(table.Assignments.ID.EQ(helpers.PostgresInt(a.ID)))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := stmt.ExecContext(ctx, m.DB)
+	_, err := stmt.ExecContext(ctx, tx)
 	if err != nil {
 		return err
 	}
@@ -106,13 +160,46 @@ func (m AssignmentModel) DeleteAssignment(assignmentID int) error {
 	return nil
 }
 
-func (m AssignmentModel) GetAssignmentsByJournalID(journalID int) ([]*Assignment, error) {
-	query := postgres.SELECT(table.Assignments.AllColumns).
-		FROM(table.Assignments).
+// assignments without any targets are for everyone in the journal
+func assignmentTargetsStudent(studentID postgres.IntegerExpression) postgres.BoolExpression {
+	return postgres.OR(
+		postgres.NOT(postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.AssignmentsStudents).
+				WHERE(table.AssignmentsStudents.AssignmentID.EQ(table.Assignments.ID)))).
+			AND(postgres.NOT(postgres.EXISTS(
+				postgres.SELECT(postgres.Int32(1)).
+					FROM(table.AssignmentsGroups).
+					WHERE(table.AssignmentsGroups.AssignmentID.EQ(table.Assignments.ID))))),
+		postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.AssignmentsStudents).
+				WHERE(table.AssignmentsStudents.AssignmentID.EQ(table.Assignments.ID).
+					AND(table.AssignmentsStudents.StudentID.EQ(studentID)))),
+		postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.AssignmentsGroups.
+					INNER_JOIN(table.JournalGroupsStudents, table.JournalGroupsStudents.GroupID.EQ(table.AssignmentsGroups.GroupID))).
+				WHERE(table.AssignmentsGroups.AssignmentID.EQ(table.Assignments.ID).
+					AND(table.JournalGroupsStudents.StudentID.EQ(studentID)))),
+	)
+}
+
+func (m AssignmentModel) GetAssignmentsByJournalID(journalID int) ([]*AssignmentExt, error) {
+	targetStudent := table.Users.AS("target_students")
+
+	query := postgres.SELECT(table.Assignments.AllColumns,
+		targetStudent.ID, targetStudent.Name, targetStudent.Role,
+		table.JournalGroups.ID, table.JournalGroups.Name).
+		FROM(table.Assignments.
+			LEFT_JOIN(table.AssignmentsStudents, table.AssignmentsStudents.AssignmentID.EQ(table.Assignments.ID)).
+			LEFT_JOIN(targetStudent, targetStudent.ID.EQ(table.AssignmentsStudents.StudentID)).
+			LEFT_JOIN(table.AssignmentsGroups, table.AssignmentsGroups.AssignmentID.EQ(table.Assignments.ID)).
+			LEFT_JOIN(table.JournalGroups, table.JournalGroups.ID.EQ(table.AssignmentsGroups.GroupID))).
 		WHERE(table.Assignments.JournalID.EQ(helpers.PostgresInt(journalID))).
-		ORDER_BY(table.Assignments.Deadline.DESC())
+		ORDER_BY(table.Assignments.Deadline.DESC(), targetStudent.Name.ASC(), table.JournalGroups.Name.ASC())
 
-	var assignments []*Assignment
+	var assignments []*AssignmentExt
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -134,17 +221,21 @@ func (m AssignmentModel) GetAssignmentsForStudent(studentID int, from, until *ty
 			AS("assignment.done")).
 		FROM(table.Assignments.
 			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Assignments.JournalID).
-				AND(table.StudentsJournals.StudentID.EQ(helpers.PostgresInt(studentID)))).
+				AND(table.StudentsJournals.StudentID.EQ(helpers.PostgresInt(studentID))).
+				AND(assignmentTargetsStudent(table.StudentsJournals.StudentID))).
 			INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.Assignments.JournalID)).
 			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)).
 			LEFT_JOIN(table.DoneAssignments, table.DoneAssignments.AssignmentID.EQ(table.Assignments.ID).
 				AND(table.DoneAssignments.UserID.EQ(table.StudentsJournals.StudentID))))
 
+	// students don't see drafts and assignments waiting to be published
 	if until != nil {
 		query = query.WHERE(table.Assignments.Deadline.GT_EQ(postgres.DateT(*from.Time)).
-			AND(table.Assignments.Deadline.LT(postgres.DateT(*until.Time))))
+			AND(table.Assignments.Deadline.LT(postgres.DateT(*until.Time))).
+			AND(table.Assignments.PublishedAt.IS_NOT_NULL()))
 	} else {
-		query = query.WHERE(table.Assignments.Deadline.GT_EQ(postgres.DateT(*from.Time)))
+		query = query.WHERE(table.Assignments.Deadline.GT_EQ(postgres.DateT(*from.Time)).
+			AND(table.Assignments.PublishedAt.IS_NOT_NULL()))
 	}
 
 	query = query.ORDER_BY(table.Assignments.Deadline.ASC())
@@ -196,3 +287,404 @@ func (m AssignmentModel) RemoveAssignmentDoneForUserID(userID, assignmentID int)
 
 	return nil
 }
+
+func (m AssignmentModel) IsAssignmentForStudent(studentID, assignmentID int) (bool, error) {
+	query := postgres.SELECT(postgres.COUNT(postgres.Int32(1))).
+		FROM(table.Assignments.
+			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Assignments.JournalID))).
+		WHERE(postgres.AND(
+			table.Assignments.ID.EQ(helpers.PostgresInt(assignmentID)),
+			table.Assignments.PublishedAt.IS_NOT_NULL(),
+			table.StudentsJournals.StudentID.EQ(helpers.PostgresInt(studentID)),
+			assignmentTargetsStudent(table.StudentsJournals.StudentID),
+		))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result[0] > 0, nil
+}
+
+func (m AssignmentModel) GetStudentIDsForAssignment(assignmentID int) ([]int, error) {
+	query := postgres.SELECT(table.StudentsJournals.StudentID).
+		FROM(table.Assignments.
+			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Assignments.JournalID))).
+		WHERE(table.Assignments.ID.EQ(helpers.PostgresInt(assignmentID)).
+			AND(assignmentTargetsStudent(table.StudentsJournals.StudentID)))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// replaces the assignment's target students and groups, no targets means the whole journal
+func (m AssignmentModel) SetAssignmentTargets(tx *sql.Tx, assignmentID int, studentIDs, groupIDs []int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := table.AssignmentsStudents.DELETE().
+		WHERE(table.AssignmentsStudents.AssignmentID.EQ(helpers.PostgresInt(assignmentID))).
+		ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = table.AssignmentsGroups.DELETE().
+		WHERE(table.AssignmentsGroups.AssignmentID.EQ(helpers.PostgresInt(assignmentID))).
+		ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if len(studentIDs) > 0 {
+		var ass []model.AssignmentsStudents
+		for _, sid := range studentIDs {
+			sid := sid
+			ass = append(ass, model.AssignmentsStudents{
+				AssignmentID: &assignmentID,
+				StudentID:    &sid,
+			})
+		}
+
+		_, err := table.AssignmentsStudents.INSERT(table.AssignmentsStudents.AllColumns).
+			MODELS(ass).
+			ON_CONFLICT(table.AssignmentsStudents.AllColumns...).DO_NOTHING().
+			ExecContext(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(groupIDs) > 0 {
+		var ags []model.AssignmentsGroups
+		for _, gid := range groupIDs {
+			gid := gid
+			ags = append(ags, model.AssignmentsGroups{
+				AssignmentID: &assignmentID,
+				GroupID:      &gid,
+			})
+		}
+
+		_, err := table.AssignmentsGroups.INSERT(table.AssignmentsGroups.AllColumns).
+			MODELS(ags).
+			ON_CONFLICT(table.AssignmentsGroups.AllColumns...).DO_NOTHING().
+			ExecContext(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// marks assignments that aren't drafts and whose publishing time has come as published,
+// the students are notified of them separately
+func (m AssignmentModel) PublishDueAssignments() error {
+	now := time.Now().UTC()
+
+	stmt := table.Assignments.UPDATE(table.Assignments.PublishedAt).
+		SET(postgres.TimestampzT(now)).
+		WHERE(postgres.AND(
+			table.Assignments.PublishedAt.IS_NULL(),
+			table.Assignments.Draft.IS_FALSE(),
+			table.Assignments.PublishAt.IS_NULL().OR(table.Assignments.PublishAt.LT_EQ(postgres.TimestampzT(now))),
+		))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// published assignments whose students haven't been notified yet
+func (m AssignmentModel) GetUnnotifiedAssignments() ([]*Assignment, error) {
+	query := postgres.SELECT(table.Assignments.AllColumns).
+		FROM(table.Assignments).
+		WHERE(table.Assignments.PublishedAt.IS_NOT_NULL().
+			AND(table.Assignments.NotifiedAt.IS_NULL())).
+		ORDER_BY(table.Assignments.PublishedAt.ASC())
+
+	var assignments []*Assignment
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// marks the assignment's students as notified, false if that was already done.
+// The row stays locked until tx ends, so the notifications are only sent once
+func (m AssignmentModel) SetAssignmentNotified(tx *sql.Tx, assignmentID int) (bool, error) {
+	stmt := table.Assignments.UPDATE(table.Assignments.NotifiedAt).
+		SET(postgres.TimestampzT(time.Now().UTC())).
+		WHERE(table.Assignments.ID.EQ(helpers.PostgresInt(assignmentID)).
+			AND(table.Assignments.NotifiedAt.IS_NULL()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// published assignments with the deadline on the date, with the students that haven't marked them done
+func (m AssignmentModel) GetUndoneAssignmentsDueOn(date time.Time) ([]*AssignmentReminder, error) {
+	student := table.Users.AS("reminder_students")
+
+	query := postgres.SELECT(table.Assignments.AllColumns, table.Subjects.ID, table.Subjects.Name,
+		student.ID, student.Name).
+		FROM(table.Assignments.
+			INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.Assignments.JournalID)).
+			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)).
+			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Assignments.JournalID)).
+			INNER_JOIN(student, student.ID.EQ(table.StudentsJournals.StudentID)).
+			LEFT_JOIN(table.DoneAssignments, table.DoneAssignments.AssignmentID.EQ(table.Assignments.ID).
+				AND(table.DoneAssignments.UserID.EQ(table.StudentsJournals.StudentID)))).
+		WHERE(postgres.AND(
+			table.Assignments.Deadline.EQ(postgres.DateT(date)),
+			table.Assignments.PublishedAt.IS_NOT_NULL(),
+			table.DoneAssignments.UserID.IS_NULL(),
+			assignmentTargetsStudent(table.StudentsJournals.StudentID),
+		)).
+		ORDER_BY(table.Assignments.ID.ASC())
+
+	var assignments []*AssignmentReminder
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// tests of any of the class's students in any journal with the deadline in the date range, drafts excluded
+func (m AssignmentModel) GetTestsForClass(classID int, from, until *types.Date) ([]*AssignmentExt, error) {
+	query := postgres.SELECT(table.Assignments.AllColumns, table.Subjects.ID, table.Subjects.Name).
+		FROM(table.Assignments.
+			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Assignments.JournalID)).
+			INNER_JOIN(table.Users, table.Users.ID.EQ(table.StudentsJournals.StudentID)).
+			INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.Assignments.JournalID)).
+			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID))).
+		WHERE(postgres.AND(
+			table.Users.ClassID.EQ(helpers.PostgresInt(classID)),
+			table.Assignments.Type.EQ(postgres.String(AssignmentTest)),
+			table.Assignments.Draft.IS_FALSE(),
+			table.Assignments.Deadline.BETWEEN(postgres.DateT(*from.Time), postgres.DateT(*until.Time)),
+			assignmentTargetsStudent(table.StudentsJournals.StudentID),
+		)).
+		ORDER_BY(table.Assignments.Deadline.ASC(), table.Assignments.ID.ASC())
+
+	var assignments []*AssignmentExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// students the assignment is for and whether they have marked it done, only the ones in the class teacher's classes if classTeacherID is set
+func (m AssignmentModel) GetCompletionForAssignment(assignmentID int, classTeacherID *int) ([]*CompletionStudent, error) {
+	where := table.Assignments.ID.EQ(helpers.PostgresInt(assignmentID)).
+		AND(assignmentTargetsStudent(table.StudentsJournals.StudentID))
+	if classTeacherID != nil {
+		where = where.AND(studentInTeachersClass(*classTeacherID))
+	}
+
+	query := postgres.SELECT(table.Users.ID, table.Users.Name, table.Users.Role,
+		table.DoneAssignments.UserID.IS_NOT_NULL().AS("completion_student.done")).
+		FROM(table.Assignments.
+			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Assignments.JournalID)).
+			INNER_JOIN(table.Users, table.Users.ID.EQ(table.StudentsJournals.StudentID)).
+			LEFT_JOIN(table.DoneAssignments, table.DoneAssignments.AssignmentID.EQ(table.Assignments.ID).
+				AND(table.DoneAssignments.UserID.EQ(table.StudentsJournals.StudentID)))).
+		WHERE(where).
+		ORDER_BY(table.Users.Name.ASC())
+
+	var students []*CompletionStudent
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &students)
+	if err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+// joins published assignments matching the condition with the students they are for and whether they are done,
+// students without any such assignments are kept
+func completionFrom(from postgres.ReadableTable, assignments postgres.BoolExpression) postgres.ReadableTable {
+	return from.
+		LEFT_JOIN(table.Assignments, postgres.AND(
+			table.Assignments.JournalID.EQ(table.StudentsJournals.JournalID),
+			table.Assignments.PublishedAt.IS_NOT_NULL(),
+			assignmentTargetsStudent(table.StudentsJournals.StudentID),
+			assignments,
+		)).
+		LEFT_JOIN(table.DoneAssignments, table.DoneAssignments.AssignmentID.EQ(table.Assignments.ID).
+			AND(table.DoneAssignments.UserID.EQ(table.StudentsJournals.StudentID)))
+}
+
+var completionCounts = postgres.ProjectionList{
+	postgres.COUNT(table.Assignments.ID).AS("completion_rate.assigned"),
+	postgres.COUNT(table.DoneAssignments.UserID).AS("completion_rate.done"),
+}
+
+// whether the journal's student is in one of the teacher's classes
+func studentInTeachersClass(teacherID int) postgres.BoolExpression {
+	student := table.Users.AS("class_student")
+
+	return postgres.EXISTS(
+		postgres.SELECT(postgres.Int32(1)).
+			FROM(student.
+				INNER_JOIN(table.TeachersClasses, table.TeachersClasses.ClassID.EQ(student.ClassID))).
+			WHERE(student.ID.EQ(table.StudentsJournals.StudentID).
+				AND(table.TeachersClasses.TeacherID.EQ(helpers.PostgresInt(teacherID)))))
+}
+
+// the students in the journal, only the ones in the class teacher's classes if classTeacherID is set
+func completionStudents(journalID int, classTeacherID *int) postgres.BoolExpression {
+	where := table.StudentsJournals.JournalID.EQ(helpers.PostgresInt(journalID))
+	if classTeacherID != nil {
+		where = where.AND(studentInTeachersClass(*classTeacherID))
+	}
+	return where
+}
+
+// completion of each published assignment in the journal with the deadline in the date range
+func (m AssignmentModel) GetAssignmentCompletionRatesForJournal(journalID int, classTeacherID *int, from, until *types.Date) ([]*AssignmentCompletionRate, error) {
+	query := postgres.SELECT(table.Assignments.AllColumns, completionCounts).
+		FROM(completionFrom(table.StudentsJournals,
+			table.Assignments.Deadline.BETWEEN(postgres.DateT(*from.Time), postgres.DateT(*until.Time)))).
+		WHERE(completionStudents(journalID, classTeacherID).
+			AND(table.Assignments.ID.IS_NOT_NULL())).
+		GROUP_BY(table.Assignments.ID).
+		ORDER_BY(table.Assignments.Deadline.ASC(), table.Assignments.ID.ASC())
+
+	var rates []*AssignmentCompletionRate
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &rates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range rates {
+		r.setRate()
+	}
+
+	return rates, nil
+}
+
+// completion of each student in the journal over assignments with the deadline in the date range
+func (m AssignmentModel) GetStudentCompletionRatesForJournal(journalID int, classTeacherID *int, from, until *types.Date) ([]*StudentCompletionRate, error) {
+	query := postgres.SELECT(table.Users.ID, table.Users.Name, table.Users.Role, completionCounts).
+		FROM(completionFrom(table.StudentsJournals.
+			INNER_JOIN(table.Users, table.Users.ID.EQ(table.StudentsJournals.StudentID)),
+			table.Assignments.Deadline.BETWEEN(postgres.DateT(*from.Time), postgres.DateT(*until.Time)))).
+		WHERE(completionStudents(journalID, classTeacherID)).
+		GROUP_BY(table.Users.ID).
+		ORDER_BY(table.Users.Name.ASC())
+
+	var rates []*StudentCompletionRate
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &rates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range rates {
+		r.setRate()
+	}
+
+	return rates, nil
+}
+
+// the student's completion over assignments due by today in each of their current year journals,
+// optionally only those the teacher teaches
+func (m AssignmentModel) GetJournalCompletionRatesForStudent(studentID int, teacherID *int) ([]*JournalCompletionRate, error) {
+	where := table.StudentsJournals.StudentID.EQ(helpers.PostgresInt(studentID))
+	if teacherID != nil {
+		where = where.AND(postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.TeachersJournals).
+				WHERE(table.TeachersJournals.JournalID.EQ(table.Journals.ID).
+					AND(table.TeachersJournals.TeacherID.EQ(helpers.PostgresInt(*teacherID))))))
+	}
+
+	query := postgres.SELECT(table.Journals.ID, table.Journals.Name, table.Subjects.ID, table.Subjects.Name, completionCounts).
+		FROM(completionFrom(table.StudentsJournals.
+			INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.StudentsJournals.JournalID)).
+			INNER_JOIN(table.Years, table.Years.ID.EQ(table.Journals.YearID).AND(table.Years.Current.IS_TRUE())).
+			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)),
+			table.Assignments.Deadline.LT_EQ(postgres.DateT(time.Now().UTC())))).
+		WHERE(where).
+		GROUP_BY(table.Journals.ID, table.Subjects.ID).
+		ORDER_BY(table.Subjects.Name.ASC(), table.Journals.Name.ASC())
+
+	var rates []*JournalCompletionRate
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &rates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range rates {
+		r.setRate()
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,163 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+)
+
+var (
+	ErrNoSuchAttachment        = errors.New("no such attachment")
+	ErrAttachmentTooLarge      = errors.New("attachment too large")
+	ErrAttachmentTypeForbidden = errors.New("attachment type not allowed")
+)
+
+type Attachment = model.Attachments
+
+type AttachmentExt struct {
+	Attachment
+	By *User `json:"by,omitempty" alias:"uploader"`
+}
+
+type AttachmentModel struct {
+	DB *sql.DB
+}
+
+func (m AttachmentModel) GetAttachmentByID(attachmentID int) (*Attachment, error) {
+	query := postgres.SELECT(table.Attachments.AllColumns).
+		FROM(table.Attachments).
+		WHERE(table.Attachments.ID.EQ(helpers.PostgresInt(attachmentID)))
+
+	var attachment Attachment
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &attachment)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchAttachment
+		default:
+			return nil, err
+		}
+	}
+
+	return &attachment, nil
+}
+
+func (m AttachmentModel) getAttachments(where postgres.BoolExpression) ([]*AttachmentExt, error) {
+	uploader := table.Users.AS("uploader")
+
+	query := postgres.SELECT(table.Attachments.AllColumns, uploader.ID, uploader.Name, uploader.Role).
+		FROM(table.Attachments.
+			INNER_JOIN(uploader, uploader.ID.EQ(table.Attachments.UserID))).
+		WHERE(where).
+		ORDER_BY(table.Attachments.CreatedAt.ASC())
+
+	var attachments []*AttachmentExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (m AttachmentModel) GetAttachmentsForExcuse(markID int) ([]*AttachmentExt, error) {
+	return m.getAttachments(table.Attachments.ExcuseID.EQ(helpers.PostgresInt(markID)))
+}
+
+func (m AttachmentModel) GetAttachmentsForAssignment(assignmentID int) ([]*AttachmentExt, error) {
+	return m.getAttachments(table.Attachments.AssignmentID.EQ(helpers.PostgresInt(assignmentID)))
+}
+
+func (m AttachmentModel) GetAttachmentsForLesson(lessonID int) ([]*AttachmentExt, error) {
+	return m.getAttachments(table.Attachments.LessonID.EQ(helpers.PostgresInt(lessonID)))
+}
+
+func (m AttachmentModel) GetAttachmentsForMessage(messageID int) ([]*AttachmentExt, error) {
+	return m.getAttachments(table.Attachments.MessageID.EQ(helpers.PostgresInt(messageID)))
+}
+
+func (m AttachmentModel) GetAttachmentsForSubmission(submissionID int) ([]*AttachmentExt, error) {
+	return m.getAttachments(table.Attachments.SubmissionID.EQ(helpers.PostgresInt(submissionID)))
+}
+
+func (m AttachmentModel) InsertAttachment(a *Attachment) error {
+	stmt := table.Attachments.INSERT(table.Attachments.MutableColumns).
+		MODEL(a).
+		RETURNING(table.Attachments.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, a)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m AttachmentModel) DeleteAttachment(attachmentID int) error {
+	stmt := table.Attachments.DELETE().
+		WHERE(table.Attachments.ID.EQ(helpers.PostgresInt(attachmentID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// files of deleted attachments that are still in storage, oldest first
+func (m AttachmentModel) GetDeletedAttachments(limit int) ([]*model.AttachmentsDeleted, error) {
+	query := postgres.SELECT(table.AttachmentsDeleted.AllColumns).
+		FROM(table.AttachmentsDeleted).
+		ORDER_BY(table.AttachmentsDeleted.ID.ASC()).
+		LIMIT(int64(limit))
+
+	var deleted []*model.AttachmentsDeleted
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// forgets a deleted attachment once its file has been removed from storage
+func (m AttachmentModel) RemoveDeletedAttachment(id int) error {
+	stmt := table.AttachmentsDeleted.DELETE().
+		WHERE(table.AttachmentsDeleted.ID.EQ(helpers.PostgresInt(id)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,485 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNoSuchResource     = errors.New("no such resource")
+	ErrResourceNameExists = errors.New("resource with this name already exists")
+	ErrNoSuchBooking      = errors.New("no such booking")
+	ErrBookingConflict    = errors.New("booking conflicts with other bookings or lessons")
+)
+
+const (
+	ConflictRoom     = "room"
+	ConflictResource = "resource"
+	ConflictTeacher  = "teacher"
+	ConflictClass    = "class"
+)
+
+type Resource = model.Resources
+
+type Booking = model.Bookings
+
+type BookingExt struct {
+	Booking
+	Room     *Room     `json:"room,omitempty"`
+	Resource *Resource `json:"resource,omitempty"`
+	Class    *Class    `json:"class,omitempty"`
+	By       *User     `json:"by,omitempty" alias:"booker"`
+}
+
+// a booking or lesson that overlaps with a new booking
+type Conflict struct {
+	Type    string      `json:"type"`
+	Booking *BookingExt `json:"booking,omitempty"`
+	Lesson  *LessonExt  `json:"lesson,omitempty"`
+}
+
+type BookingModel struct {
+	DB *sql.DB
+}
+
+func (m BookingModel) AllResources() ([]*Resource, error) {
+	query := postgres.SELECT(table.Resources.AllColumns).
+		FROM(table.Resources).
+		ORDER_BY(table.Resources.Name.ASC())
+
+	var resources []*Resource
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+func (m BookingModel) GetResourceByID(resourceID int) (*Resource, error) {
+	query := postgres.SELECT(table.Resources.AllColumns).
+		FROM(table.Resources).
+		WHERE(table.Resources.ID.EQ(helpers.PostgresInt(resourceID)))
+
+	var resource Resource
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &resource)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchResource
+		default:
+			return nil, err
+		}
+	}
+
+	return &resource, nil
+}
+
+func (m BookingModel) InsertResource(r *Resource) error {
+	stmt := table.Resources.INSERT(table.Resources.MutableColumns).
+		MODEL(r).
+		RETURNING(table.Resources.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, r)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrResourceNameExists
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m BookingModel) UpdateResource(r *Resource) error {
+	stmt := table.Resources.UPDATE(table.Resources.MutableColumns).
+		MODEL(r).
+		WHERE(table.Resources.ID.EQ(helpers.PostgresInt(r.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrResourceNameExists
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m BookingModel) DeleteResource(resourceID int) error {
+	stmt := table.Resources.DELETE().
+		WHERE(table.Resources.ID.EQ(helpers.PostgresInt(resourceID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m BookingModel) GetBookingByID(bookingID int) (*Booking, error) {
+	query := postgres.SELECT(table.Bookings.AllColumns).
+		FROM(table.Bookings).
+		WHERE(table.Bookings.ID.EQ(helpers.PostgresInt(bookingID)))
+
+	var booking Booking
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &booking)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchBooking
+		default:
+			return nil, err
+		}
+	}
+
+	return &booking, nil
+}
+
+func (m BookingModel) InsertBooking(b *Booking) error {
+	stmt := table.Bookings.INSERT(table.Bookings.MutableColumns).
+		MODEL(b).
+		RETURNING(table.Bookings.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, b)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ExclusionViolation {
+			return ErrBookingConflict
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m BookingModel) DeleteBooking(bookingID int) error {
+	stmt := table.Bookings.DELETE().
+		WHERE(table.Bookings.ID.EQ(helpers.PostgresInt(bookingID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m BookingModel) getBookings(where postgres.BoolExpression) ([]*BookingExt, error) {
+	booker := table.Users.AS("booker")
+
+	query := postgres.SELECT(
+		table.Bookings.AllColumns,
+		table.Rooms.AllColumns,
+		table.Resources.AllColumns,
+		table.Classes.ID, table.Classes.Name,
+		booker.ID, booker.Name, booker.Role).
+		FROM(table.Bookings.
+			LEFT_JOIN(table.Rooms, table.Rooms.ID.EQ(table.Bookings.RoomID)).
+			LEFT_JOIN(table.Resources, table.Resources.ID.EQ(table.Bookings.ResourceID)).
+			LEFT_JOIN(table.Classes, table.Classes.ID.EQ(table.Bookings.ClassID)).
+			INNER_JOIN(booker, booker.ID.EQ(table.Bookings.UserID))).
+		WHERE(where).
+		ORDER_BY(table.Bookings.Date.ASC(), table.Bookings.StartTime.ASC())
+
+	var bookings []*BookingExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &bookings)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func bookingsInRange(from, until *types.Date) postgres.BoolExpression {
+	return table.Bookings.Date.BETWEEN(postgres.DateT(*from.Time), postgres.DateT(*until.Time))
+}
+
+func bookingsOverlap(date *types.Date, start, end *types.Time) postgres.BoolExpression {
+	return postgres.AND(
+		table.Bookings.Date.EQ(postgres.DateT(*date.Time)),
+		table.Bookings.StartTime.LT(postgres.TimeT(*end.Time)),
+		table.Bookings.EndTime.GT(postgres.TimeT(*start.Time)),
+	)
+}
+
+// lessons without times never overlap
+func lessonsOverlap(date *types.Date, start, end *types.Time) postgres.BoolExpression {
+	return postgres.AND(
+		table.Lessons.Date.EQ(postgres.DateT(*date.Time)),
+		table.Lessons.StartTime.LT(postgres.TimeT(*end.Time)),
+		table.Lessons.EndTime.GT(postgres.TimeT(*start.Time)),
+	)
+}
+
+func (m BookingModel) GetBookingsForRoom(roomID int, from, until *types.Date) ([]*BookingExt, error) {
+	return m.getBookings(table.Bookings.RoomID.EQ(helpers.PostgresInt(roomID)).
+		AND(bookingsInRange(from, until)))
+}
+
+func (m BookingModel) GetBookingsForResource(resourceID int, from, until *types.Date) ([]*BookingExt, error) {
+	return m.getBookings(table.Bookings.ResourceID.EQ(helpers.PostgresInt(resourceID)).
+		AND(bookingsInRange(from, until)))
+}
+
+func (m BookingModel) GetBookingsForUser(userID int, from, until *types.Date) ([]*BookingExt, error) {
+	return m.getBookings(table.Bookings.UserID.EQ(helpers.PostgresInt(userID)).
+		AND(bookingsInRange(from, until)))
+}
+
+func (m BookingModel) getLessons(where postgres.BoolExpression) ([]*LessonExt, error) {
+	query := postgres.SELECT(table.Lessons.AllColumns, table.Journals.ID, table.Journals.Name, table.Subjects.AllColumns).
+		FROM(table.Lessons.
+			INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.Lessons.JournalID)).
+			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)).
+			LEFT_JOIN(table.TimetableSlots, table.TimetableSlots.ID.EQ(table.Lessons.TimetableSlotID))).
+		WHERE(where).
+		ORDER_BY(table.Lessons.Date.ASC(), table.Lessons.StartTime.ASC())
+
+	var lessons []*LessonExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &lessons)
+	if err != nil {
+		return nil, err
+	}
+
+	return lessons, nil
+}
+
+// lessons held in the room according to the timetable
+func (m BookingModel) GetLessonsForRoom(roomID int, from, until *types.Date) ([]*LessonExt, error) {
+	return m.getLessons(table.TimetableSlots.RoomID.EQ(helpers.PostgresInt(roomID)).
+		AND(table.Lessons.Date.BETWEEN(postgres.DateT(*from.Time), postgres.DateT(*until.Time))))
+}
+
+// excludeLessonID leaves out the lesson the booking is for and other bookings made for it
+func (m BookingModel) GetConflicts(b *Booking, teacherID int, excludeLessonID *int) ([]*Conflict, error) {
+	var conflicts []*Conflict
+
+	notExcludedBooking := table.Bookings.ID.NOT_EQ(helpers.PostgresInt(b.ID))
+	notExcludedLesson := postgres.Bool(true)
+	if excludeLessonID != nil {
+		notExcludedBooking = notExcludedBooking.AND(table.Bookings.LessonID.IS_NULL().
+			OR(table.Bookings.LessonID.NOT_EQ(helpers.PostgresInt(*excludeLessonID))))
+		notExcludedLesson = table.Lessons.ID.NOT_EQ(helpers.PostgresInt(*excludeLessonID))
+	}
+
+	add := func(conflictType string, bookings []*BookingExt, lessons []*LessonExt) {
+		for _, booking := range bookings {
+			conflicts = append(conflicts, &Conflict{Type: conflictType, Booking: booking})
+		}
+		for _, lesson := range lessons {
+			conflicts = append(conflicts, &Conflict{Type: conflictType, Lesson: lesson})
+		}
+	}
+
+	// double bookings
+	if b.RoomID != nil {
+		bookings, err := m.getBookings(postgres.AND(
+			table.Bookings.RoomID.EQ(helpers.PostgresInt(*b.RoomID)),
+			bookingsOverlap(b.Date, b.StartTime, b.EndTime),
+			table.Bookings.ID.NOT_EQ(helpers.PostgresInt(b.ID)),
+		))
+		if err != nil {
+			return nil, err
+		}
+
+		lessons, err := m.getLessons(postgres.AND(
+			table.TimetableSlots.RoomID.EQ(helpers.PostgresInt(*b.RoomID)),
+			lessonsOverlap(b.Date, b.StartTime, b.EndTime),
+			notExcludedLesson,
+		))
+		if err != nil {
+			return nil, err
+		}
+
+		add(ConflictRoom, bookings, lessons)
+	} else {
+		bookings, err := m.getBookings(postgres.AND(
+			table.Bookings.ResourceID.EQ(helpers.PostgresInt(*b.ResourceID)),
+			bookingsOverlap(b.Date, b.StartTime, b.EndTime),
+			table.Bookings.ID.NOT_EQ(helpers.PostgresInt(b.ID)),
+		))
+		if err != nil {
+			return nil, err
+		}
+
+		add(ConflictResource, bookings, nil)
+	}
+
+	// the teacher is busy with another lesson or booking
+	bookings, err := m.getBookings(postgres.AND(
+		table.Bookings.UserID.EQ(helpers.PostgresInt(teacherID)),
+		bookingsOverlap(b.Date, b.StartTime, b.EndTime),
+		notExcludedBooking,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	lessons, err := m.getLessons(postgres.AND(
+		postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.TeachersJournals).
+				WHERE(table.TeachersJournals.JournalID.EQ(table.Lessons.JournalID).
+					AND(table.TeachersJournals.TeacherID.EQ(helpers.PostgresInt(teacherID))))),
+		lessonsOverlap(b.Date, b.StartTime, b.EndTime),
+		notExcludedLesson,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	add(ConflictTeacher, bookings, lessons)
+
+	// the students the booking is for have another lesson or booking
+	var students postgres.SelectStatement
+	switch {
+	case excludeLessonID != nil:
+		students = postgres.SELECT(table.StudentsJournals.StudentID).
+			FROM(table.StudentsJournals.
+				INNER_JOIN(table.Lessons, table.Lessons.JournalID.EQ(table.StudentsJournals.JournalID))).
+			WHERE(table.Lessons.ID.EQ(helpers.PostgresInt(*excludeLessonID)))
+	case b.ClassID != nil:
+		students = postgres.SELECT(table.Users.ID).
+			FROM(table.Users).
+			WHERE(table.Users.ClassID.EQ(helpers.PostgresInt(*b.ClassID)))
+	default:
+		return conflicts, nil
+	}
+
+	bookings = nil
+	if b.ClassID != nil {
+		bookings, err = m.getBookings(postgres.AND(
+			table.Bookings.ClassID.EQ(helpers.PostgresInt(*b.ClassID)),
+			bookingsOverlap(b.Date, b.StartTime, b.EndTime),
+			notExcludedBooking,
+		))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lessons, err = m.getLessons(postgres.AND(
+		postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.StudentsJournals).
+				WHERE(table.StudentsJournals.JournalID.EQ(table.Lessons.JournalID).
+					AND(table.StudentsJournals.StudentID.IN(students)))),
+		lessonsOverlap(b.Date, b.StartTime, b.EndTime),
+		notExcludedLesson,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	add(ConflictClass, bookings, lessons)
+
+	return conflicts, nil
+}
+
+func (m BookingModel) GetAvailableRooms(date *types.Date, start, end *types.Time) ([]*Room, error) {
+	query := postgres.SELECT(table.Rooms.AllColumns).
+		FROM(table.Rooms).
+		WHERE(postgres.AND(
+			postgres.NOT(postgres.EXISTS(
+				postgres.SELECT(postgres.Int32(1)).
+					FROM(table.Bookings).
+					WHERE(table.Bookings.RoomID.EQ(table.Rooms.ID).
+						AND(bookingsOverlap(date, start, end))))),
+			postgres.NOT(postgres.EXISTS(
+				postgres.SELECT(postgres.Int32(1)).
+					FROM(table.Lessons.
+						INNER_JOIN(table.TimetableSlots, table.TimetableSlots.ID.EQ(table.Lessons.TimetableSlotID))).
+					WHERE(table.TimetableSlots.RoomID.EQ(table.Rooms.ID).
+						AND(lessonsOverlap(date, start, end))))),
+		)).
+		ORDER_BY(table.Rooms.Name.ASC())
+
+	var rooms []*Room
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &rooms)
+	if err != nil {
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+func (m BookingModel) GetAvailableResources(date *types.Date, start, end *types.Time) ([]*Resource, error) {
+	query := postgres.SELECT(table.Resources.AllColumns).
+		FROM(table.Resources).
+		WHERE(postgres.NOT(postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.Bookings).
+				WHERE(table.Bookings.ResourceID.EQ(table.Resources.ID).
+					AND(bookingsOverlap(date, start, end)))))).
+		ORDER_BY(table.Resources.Name.ASC())
+
+	var resources []*Resource
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,178 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+)
+
+var (
+	ErrNoSuchCalendarEntry = errors.New("no such calendar entry")
+	ErrDateIsDayOff        = errors.New("date falls on a holiday or vacation")
+)
+
+const (
+	CalendarHoliday  = "holiday"
+	CalendarVacation = "vacation"
+	CalendarEvent    = "event"
+)
+
+type CalendarEntry = model.CalendarEntries
+
+type CalendarModel struct {
+	DB *sql.DB
+}
+
+func (m CalendarModel) GetEntryByID(entryID int) (*CalendarEntry, error) {
+	query := postgres.SELECT(table.CalendarEntries.AllColumns).
+		FROM(table.CalendarEntries).
+		WHERE(table.CalendarEntries.ID.EQ(helpers.PostgresInt(entryID)))
+
+	var entry CalendarEntry
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &entry)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchCalendarEntry
+		default:
+			return nil, err
+		}
+	}
+
+	return &entry, nil
+}
+
+func (m CalendarModel) InsertEntry(e *CalendarEntry) error {
+	stmt := table.CalendarEntries.INSERT(table.CalendarEntries.MutableColumns).
+		MODEL(e).
+		RETURNING(table.CalendarEntries.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, e)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m CalendarModel) UpdateEntry(e *CalendarEntry) error {
+	stmt := table.CalendarEntries.UPDATE(table.CalendarEntries.MutableColumns).
+		MODEL(e).
+		WHERE(table.CalendarEntries.ID.EQ(helpers.PostgresInt(e.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m CalendarModel) DeleteEntry(entryID int) error {
+	stmt := table.CalendarEntries.DELETE().
+		WHERE(table.CalendarEntries.ID.EQ(helpers.PostgresInt(entryID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// classIDs limits class-specific events to the given classes, nil returns entries for all classes
+func (m CalendarModel) GetEntriesForYear(yearID int, classIDs []int) ([]*CalendarEntry, error) {
+	condition := table.CalendarEntries.YearID.EQ(helpers.PostgresInt(yearID))
+
+	if classIDs != nil {
+		var cids []postgres.Expression
+		for _, id := range classIDs {
+			cids = append(cids, helpers.PostgresInt(id))
+		}
+
+		classCondition := table.CalendarEntries.ClassID.IS_NULL()
+		if len(cids) > 0 {
+			classCondition = classCondition.OR(table.CalendarEntries.ClassID.IN(cids...))
+		}
+
+		condition = condition.AND(classCondition)
+	}
+
+	query := postgres.SELECT(table.CalendarEntries.AllColumns).
+		FROM(table.CalendarEntries).
+		WHERE(condition).
+		ORDER_BY(table.CalendarEntries.StartDate.ASC(), table.CalendarEntries.ID.ASC())
+
+	var entries []*CalendarEntry
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (m CalendarModel) GetDaysOff(yearID int, from, until time.Time) ([]time.Time, error) {
+	query := postgres.SELECT(table.CalendarEntries.StartDate, table.CalendarEntries.EndDate).
+		FROM(table.CalendarEntries).
+		WHERE(table.CalendarEntries.YearID.EQ(helpers.PostgresInt(yearID)).
+			AND(table.CalendarEntries.Type.IN(postgres.String(CalendarHoliday), postgres.String(CalendarVacation))).
+			AND(table.CalendarEntries.StartDate.LT_EQ(postgres.DateT(until))).
+			AND(table.CalendarEntries.EndDate.GT_EQ(postgres.DateT(from))))
+
+	var entries []*CalendarEntry
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var days []time.Time
+
+	for _, e := range entries {
+		for day := *e.StartDate.Time; !day.After(*e.EndDate.Time); day = day.AddDate(0, 0, 1) {
+			if !day.Before(from) && !day.After(until) {
+				days = append(days, day)
+			}
+		}
+	}
+
+	return days, nil
+}
+
+func (m CalendarModel) IsDayOff(yearID int, date *types.Date) (bool, error) {
+	days, err := m.GetDaysOff(yearID, *date.Time, *date.Time)
+	if err != nil {
+		return false, err
+	}
+
+	return len(days) > 0, nil
+}
@@ -65,6 +65,22 @@ func (m ClassModel) UpdateClass(c *ClassExt) error {
 	return nil
 }
 
+func (m ClassModel) SetClassWorkloadLimits(c *Class) error {
+	stmt := table.Classes.UPDATE(table.Classes.MaxTestsPerDay, table.Classes.MaxTestsPerWeek).
+		MODEL(c).
+		WHERE(table.Classes.ID.EQ(helpers.PostgresInt(c.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m ClassModel) SetClassTeachers(classID int, teacherIDs []int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -159,6 +175,68 @@ func (m ClassModel) GetAllClassIDs() ([]int, error) {
 	return ids, nil
 }
 
+func (m ClassModel) GetClassIDsForStudents(studentIDs []int) ([]int, error) {
+	var sids []postgres.Expression
+	for _, sid := range studentIDs {
+		sids = append(sids, helpers.PostgresInt(sid))
+	}
+
+	if len(sids) == 0 {
+		return []int{}, nil
+	}
+
+	query := postgres.SELECT(table.Users.ClassID).
+		DISTINCT().
+		FROM(table.Users).
+		WHERE(table.Users.ID.IN(sids...).
+			AND(table.Users.ClassID.IS_NOT_NULL()))
+
+	var ids []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// the classes the teacher is class teacher of, and the classes of students in the teacher's journals of the year
+func (m ClassModel) GetClassIDsForTeacher(teacherID, yearID int) ([]int, error) {
+	tid := helpers.PostgresInt(teacherID)
+
+	query := postgres.UNION(
+		postgres.SELECT(table.TeachersClasses.ClassID).
+			FROM(table.TeachersClasses).
+			WHERE(table.TeachersClasses.TeacherID.EQ(tid)),
+		postgres.SELECT(table.Users.ClassID).
+			FROM(table.TeachersJournals.
+				INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.TeachersJournals.JournalID)).
+				INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Journals.ID)).
+				INNER_JOIN(table.Users, table.Users.ID.EQ(table.StudentsJournals.StudentID))).
+			WHERE(postgres.AND(
+				table.TeachersJournals.TeacherID.EQ(tid),
+				table.Journals.YearID.EQ(helpers.PostgresInt(yearID)),
+				table.Users.ClassID.IS_NOT_NULL(),
+			)),
+	)
+
+	ids := []int{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
 func (m ClassModel) GetClassByID(classID int) (*ClassExt, error) {
 	teacher := table.Users.AS("teachers")
 
@@ -0,0 +1,268 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+const (
+	EmailEventMessage       = "message"
+	EmailEventMark          = "mark"
+	EmailEventAbsence       = "absence"
+	EmailEventAssignmentDue = "assignment_due"
+)
+
+var EmailEventTypes = []string{EmailEventMessage, EmailEventMark, EmailEventAbsence, EmailEventAssignmentDue}
+
+const (
+	DeliveryImmediate = "immediate"
+	DeliveryDigest    = "digest"
+	DeliveryOff       = "off"
+)
+
+// emails that keep failing are given up on
+const (
+	MaxEmailAttempts = 5
+	emailRetryDelay  = 10 * time.Minute
+	emailClaimLease  = 15 * time.Minute
+)
+
+type NotificationPreference = model.NotificationPreferences
+
+type Email = model.EmailOutbox
+
+// who an email about ForUser goes to, either the user themselves or one of their parents
+type EmailRecipient struct {
+	ForUserID int    `alias:"for_user.id"`
+	ForName   string `alias:"for_user.name"`
+	UserID    int    `alias:"recipient.id"`
+	Email     string `alias:"recipient.email"`
+	Delivery  string `alias:"email_recipient.delivery"`
+}
+
+type EmailModel struct {
+	DB *sql.DB
+}
+
+// preferences the user has set, event types without one are delivered immediately
+func (m EmailModel) GetNotificationPreferences(userID int) ([]*NotificationPreference, error) {
+	query := postgres.SELECT(table.NotificationPreferences.AllColumns).
+		FROM(table.NotificationPreferences).
+		WHERE(table.NotificationPreferences.UserID.EQ(helpers.PostgresInt(userID))).
+		ORDER_BY(table.NotificationPreferences.EventType.ASC())
+
+	var prefs []*NotificationPreference
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &prefs)
+	if err != nil {
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+func (m EmailModel) SetNotificationPreferences(prefs []*NotificationPreference) error {
+	stmt := table.NotificationPreferences.INSERT(table.NotificationPreferences.AllColumns).
+		MODELS(prefs).
+		ON_CONFLICT(table.NotificationPreferences.UserID, table.NotificationPreferences.EventType).
+		DO_UPDATE(postgres.SET(
+			table.NotificationPreferences.Delivery.SET(table.NotificationPreferences.EXCLUDED.Delivery),
+		))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// the user themselves and, with withParents set, their parents
+func notificationRecipient(recipient, forUser *table.UsersTable, withParents bool) postgres.BoolExpression {
+	condition := recipient.ID.EQ(forUser.ID)
+	if withParents {
+		condition = condition.OR(recipient.ID.IN(
+			postgres.SELECT(table.ParentsChildren.ParentID).
+				FROM(table.ParentsChildren).
+				WHERE(table.ParentsChildren.ChildID.EQ(forUser.ID))))
+	}
+
+	return condition
+}
+
+// active users with an email address that haven't turned the event type off,
+// with withParents set the parents of the users are included as well
+func (m EmailModel) GetEmailRecipients(eventType string, userIDs []int, withParents bool) ([]*EmailRecipient, error) {
+	var uids []postgres.Expression
+	for _, uid := range userIDs {
+		uids = append(uids, helpers.PostgresInt(uid))
+	}
+
+	forUser := table.Users.AS("for_user")
+	recipient := table.Users.AS("recipient")
+
+	delivery := postgres.StringExp(postgres.COALESCE(table.NotificationPreferences.Delivery, postgres.String(DeliveryImmediate)))
+
+	query := postgres.SELECT(forUser.ID, forUser.Name, recipient.ID, recipient.Email,
+		delivery.AS("email_recipient.delivery")).
+		FROM(forUser.
+			INNER_JOIN(recipient, notificationRecipient(recipient, forUser, withParents)).
+			LEFT_JOIN(table.NotificationPreferences, table.NotificationPreferences.UserID.EQ(recipient.ID).
+				AND(table.NotificationPreferences.EventType.EQ(postgres.String(eventType))))).
+		WHERE(postgres.AND(
+			forUser.ID.IN(uids...),
+			recipient.Email.IS_NOT_NULL(),
+			recipient.Active.IS_TRUE(),
+			recipient.Archived.IS_FALSE(),
+			delivery.NOT_EQ(postgres.String(DeliveryOff)),
+		))
+
+	var recipients []*EmailRecipient
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+// emails with a dedupe key that has already been queued are skipped
+func (m EmailModel) InsertEmails(emails []*Email) error {
+	stmt := table.EmailOutbox.INSERT(table.EmailOutbox.MutableColumns).
+		MODELS(emails).
+		ON_CONFLICT(table.EmailOutbox.DedupeKey).DO_NOTHING()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// claims unsent emails whose time has come by moving their send_after past the time it takes to send them,
+// so other senders leave them alone and they are tried again if the sender dies before marking them.
+// Digests are claimed whole per user, limit is then the number of users
+func (m EmailModel) ClaimDueEmails(digest bool, limit int) ([]*Email, error) {
+	currentTime := time.Now().UTC()
+
+	due := postgres.AND(
+		table.EmailOutbox.SentAt.IS_NULL(),
+		table.EmailOutbox.Digest.EQ(postgres.Bool(digest)),
+		table.EmailOutbox.SendAfter.LT_EQ(postgres.TimestampzT(currentTime)),
+		table.EmailOutbox.Attempts.LT(postgres.Int32(MaxEmailAttempts)),
+	)
+
+	claimable := postgres.SELECT(table.EmailOutbox.ID).
+		FROM(table.EmailOutbox).
+		WHERE(due).
+		ORDER_BY(table.EmailOutbox.ID.ASC()).
+		LIMIT(int64(limit)).
+		FOR(postgres.UPDATE().SKIP_LOCKED())
+
+	if digest {
+		users := postgres.SELECT(table.EmailOutbox.UserID).
+			DISTINCT().
+			FROM(table.EmailOutbox).
+			WHERE(due).
+			ORDER_BY(table.EmailOutbox.UserID.ASC()).
+			LIMIT(int64(limit))
+
+		claimable = postgres.SELECT(table.EmailOutbox.ID).
+			FROM(table.EmailOutbox).
+			WHERE(due.AND(table.EmailOutbox.UserID.IN(users))).
+			FOR(postgres.UPDATE().SKIP_LOCKED())
+	}
+
+	stmt := table.EmailOutbox.UPDATE(table.EmailOutbox.SendAfter).
+		SET(currentTime.Add(emailClaimLease)).
+		WHERE(table.EmailOutbox.ID.IN(claimable)).
+		RETURNING(table.EmailOutbox.AllColumns)
+
+	var emails []*Email
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, &emails)
+	if err != nil {
+		return nil, err
+	}
+
+	// returned rows come in no particular order
+	sort.Slice(emails, func(i, j int) bool {
+		if *emails[i].UserID != *emails[j].UserID {
+			return *emails[i].UserID < *emails[j].UserID
+		}
+		return emails[i].ID < emails[j].ID
+	})
+
+	return emails, nil
+}
+
+func (m EmailModel) SetEmailsSent(emailIDs []int) error {
+	var eids []postgres.Expression
+	for _, eid := range emailIDs {
+		eids = append(eids, helpers.PostgresInt(eid))
+	}
+
+	stmt := table.EmailOutbox.UPDATE(table.EmailOutbox.SentAt).
+		SET(time.Now().UTC()).
+		WHERE(table.EmailOutbox.ID.IN(eids...))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// records the failure and retries the emails later
+func (m EmailModel) SetEmailsFailed(emailIDs []int, sendErr error) error {
+	var eids []postgres.Expression
+	for _, eid := range emailIDs {
+		eids = append(eids, helpers.PostgresInt(eid))
+	}
+
+	stmt := table.EmailOutbox.UPDATE().
+		SET(
+			table.EmailOutbox.Attempts.SET(table.EmailOutbox.Attempts.ADD(postgres.Int32(1))),
+			table.EmailOutbox.LastError.SET(postgres.String(sendErr.Error())),
+			table.EmailOutbox.SendAfter.SET(postgres.TimestampzT(time.Now().UTC().Add(emailRetryDelay))),
+		).
+		WHERE(table.EmailOutbox.ID.IN(eids...))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
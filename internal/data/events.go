@@ -0,0 +1,88 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+// postgres channel events are fanned out on, so every API instance sees them
+const EventsChannel = "lavurso_events"
+
+const (
+	EventMessage        = "message"
+	EventMessageUpdated = "message_updated"
+	EventMessageDeleted = "message_deleted"
+	EventMembersAdded   = "members_added"
+	EventMembersRemoved = "members_removed"
+	EventThreadLocked   = "thread_locked"
+	EventThreadUnlocked = "thread_unlocked"
+	EventThreadDeleted  = "thread_deleted"
+	EventUnread         = "unread"
+)
+
+// postgres drops notifications over 8000 bytes, UserIDs is split across notifications to stay well below
+const eventUserIDsPerNotification = 500
+
+// an event is delivered to UserIDs and, with ThreadMembers set, to everyone in the thread.
+// Thread members are looked up by the listener, UserIDs is only for the ones who can't be, like the
+// members of a deleted thread
+type Event struct {
+	Type          string `json:"type"`
+	ThreadID      *int   `json:"thread_id,omitempty"`
+	MessageID     *int   `json:"message_id,omitempty"`
+	UserIDs       []int  `json:"user_ids,omitempty"`
+	ThreadMembers bool   `json:"thread_members,omitempty"`
+}
+
+type EventModel struct {
+	DB *sql.DB
+}
+
+func (m EventModel) PublishEvent(e *Event) error {
+	if len(e.UserIDs) <= eventUserIDsPerNotification {
+		return m.notify(e)
+	}
+
+	for i := 0; i < len(e.UserIDs); i += eventUserIDsPerNotification {
+		end := i + eventUserIDsPerNotification
+		if end > len(e.UserIDs) {
+			end = len(e.UserIDs)
+		}
+
+		part := *e
+		part.UserIDs = e.UserIDs[i:end]
+		// thread members only need to be looked up once
+		part.ThreadMembers = e.ThreadMembers && i == 0
+
+		err := m.notify(&part)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m EventModel) notify(e *Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	stmt := postgres.RawStatement("SELECT pg_notify(#channel, #payload)",
+		postgres.RawArgs{"#channel": EventsChannel, "#payload": string(payload)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err = stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+)
+
+var (
+	ErrNoSuchCalendarFeed = errors.New("no such calendar feed")
+)
+
+type CalendarFeed = model.CalendarFeeds
+
+type CalendarFeedExt struct {
+	CalendarFeed
+	Student *User `json:"student,omitempty" alias:"student"`
+}
+
+type FeedModel struct {
+	DB *sql.DB
+}
+
+func (m FeedModel) GetFeedByID(feedID int) (*CalendarFeed, error) {
+	query := postgres.SELECT(table.CalendarFeeds.AllColumns).
+		FROM(table.CalendarFeeds).
+		WHERE(table.CalendarFeeds.ID.EQ(helpers.PostgresInt(feedID)))
+
+	var feed CalendarFeed
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &feed)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchCalendarFeed
+		default:
+			return nil, err
+		}
+	}
+
+	return &feed, nil
+}
+
+// only returns feeds of active users
+func (m FeedModel) GetFeedByToken(plaintextToken string) (*CalendarFeed, error) {
+	hash := sha256.Sum256([]byte(plaintextToken))
+
+	query := postgres.SELECT(table.CalendarFeeds.AllColumns).
+		FROM(table.CalendarFeeds.
+			INNER_JOIN(table.Users, table.Users.ID.EQ(table.CalendarFeeds.UserID))).
+		WHERE(postgres.AND(
+			table.Users.Archived.IS_FALSE(),
+			table.Users.Active.IS_TRUE(),
+			table.CalendarFeeds.Token.EQ(postgres.Bytea(hash[:])),
+		))
+
+	var feed CalendarFeed
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &feed)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	return &feed, nil
+}
+
+func (m FeedModel) GetFeedsForUser(userID int) ([]*CalendarFeedExt, error) {
+	student := table.Users.AS("student")
+
+	query := postgres.SELECT(table.CalendarFeeds.AllColumns, student.ID, student.Name, student.Role).
+		FROM(table.CalendarFeeds.
+			LEFT_JOIN(student, student.ID.EQ(table.CalendarFeeds.StudentID))).
+		WHERE(table.CalendarFeeds.UserID.EQ(helpers.PostgresInt(userID))).
+		ORDER_BY(table.CalendarFeeds.CreatedAt.ASC())
+
+	var feeds []*CalendarFeedExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &feeds)
+	if err != nil {
+		return nil, err
+	}
+
+	return feeds, nil
+}
+
+func (m FeedModel) InsertFeed(f *CalendarFeed) error {
+	stmt := table.CalendarFeeds.INSERT(table.CalendarFeeds.UserID, table.CalendarFeeds.StudentID, table.CalendarFeeds.Token, table.CalendarFeeds.CreatedAt).
+		MODEL(f).
+		RETURNING(table.CalendarFeeds.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, f)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m FeedModel) SetFeedToken(feedID int, token *types.Token) error {
+	stmt := table.CalendarFeeds.UPDATE(table.CalendarFeeds.Token, table.CalendarFeeds.LastAccessed).
+		SET(postgres.Bytea(token.Hashed), postgres.NULL).
+		WHERE(table.CalendarFeeds.ID.EQ(helpers.PostgresInt(feedID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m FeedModel) SetFeedAccessed(feedID int) error {
+	stmt := table.CalendarFeeds.UPDATE(table.CalendarFeeds.LastAccessed).
+		SET(time.Now().UTC()).
+		WHERE(table.CalendarFeeds.ID.EQ(helpers.PostgresInt(feedID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m FeedModel) DeleteFeed(feedID int) error {
+	stmt := table.CalendarFeeds.DELETE().
+		WHERE(table.CalendarFeeds.ID.EQ(helpers.PostgresInt(feedID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -39,16 +39,33 @@ func main() {
 								} else if table.Name == "users" && columnMetaData.Name == "totp_secret" {
 									defaultTableModelField.Tags = append(defaultTableModelField.Tags, `json:"-"`)
 									defaultTableModelField.Type = template.NewType(new(types.TOTPSecret))
+								} else if table.Name == "attachments" && columnMetaData.Name == "storage_key" {
+									defaultTableModelField.Tags = append(defaultTableModelField.Tags, `json:"-"`)
+								} else if table.Name == "calendar_feeds" && columnMetaData.Name == "token" {
+									defaultTableModelField.Tags = append(defaultTableModelField.Tags, `json:"-"`)
+									defaultTableModelField.Type = template.NewType(new(types.Token))
+								} else if table.Name == "events_tickets" && columnMetaData.Name == "token" {
+									defaultTableModelField.Tags = append(defaultTableModelField.Tags, `json:"-"`)
+									defaultTableModelField.Type = template.NewType(new(types.Token))
 								} else {
 									defaultTableModelField.Tags = append(defaultTableModelField.Tags, fmt.Sprintf(`json:"%s,omitempty"`, columnMetaData.Name))
 								}
 
 								if table.Name == "assignments" && columnMetaData.Name == "deadline" ||
 									table.Name == "users" && columnMetaData.Name == "birth_date" ||
-									table.Name == "lessons" && columnMetaData.Name == "date" {
+									table.Name == "lessons" && columnMetaData.Name == "date" ||
+									table.Name == "absence_notices" && (columnMetaData.Name == "start_date" || columnMetaData.Name == "end_date") ||
+									table.Name == "years" && (columnMetaData.Name == "start_date" || columnMetaData.Name == "end_date") ||
+									table.Name == "calendar_entries" && (columnMetaData.Name == "start_date" || columnMetaData.Name == "end_date") ||
+									table.Name == "substitutions" && (columnMetaData.Name == "start_date" || columnMetaData.Name == "end_date") ||
+									table.Name == "bookings" && columnMetaData.Name == "date" {
 									defaultTableModelField.Type = template.NewType(new(types.Date))
 								}
 
+								if (table.Name == "periods" || table.Name == "lessons" || table.Name == "bookings") && (columnMetaData.Name == "start_time" || columnMetaData.Name == "end_time") {
+									defaultTableModelField.Type = template.NewType(new(types.Time))
+								}
+
 								switch defaultTableModelField.Type.Name {
 								case "int32", "*int32":
 									if columnMetaData.Name != "id" {
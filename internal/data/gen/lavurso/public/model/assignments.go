@@ -20,4 +20,8 @@ type Assignments struct {
 	Type        *string     `json:"type,omitempty"`
 	CreatedAt   *time.Time  `json:"created_at,omitempty"`
 	UpdatedAt   *time.Time  `json:"updated_at,omitempty"`
+	Draft       *bool       `json:"draft,omitempty"`
+	PublishAt   *time.Time  `json:"publish_at,omitempty"`
+	PublishedAt *time.Time  `json:"published_at,omitempty"`
+	NotifiedAt  *time.Time  `json:"notified_at,omitempty"`
 }
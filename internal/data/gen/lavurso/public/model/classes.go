@@ -8,6 +8,8 @@
 package model
 
 type Classes struct {
-	ID   int     `sql:"primary_key" json:"id,omitempty"`
-	Name *string `json:"name,omitempty"`
+	ID              int     `sql:"primary_key" json:"id,omitempty"`
+	Name            *string `json:"name,omitempty"`
+	MaxTestsPerDay  *int    `json:"max_tests_per_day,omitempty"`
+	MaxTestsPerWeek *int    `json:"max_tests_per_week,omitempty"`
 }
@@ -12,8 +12,12 @@ import (
 )
 
 type Excuses struct {
-	MarkID *int       `sql:"primary_key" json:"mark_id,omitempty"`
-	Excuse *string    `json:"excuse,omitempty"`
-	UserID *int       `json:"user_id,omitempty"`
-	At     *time.Time `json:"at,omitempty"`
+	MarkID        *int       `sql:"primary_key" json:"mark_id,omitempty"`
+	Excuse        *string    `json:"excuse,omitempty"`
+	UserID        *int       `json:"user_id,omitempty"`
+	At            *time.Time `json:"at,omitempty"`
+	Status        *string    `json:"status,omitempty"`
+	ReviewerID    *int       `json:"reviewer_id,omitempty"`
+	ReviewComment *string    `json:"review_comment,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
 }
@@ -13,11 +13,14 @@ import (
 )
 
 type Lessons struct {
-	ID          int         `sql:"primary_key" json:"id,omitempty"`
-	JournalID   *int        `json:"journal_id,omitempty"`
-	Description *string     `json:"description,omitempty"`
-	Date        *types.Date `json:"date,omitempty"`
-	Course      *int        `json:"course,omitempty"`
-	CreatedAt   *time.Time  `json:"created_at,omitempty"`
-	UpdatedAt   *time.Time  `json:"updated_at,omitempty"`
+	ID              int         `sql:"primary_key" json:"id,omitempty"`
+	JournalID       *int        `json:"journal_id,omitempty"`
+	Description     *string     `json:"description,omitempty"`
+	Date            *types.Date `json:"date,omitempty"`
+	Course          *int        `json:"course,omitempty"`
+	CreatedAt       *time.Time  `json:"created_at,omitempty"`
+	UpdatedAt       *time.Time  `json:"updated_at,omitempty"`
+	TimetableSlotID *int        `json:"timetable_slot_id,omitempty"`
+	StartTime       *types.Time `json:"start_time,omitempty"`
+	EndTime         *types.Time `json:"end_time,omitempty"`
 }
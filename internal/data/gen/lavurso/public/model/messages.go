@@ -19,4 +19,6 @@ type Messages struct {
 	Type      *string    `json:"type,omitempty"`
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	HiddenAt  *time.Time `json:"hidden_at,omitempty"`
+	HiddenBy  *int       `json:"hidden_by,omitempty"`
 }
@@ -12,10 +12,11 @@ import (
 )
 
 type Threads struct {
-	ID        int        `sql:"primary_key" json:"id,omitempty"`
-	UserID    *int       `json:"user_id,omitempty"`
-	Title     *string    `json:"title,omitempty"`
-	Locked    *bool      `json:"locked,omitempty"`
-	CreatedAt *time.Time `json:"created_at,omitempty"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ID            int        `sql:"primary_key" json:"id,omitempty"`
+	UserID        *int       `json:"user_id,omitempty"`
+	Title         *string    `json:"title,omitempty"`
+	Locked        *bool      `json:"locked,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	LockedByAdmin *bool      `json:"locked_by_admin,omitempty"`
 }
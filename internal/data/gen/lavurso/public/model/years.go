@@ -7,8 +7,14 @@
 
 package model
 
+import (
+	"github.com/annusingmar/lavurso-backend/internal/types"
+)
+
 type Years struct {
-	ID          int     `sql:"primary_key" json:"id,omitempty"`
-	DisplayName *string `json:"display_name,omitempty"`
-	Current     *bool   `json:"current,omitempty"`
+	ID          int         `sql:"primary_key" json:"id,omitempty"`
+	DisplayName *string     `json:"display_name,omitempty"`
+	Current     *bool       `json:"current,omitempty"`
+	StartDate   *types.Date `json:"start_date,omitempty"`
+	EndDate     *types.Date `json:"end_date,omitempty"`
 }
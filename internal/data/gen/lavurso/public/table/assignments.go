@@ -24,6 +24,10 @@ type assignmentsTable struct {
 	Type        postgres.ColumnString
 	CreatedAt   postgres.ColumnTimestampz
 	UpdatedAt   postgres.ColumnTimestampz
+	Draft       postgres.ColumnBool
+	PublishAt   postgres.ColumnTimestampz
+	PublishedAt postgres.ColumnTimestampz
+	NotifiedAt  postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -71,8 +75,12 @@ func newAssignmentsTableImpl(schemaName, tableName, alias string) assignmentsTab
 		TypeColumn        = postgres.StringColumn("type")
 		CreatedAtColumn   = postgres.TimestampzColumn("created_at")
 		UpdatedAtColumn   = postgres.TimestampzColumn("updated_at")
-		allColumns        = postgres.ColumnList{IDColumn, JournalIDColumn, DescriptionColumn, DeadlineColumn, TypeColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns    = postgres.ColumnList{JournalIDColumn, DescriptionColumn, DeadlineColumn, TypeColumn, CreatedAtColumn, UpdatedAtColumn}
+		DraftColumn       = postgres.BoolColumn("draft")
+		PublishAtColumn   = postgres.TimestampzColumn("publish_at")
+		PublishedAtColumn = postgres.TimestampzColumn("published_at")
+		NotifiedAtColumn  = postgres.TimestampzColumn("notified_at")
+		allColumns        = postgres.ColumnList{IDColumn, JournalIDColumn, DescriptionColumn, DeadlineColumn, TypeColumn, CreatedAtColumn, UpdatedAtColumn, DraftColumn, PublishAtColumn, PublishedAtColumn, NotifiedAtColumn}
+		mutableColumns    = postgres.ColumnList{JournalIDColumn, DescriptionColumn, DeadlineColumn, TypeColumn, CreatedAtColumn, UpdatedAtColumn, DraftColumn, PublishAtColumn, PublishedAtColumn, NotifiedAtColumn}
 	)
 
 	return assignmentsTable{
@@ -86,6 +94,10 @@ func newAssignmentsTableImpl(schemaName, tableName, alias string) assignmentsTab
 		Type:        TypeColumn,
 		CreatedAt:   CreatedAtColumn,
 		UpdatedAt:   UpdatedAtColumn,
+		Draft:       DraftColumn,
+		PublishAt:   PublishAtColumn,
+		PublishedAt: PublishedAtColumn,
+		NotifiedAt:  NotifiedAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -17,8 +17,10 @@ type classesTable struct {
 	postgres.Table
 
 	//Columns
-	ID   postgres.ColumnInteger
-	Name postgres.ColumnString
+	ID              postgres.ColumnInteger
+	Name            postgres.ColumnString
+	MaxTestsPerDay  postgres.ColumnInteger
+	MaxTestsPerWeek postgres.ColumnInteger
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -59,18 +61,22 @@ func newClassesTable(schemaName, tableName, alias string) *ClassesTable {
 
 func newClassesTableImpl(schemaName, tableName, alias string) classesTable {
 	var (
-		IDColumn       = postgres.IntegerColumn("id")
-		NameColumn     = postgres.StringColumn("name")
-		allColumns     = postgres.ColumnList{IDColumn, NameColumn}
-		mutableColumns = postgres.ColumnList{NameColumn}
+		IDColumn              = postgres.IntegerColumn("id")
+		NameColumn            = postgres.StringColumn("name")
+		MaxTestsPerDayColumn  = postgres.IntegerColumn("max_tests_per_day")
+		MaxTestsPerWeekColumn = postgres.IntegerColumn("max_tests_per_week")
+		allColumns            = postgres.ColumnList{IDColumn, NameColumn, MaxTestsPerDayColumn, MaxTestsPerWeekColumn}
+		mutableColumns        = postgres.ColumnList{NameColumn, MaxTestsPerDayColumn, MaxTestsPerWeekColumn}
 	)
 
 	return classesTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:   IDColumn,
-		Name: NameColumn,
+		ID:              IDColumn,
+		Name:            NameColumn,
+		MaxTestsPerDay:  MaxTestsPerDayColumn,
+		MaxTestsPerWeek: MaxTestsPerWeekColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
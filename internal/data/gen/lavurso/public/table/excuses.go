@@ -17,10 +17,14 @@ type excusesTable struct {
 	postgres.Table
 
 	//Columns
-	MarkID postgres.ColumnInteger
-	Excuse postgres.ColumnString
-	UserID postgres.ColumnInteger
-	At     postgres.ColumnTimestampz
+	MarkID        postgres.ColumnInteger
+	Excuse        postgres.ColumnString
+	UserID        postgres.ColumnInteger
+	At            postgres.ColumnTimestampz
+	Status        postgres.ColumnString
+	ReviewerID    postgres.ColumnInteger
+	ReviewComment postgres.ColumnString
+	ReviewedAt    postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -61,22 +65,30 @@ func newExcusesTable(schemaName, tableName, alias string) *ExcusesTable {
 
 func newExcusesTableImpl(schemaName, tableName, alias string) excusesTable {
 	var (
-		MarkIDColumn   = postgres.IntegerColumn("mark_id")
-		ExcuseColumn   = postgres.StringColumn("excuse")
-		UserIDColumn   = postgres.IntegerColumn("user_id")
-		AtColumn       = postgres.TimestampzColumn("at")
-		allColumns     = postgres.ColumnList{MarkIDColumn, ExcuseColumn, UserIDColumn, AtColumn}
-		mutableColumns = postgres.ColumnList{ExcuseColumn, UserIDColumn, AtColumn}
+		MarkIDColumn        = postgres.IntegerColumn("mark_id")
+		ExcuseColumn        = postgres.StringColumn("excuse")
+		UserIDColumn        = postgres.IntegerColumn("user_id")
+		AtColumn            = postgres.TimestampzColumn("at")
+		StatusColumn        = postgres.StringColumn("status")
+		ReviewerIDColumn    = postgres.IntegerColumn("reviewer_id")
+		ReviewCommentColumn = postgres.StringColumn("review_comment")
+		ReviewedAtColumn    = postgres.TimestampzColumn("reviewed_at")
+		allColumns          = postgres.ColumnList{MarkIDColumn, ExcuseColumn, UserIDColumn, AtColumn, StatusColumn, ReviewerIDColumn, ReviewCommentColumn, ReviewedAtColumn}
+		mutableColumns      = postgres.ColumnList{ExcuseColumn, UserIDColumn, AtColumn, StatusColumn, ReviewerIDColumn, ReviewCommentColumn, ReviewedAtColumn}
 	)
 
 	return excusesTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		MarkID: MarkIDColumn,
-		Excuse: ExcuseColumn,
-		UserID: UserIDColumn,
-		At:     AtColumn,
+		MarkID:        MarkIDColumn,
+		Excuse:        ExcuseColumn,
+		UserID:        UserIDColumn,
+		At:            AtColumn,
+		Status:        StatusColumn,
+		ReviewerID:    ReviewerIDColumn,
+		ReviewComment: ReviewCommentColumn,
+		ReviewedAt:    ReviewedAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
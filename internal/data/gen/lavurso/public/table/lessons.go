@@ -17,13 +17,16 @@ type lessonsTable struct {
 	postgres.Table
 
 	//Columns
-	ID          postgres.ColumnInteger
-	JournalID   postgres.ColumnInteger
-	Description postgres.ColumnString
-	Date        postgres.ColumnDate
-	Course      postgres.ColumnInteger
-	CreatedAt   postgres.ColumnTimestampz
-	UpdatedAt   postgres.ColumnTimestampz
+	ID              postgres.ColumnInteger
+	JournalID       postgres.ColumnInteger
+	Description     postgres.ColumnString
+	Date            postgres.ColumnDate
+	Course          postgres.ColumnInteger
+	CreatedAt       postgres.ColumnTimestampz
+	UpdatedAt       postgres.ColumnTimestampz
+	TimetableSlotID postgres.ColumnInteger
+	StartTime       postgres.ColumnTime
+	EndTime         postgres.ColumnTime
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -64,28 +67,34 @@ func newLessonsTable(schemaName, tableName, alias string) *LessonsTable {
 
 func newLessonsTableImpl(schemaName, tableName, alias string) lessonsTable {
 	var (
-		IDColumn          = postgres.IntegerColumn("id")
-		JournalIDColumn   = postgres.IntegerColumn("journal_id")
-		DescriptionColumn = postgres.StringColumn("description")
-		DateColumn        = postgres.DateColumn("date")
-		CourseColumn      = postgres.IntegerColumn("course")
-		CreatedAtColumn   = postgres.TimestampzColumn("created_at")
-		UpdatedAtColumn   = postgres.TimestampzColumn("updated_at")
-		allColumns        = postgres.ColumnList{IDColumn, JournalIDColumn, DescriptionColumn, DateColumn, CourseColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns    = postgres.ColumnList{JournalIDColumn, DescriptionColumn, DateColumn, CourseColumn, CreatedAtColumn, UpdatedAtColumn}
+		IDColumn              = postgres.IntegerColumn("id")
+		JournalIDColumn       = postgres.IntegerColumn("journal_id")
+		DescriptionColumn     = postgres.StringColumn("description")
+		DateColumn            = postgres.DateColumn("date")
+		CourseColumn          = postgres.IntegerColumn("course")
+		CreatedAtColumn       = postgres.TimestampzColumn("created_at")
+		UpdatedAtColumn       = postgres.TimestampzColumn("updated_at")
+		TimetableSlotIDColumn = postgres.IntegerColumn("timetable_slot_id")
+		StartTimeColumn       = postgres.TimeColumn("start_time")
+		EndTimeColumn         = postgres.TimeColumn("end_time")
+		allColumns            = postgres.ColumnList{IDColumn, JournalIDColumn, DescriptionColumn, DateColumn, CourseColumn, CreatedAtColumn, UpdatedAtColumn, TimetableSlotIDColumn, StartTimeColumn, EndTimeColumn}
+		mutableColumns        = postgres.ColumnList{JournalIDColumn, DescriptionColumn, DateColumn, CourseColumn, CreatedAtColumn, UpdatedAtColumn, TimetableSlotIDColumn, StartTimeColumn, EndTimeColumn}
 	)
 
 	return lessonsTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:          IDColumn,
-		JournalID:   JournalIDColumn,
-		Description: DescriptionColumn,
-		Date:        DateColumn,
-		Course:      CourseColumn,
-		CreatedAt:   CreatedAtColumn,
-		UpdatedAt:   UpdatedAtColumn,
+		ID:              IDColumn,
+		JournalID:       JournalIDColumn,
+		Description:     DescriptionColumn,
+		Date:            DateColumn,
+		Course:          CourseColumn,
+		CreatedAt:       CreatedAtColumn,
+		UpdatedAt:       UpdatedAtColumn,
+		TimetableSlotID: TimetableSlotIDColumn,
+		StartTime:       StartTimeColumn,
+		EndTime:         EndTimeColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
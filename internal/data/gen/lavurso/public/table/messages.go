@@ -24,6 +24,8 @@ type messagesTable struct {
 	Type      postgres.ColumnString
 	CreatedAt postgres.ColumnTimestampz
 	UpdatedAt postgres.ColumnTimestampz
+	HiddenAt  postgres.ColumnTimestampz
+	HiddenBy  postgres.ColumnInteger
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -71,8 +73,10 @@ func newMessagesTableImpl(schemaName, tableName, alias string) messagesTable {
 		TypeColumn      = postgres.StringColumn("type")
 		CreatedAtColumn = postgres.TimestampzColumn("created_at")
 		UpdatedAtColumn = postgres.TimestampzColumn("updated_at")
-		allColumns      = postgres.ColumnList{IDColumn, ThreadIDColumn, UserIDColumn, BodyColumn, TypeColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns  = postgres.ColumnList{ThreadIDColumn, UserIDColumn, BodyColumn, TypeColumn, CreatedAtColumn, UpdatedAtColumn}
+		HiddenAtColumn  = postgres.TimestampzColumn("hidden_at")
+		HiddenByColumn  = postgres.IntegerColumn("hidden_by")
+		allColumns      = postgres.ColumnList{IDColumn, ThreadIDColumn, UserIDColumn, BodyColumn, TypeColumn, CreatedAtColumn, UpdatedAtColumn, HiddenAtColumn, HiddenByColumn}
+		mutableColumns  = postgres.ColumnList{ThreadIDColumn, UserIDColumn, BodyColumn, TypeColumn, CreatedAtColumn, UpdatedAtColumn, HiddenAtColumn, HiddenByColumn}
 	)
 
 	return messagesTable{
@@ -86,6 +90,8 @@ func newMessagesTableImpl(schemaName, tableName, alias string) messagesTable {
 		Type:      TypeColumn,
 		CreatedAt: CreatedAtColumn,
 		UpdatedAt: UpdatedAtColumn,
+		HiddenAt:  HiddenAtColumn,
+		HiddenBy:  HiddenByColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -17,12 +17,13 @@ type threadsTable struct {
 	postgres.Table
 
 	//Columns
-	ID        postgres.ColumnInteger
-	UserID    postgres.ColumnInteger
-	Title     postgres.ColumnString
-	Locked    postgres.ColumnBool
-	CreatedAt postgres.ColumnTimestampz
-	UpdatedAt postgres.ColumnTimestampz
+	ID            postgres.ColumnInteger
+	UserID        postgres.ColumnInteger
+	Title         postgres.ColumnString
+	Locked        postgres.ColumnBool
+	CreatedAt     postgres.ColumnTimestampz
+	UpdatedAt     postgres.ColumnTimestampz
+	LockedByAdmin postgres.ColumnBool
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -63,26 +64,28 @@ func newThreadsTable(schemaName, tableName, alias string) *ThreadsTable {
 
 func newThreadsTableImpl(schemaName, tableName, alias string) threadsTable {
 	var (
-		IDColumn        = postgres.IntegerColumn("id")
-		UserIDColumn    = postgres.IntegerColumn("user_id")
-		TitleColumn     = postgres.StringColumn("title")
-		LockedColumn    = postgres.BoolColumn("locked")
-		CreatedAtColumn = postgres.TimestampzColumn("created_at")
-		UpdatedAtColumn = postgres.TimestampzColumn("updated_at")
-		allColumns      = postgres.ColumnList{IDColumn, UserIDColumn, TitleColumn, LockedColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns  = postgres.ColumnList{UserIDColumn, TitleColumn, LockedColumn, CreatedAtColumn, UpdatedAtColumn}
+		IDColumn            = postgres.IntegerColumn("id")
+		UserIDColumn        = postgres.IntegerColumn("user_id")
+		TitleColumn         = postgres.StringColumn("title")
+		LockedColumn        = postgres.BoolColumn("locked")
+		CreatedAtColumn     = postgres.TimestampzColumn("created_at")
+		UpdatedAtColumn     = postgres.TimestampzColumn("updated_at")
+		LockedByAdminColumn = postgres.BoolColumn("locked_by_admin")
+		allColumns          = postgres.ColumnList{IDColumn, UserIDColumn, TitleColumn, LockedColumn, CreatedAtColumn, UpdatedAtColumn, LockedByAdminColumn}
+		mutableColumns      = postgres.ColumnList{UserIDColumn, TitleColumn, LockedColumn, CreatedAtColumn, UpdatedAtColumn, LockedByAdminColumn}
 	)
 
 	return threadsTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:        IDColumn,
-		UserID:    UserIDColumn,
-		Title:     TitleColumn,
-		Locked:    LockedColumn,
-		CreatedAt: CreatedAtColumn,
-		UpdatedAt: UpdatedAtColumn,
+		ID:            IDColumn,
+		UserID:        UserIDColumn,
+		Title:         TitleColumn,
+		Locked:        LockedColumn,
+		CreatedAt:     CreatedAtColumn,
+		UpdatedAt:     UpdatedAtColumn,
+		LockedByAdmin: LockedByAdminColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
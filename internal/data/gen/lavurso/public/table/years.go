@@ -20,6 +20,8 @@ type yearsTable struct {
 	ID          postgres.ColumnInteger
 	DisplayName postgres.ColumnString
 	Current     postgres.ColumnBool
+	StartDate   postgres.ColumnDate
+	EndDate     postgres.ColumnDate
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -63,8 +65,10 @@ func newYearsTableImpl(schemaName, tableName, alias string) yearsTable {
 		IDColumn          = postgres.IntegerColumn("id")
 		DisplayNameColumn = postgres.StringColumn("display_name")
 		CurrentColumn     = postgres.BoolColumn("current")
-		allColumns        = postgres.ColumnList{IDColumn, DisplayNameColumn, CurrentColumn}
-		mutableColumns    = postgres.ColumnList{DisplayNameColumn, CurrentColumn}
+		StartDateColumn   = postgres.DateColumn("start_date")
+		EndDateColumn     = postgres.DateColumn("end_date")
+		allColumns        = postgres.ColumnList{IDColumn, DisplayNameColumn, CurrentColumn, StartDateColumn, EndDateColumn}
+		mutableColumns    = postgres.ColumnList{DisplayNameColumn, CurrentColumn, StartDateColumn, EndDateColumn}
 	)
 
 	return yearsTable{
@@ -74,6 +78,8 @@ func newYearsTableImpl(schemaName, tableName, alias string) yearsTable {
 		ID:          IDColumn,
 		DisplayName: DisplayNameColumn,
 		Current:     CurrentColumn,
+		StartDate:   StartDateColumn,
+		EndDate:     EndDateColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -0,0 +1,194 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNoSuchJournalGroup     = errors.New("no such journal group")
+	ErrJournalGroupNameExists = errors.New("journal already has a group with that name")
+	ErrJournalGroupInUse      = errors.New("journal group is targeted by assignments")
+)
+
+type JournalGroup = model.JournalGroups
+
+type JournalGroupExt struct {
+	JournalGroup
+	Students []*User `json:"students,omitempty" alias:"group_students"`
+}
+
+type JournalGroupModel struct {
+	DB *sql.DB
+}
+
+func (m JournalGroupModel) getJournalGroups(where postgres.BoolExpression) ([]*JournalGroupExt, error) {
+	student := table.Users.AS("group_students")
+
+	query := postgres.SELECT(table.JournalGroups.AllColumns, student.ID, student.Name, student.Role).
+		FROM(table.JournalGroups.
+			LEFT_JOIN(table.JournalGroupsStudents, table.JournalGroupsStudents.GroupID.EQ(table.JournalGroups.ID)).
+			LEFT_JOIN(student, student.ID.EQ(table.JournalGroupsStudents.StudentID))).
+		WHERE(where).
+		ORDER_BY(table.JournalGroups.Name.ASC(), student.Name.ASC())
+
+	var groups []*JournalGroupExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (m JournalGroupModel) GetJournalGroupByID(groupID int) (*JournalGroupExt, error) {
+	groups, err := m.getJournalGroups(table.JournalGroups.ID.EQ(helpers.PostgresInt(groupID)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 0 {
+		return nil, ErrNoSuchJournalGroup
+	}
+
+	return groups[0], nil
+}
+
+func (m JournalGroupModel) GetJournalGroupsForJournal(journalID int) ([]*JournalGroupExt, error) {
+	return m.getJournalGroups(table.JournalGroups.JournalID.EQ(helpers.PostgresInt(journalID)))
+}
+
+func (m JournalGroupModel) GetJournalGroupIDsForJournal(journalID int) ([]int, error) {
+	query := postgres.SELECT(table.JournalGroups.ID).
+		FROM(table.JournalGroups).
+		WHERE(table.JournalGroups.JournalID.EQ(helpers.PostgresInt(journalID)))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (m JournalGroupModel) InsertJournalGroup(g *JournalGroup) error {
+	stmt := table.JournalGroups.INSERT(table.JournalGroups.MutableColumns).
+		MODEL(g).
+		RETURNING(table.JournalGroups.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, g)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrJournalGroupNameExists
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m JournalGroupModel) UpdateJournalGroup(g *JournalGroup) error {
+	stmt := table.JournalGroups.UPDATE(table.JournalGroups.Name).
+		MODEL(g).
+		WHERE(table.JournalGroups.ID.EQ(helpers.PostgresInt(g.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrJournalGroupNameExists
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m JournalGroupModel) DeleteJournalGroup(groupID int) error {
+	stmt := table.JournalGroups.DELETE().
+		WHERE(table.JournalGroups.ID.EQ(helpers.PostgresInt(groupID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return ErrJournalGroupInUse
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaces the group's members with the given students
+func (m JournalGroupModel) SetJournalGroupStudents(groupID int, studentIDs []int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var gss []model.JournalGroupsStudents
+	var sids []postgres.Expression
+	for _, sid := range studentIDs {
+		sid := sid
+		gss = append(gss, model.JournalGroupsStudents{
+			GroupID:   &groupID,
+			StudentID: &sid,
+		})
+		sids = append(sids, helpers.PostgresInt(sid))
+	}
+
+	var deletestmt postgres.DeleteStatement
+
+	if gss != nil {
+		insertstmt := table.JournalGroupsStudents.INSERT(table.JournalGroupsStudents.AllColumns).
+			MODELS(gss).
+			ON_CONFLICT(table.JournalGroupsStudents.AllColumns...).DO_NOTHING()
+
+		_, err := insertstmt.ExecContext(ctx, m.DB)
+		if err != nil {
+			return err
+		}
+
+		deletestmt = table.JournalGroupsStudents.DELETE().WHERE(table.JournalGroupsStudents.StudentID.NOT_IN(sids...).
+			AND(table.JournalGroupsStudents.GroupID.EQ(helpers.PostgresInt(groupID))))
+	} else {
+		deletestmt = table.JournalGroupsStudents.DELETE().WHERE(table.JournalGroupsStudents.GroupID.EQ(helpers.PostgresInt(groupID)))
+	}
+
+	_, err := deletestmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
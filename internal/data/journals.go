@@ -371,26 +371,47 @@ func (m JournalModel) GetStudentIDsForJournal(journalID int) ([]int, error) {
 	return result, nil
 }
 
-// todo: not needed?
-// func (m JournalModel) DoesParentHaveChildInJournal(parentID, journalID int) (bool, error) {
-// 	query := postgres.SELECT(postgres.COUNT(postgres.Int32(1))).
-// 		FROM(table.ParentsChildren.
-// 			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.StudentID.EQ(table.ParentsChildren.ChildID))).
-// 		WHERE(table.ParentsChildren.ParentID.EQ(helpers.PostgresInt(parentID)).
-// 			AND(table.StudentsJournals.JournalID.EQ(helpers.PostgresInt(journalID))))
-
-// 	var result []int
-
-// 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-// 	defer cancel()
-
-// 	err := query.QueryContext(ctx, m.DB, &result)
-// 	if err != nil {
-// 		return false, err
-// 	}
-
-// 	return result[0] > 0, nil
-// }
+func (m JournalModel) DoesParentHaveChildInJournal(parentID, journalID int) (bool, error) {
+	query := postgres.SELECT(postgres.COUNT(postgres.Int32(1))).
+		FROM(table.ParentsChildren.
+			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.StudentID.EQ(table.ParentsChildren.ChildID))).
+		WHERE(table.ParentsChildren.ParentID.EQ(helpers.PostgresInt(parentID)).
+			AND(table.StudentsJournals.JournalID.EQ(helpers.PostgresInt(journalID))))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result[0] > 0, nil
+}
+
+// whether the teacher is class teacher of any of the students in the journal
+func (m JournalModel) IsClassTeacherInJournal(teacherID, journalID int) (bool, error) {
+	query := postgres.SELECT(postgres.COUNT(postgres.Int32(1))).
+		FROM(table.StudentsJournals.
+			INNER_JOIN(table.Users, table.Users.ID.EQ(table.StudentsJournals.StudentID)).
+			INNER_JOIN(table.TeachersClasses, table.TeachersClasses.ClassID.EQ(table.Users.ClassID))).
+		WHERE(table.StudentsJournals.JournalID.EQ(helpers.PostgresInt(journalID)).
+			AND(table.TeachersClasses.TeacherID.EQ(helpers.PostgresInt(teacherID))))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result[0] > 0, nil
+}
 
 func (m JournalModel) SetJournalLastUpdated(journalID int) error {
 	stmt := table.Journals.UPDATE(table.Journals.LastUpdated).
@@ -24,6 +24,7 @@ type LessonExt struct {
 	Lesson
 	Journal *Journal   `json:"journal,omitempty"`
 	Subject *Subject   `json:"subject,omitempty"`
+	Period  *Period    `json:"period,omitempty"`
 	Marks   []*MarkExt `json:"marks,omitempty"`
 }
 
@@ -75,7 +76,7 @@ func (m LessonModel) GetLessonByID(lessonID int) (*LessonExt, error) {
 }
 
 func (m LessonModel) UpdateLesson(l *LessonExt) error {
-	stmt := table.Lessons.UPDATE(table.Lessons.Description, table.Lessons.Date, table.Lessons.UpdatedAt).
+	stmt := table.Lessons.UPDATE(table.Lessons.Description, table.Lessons.Date, table.Lessons.StartTime, table.Lessons.EndTime, table.Lessons.UpdatedAt).
 		MODEL(l).
 		WHERE(table.Lessons.ID.EQ(helpers.PostgresInt(l.ID)))
 
@@ -162,11 +163,13 @@ func (m LessonModel) GetLessonsAndStudentMarksByJournalID(studentID, journalID,
 }
 
 func (m LessonModel) GetLatestLessonsForStudent(studentID int, from, until *types.Date) ([]*LessonExt, error) {
-	query := postgres.SELECT(table.Lessons.AllColumns, table.Subjects.AllColumns).
+	query := postgres.SELECT(table.Lessons.AllColumns, table.Subjects.AllColumns, table.Periods.AllColumns).
 		FROM(table.Lessons.
 			INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.Lessons.JournalID)).
 			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Journals.ID)).
-			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)))
+			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)).
+			LEFT_JOIN(table.TimetableSlots, table.TimetableSlots.ID.EQ(table.Lessons.TimetableSlotID)).
+			LEFT_JOIN(table.Periods, table.Periods.ID.EQ(table.TimetableSlots.PeriodID)))
 
 	if until != nil {
 		query = query.WHERE(postgres.AND(
@@ -29,6 +29,7 @@ type MarkExt struct {
 	Excuse  *ExcuseExt  `json:"excuse,omitempty"`
 	Teacher *User       `json:"teacher,omitempty" alias:"teacher"`
 	Journal *JournalExt `json:"journal,omitempty"`
+	Student *User       `json:"student,omitempty" alias:"student"`
 }
 
 type MinimalMark struct {
@@ -52,8 +53,9 @@ type LessonMarks struct {
 
 type LessonStudent struct {
 	UserExt
-	Lesson LessonMarks    `json:"lesson"`
-	Marks  []*MinimalMark `json:"marks,omitempty"`
+	Lesson        LessonMarks    `json:"lesson"`
+	Marks         []*MinimalMark `json:"marks,omitempty"`
+	AbsenceNotice *AbsenceNotice `json:"absence_notice,omitempty"`
 }
 
 type StudentWithLowerMarks struct {
@@ -283,6 +285,22 @@ func (m MarkModel) InsertMarks(tx *sql.Tx, marks []*Mark) error {
 	return nil
 }
 
+func (m MarkModel) InsertMark(tx *sql.Tx, mark *Mark) error {
+	stmt := table.Marks.INSERT(table.Marks.MutableColumns).
+		MODEL(mark).
+		RETURNING(table.Marks.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, tx, mark)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m MarkModel) UpdateMarks(tx *sql.Tx, marks []*Mark) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -363,6 +381,8 @@ func (m MarkModel) DeleteMarksByStudentIDType(tx *sql.Tx, l []MarkByLessonStuden
 }
 
 func (m MarkModel) GetStudentsMarksForLesson(lessonID int) ([]*LessonStudent, error) {
+	notice := table.AbsenceNotices.AS("notice")
+
 	query := postgres.SELECT(
 		table.Users.ID, table.Users.Name, table.Marks.ID,
 		postgres.CASE().
@@ -370,12 +390,25 @@ func (m MarkModel) GetStudentsMarksForLesson(lessonID int) ([]*LessonStudent, er
 			THEN(postgres.String("grade")).
 			ELSE(table.Marks.Type).AS("marks.type"),
 		table.Marks.Comment, table.Grades.Identifier,
+		table.AbsenceNotices.AllColumns,
 	).
 		FROM(table.Lessons.
 			INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(table.Lessons.JournalID)).
 			INNER_JOIN(table.Users, table.Users.ID.EQ(table.StudentsJournals.StudentID)).
 			LEFT_JOIN(table.Marks, table.Marks.UserID.EQ(table.Users.ID).AND(table.Marks.LessonID.EQ(table.Lessons.ID))).
-			LEFT_JOIN(table.Grades, table.Grades.ID.EQ(table.Marks.GradeID))).
+			LEFT_JOIN(table.Grades, table.Grades.ID.EQ(table.Marks.GradeID)).
+			// a student may have overlapping notices, show the one the absences are excused with
+			LEFT_JOIN(table.AbsenceNotices, table.AbsenceNotices.ID.EQ(postgres.IntExp(
+				postgres.SELECT(notice.ID).
+					FROM(notice).
+					WHERE(postgres.AND(
+						notice.StudentID.EQ(table.Users.ID),
+						notice.StartDate.LT_EQ(table.Lessons.Date),
+						notice.EndDate.GT_EQ(table.Lessons.Date),
+					)).
+					ORDER_BY(notice.CreatedAt.ASC(), notice.ID.ASC()).
+					LIMIT(1),
+			)))).
 		WHERE(table.Lessons.ID.EQ(helpers.PostgresInt(lessonID))).
 		ORDER_BY(table.Users.Name.ASC(), table.Marks.CreatedAt.ASC())
 
@@ -446,7 +479,8 @@ func (m MarkModel) GetStudentsMarksForCourse(journalID, course int) ([]*StudentW
 			LEFT_JOIN(lessonMarksGrade, lessonMarksGrade.ID.EQ(lessonMarks.GradeID)).
 			LEFT_JOIN(lessonMarksTeacher, lessonMarksTeacher.ID.EQ(lessonMarks.TeacherID)).
 			LEFT_JOIN(lesson, lesson.ID.EQ(lessonMarks.LessonID)).
-			LEFT_JOIN(table.Excuses, table.Excuses.MarkID.EQ(lessonMarks.ID))).
+			LEFT_JOIN(table.Excuses, table.Excuses.MarkID.EQ(lessonMarks.ID).
+				AND(table.Excuses.Status.EQ(postgres.String(ExcuseAccepted))))).
 		ORDER_BY(table.Users.Name.ASC(), courseMarks.CreatedAt.ASC(), lesson.Date.DESC())
 
 	var students []*StudentWithLowerMarks
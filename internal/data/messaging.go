@@ -3,7 +3,10 @@ package data
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
@@ -18,7 +21,11 @@ var (
 	ErrNoSuchMessage          = errors.New("no such message")
 	ErrThreadAlreadyLocked    = errors.New("thread already locked")
 	ErrThreadAlreadyUnlocked  = errors.New("thread already unlocked")
+	ErrThreadLocked           = errors.New("thread is locked")
 	ErrCantDeleteFirstMessage = errors.New("can't delete first message of thread")
+	ErrInvalidCursor          = errors.New("invalid cursor")
+	ErrMessageAlreadyHidden   = errors.New("message already hidden")
+	ErrMessageNotHidden       = errors.New("message not hidden")
 )
 
 const (
@@ -33,19 +40,185 @@ type ThreadExt struct {
 	User         *User `json:"user"`
 	Read         *bool `json:"read,omitempty"`
 	MessageCount *int  `json:"message_count,omitempty"`
+	Archived     *bool `json:"archived,omitempty"`
+	Muted        *bool `json:"muted,omitempty"`
+	Pinned       *bool `json:"pinned,omitempty"`
+}
+
+// a user's own archived, muted and pinned flags for a thread
+type ThreadState = model.ThreadsStates
+
+// which of the user's threads are listed, archived ones are left out unless asked for
+const (
+	ThreadFilterArchived = "archived"
+	ThreadFilterMuted    = "muted"
+	ThreadFilterPinned   = "pinned"
+	ThreadFilterAll      = "all"
+)
+
+var ThreadFilters = []string{ThreadFilterArchived, ThreadFilterMuted, ThreadFilterPinned, ThreadFilterAll}
+
+const (
+	ThreadRuleRole            = "role"
+	ThreadRuleClassParents    = "class_parents"
+	ThreadRuleClassTeachers   = "class_teachers"
+	ThreadRuleJournalStudents = "journal_students"
+)
+
+type ThreadRule = model.ThreadsRules
+
+type ThreadRuleExt struct {
+	ThreadRule
+	Class   *Class   `json:"class,omitempty"`
+	Journal *Journal `json:"journal,omitempty"`
 }
 
 type Message = model.Messages
 
 type MessageExt struct {
 	Message
-	User *User `json:"user"`
+	User   *User `json:"user"`
+	Edited *bool `json:"edited,omitempty"`
+}
+
+// a previous version of a message's body, EditedAt is when it was replaced
+type MessageEdit = model.MessagesEdits
+
+// position in the thread list, pinned threads come first and then threads are ordered by when they were last updated
+type ThreadCursor struct {
+	Pinned    bool
+	UpdatedAt time.Time
+	ID        int
+}
+
+func (c ThreadCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatBool(c.Pinned) + "," + c.UpdatedAt.Format(time.RFC3339Nano) + "," + strconv.Itoa(c.ID)))
+}
+
+func ParseThreadCursor(s string) (*ThreadCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(decoded), ",")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCursor
+	}
+
+	var c ThreadCursor
+
+	c.Pinned, err = strconv.ParseBool(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c.UpdatedAt, err = time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c.ID, err = strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+type MessageRead = model.MessagesRead
+
+// a thread member and the last message they have read
+type ThreadReceipt struct {
+	User
+	LastReadMessageID *int       `json:"last_read_message_id" alias:"messages_read.message_id"`
+	ReadAt            *time.Time `json:"read_at" alias:"messages_read.read_at"`
 }
 
 type MessagingModel struct {
 	DB *sql.DB
 }
 
+// ids of the threads the user is in, directly, through a group or by one of the threads' rules.
+// Rules are resolved at query time so they follow changes in classes and journals
+func userThreadIDs(userID postgres.IntegerExpression) postgres.Expression {
+	rule := table.ThreadsRules
+
+	return postgres.UNION(
+		postgres.SELECT(table.ThreadsRecipients.ThreadID).
+			FROM(table.ThreadsRecipients).
+			WHERE(table.ThreadsRecipients.UserID.EQ(userID)),
+		postgres.SELECT(table.ThreadsRecipients.ThreadID).
+			FROM(table.ThreadsRecipients.
+				INNER_JOIN(table.UsersGroups, table.UsersGroups.GroupID.EQ(table.ThreadsRecipients.GroupID))).
+			WHERE(table.UsersGroups.UserID.EQ(userID)),
+		postgres.SELECT(rule.ThreadID).
+			FROM(rule.
+				INNER_JOIN(table.Users, table.Users.Role.EQ(rule.Role))).
+			WHERE(rule.Type.EQ(postgres.String(ThreadRuleRole)).
+				AND(table.Users.ID.EQ(userID))),
+		postgres.SELECT(rule.ThreadID).
+			FROM(rule.
+				INNER_JOIN(table.Users, table.Users.ClassID.EQ(rule.ClassID)).
+				INNER_JOIN(table.ParentsChildren, table.ParentsChildren.ChildID.EQ(table.Users.ID))).
+			WHERE(rule.Type.EQ(postgres.String(ThreadRuleClassParents)).
+				AND(table.ParentsChildren.ParentID.EQ(userID))),
+		postgres.SELECT(rule.ThreadID).
+			FROM(rule.
+				INNER_JOIN(table.TeachersClasses, table.TeachersClasses.ClassID.EQ(rule.ClassID))).
+			WHERE(rule.Type.EQ(postgres.String(ThreadRuleClassTeachers)).
+				AND(table.TeachersClasses.TeacherID.EQ(userID))),
+		postgres.SELECT(rule.ThreadID).
+			FROM(rule.
+				INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(rule.JournalID))).
+			WHERE(rule.Type.EQ(postgres.String(ThreadRuleJournalStudents)).
+				AND(table.StudentsJournals.StudentID.EQ(userID))),
+	)
+}
+
+// ids of the users in the thread, resolved the same way as userThreadIDs but from the thread's side
+func threadMemberIDs(threadID postgres.IntegerExpression) postgres.Expression {
+	rule := table.ThreadsRules
+
+	return postgres.UNION(
+		postgres.SELECT(table.ThreadsRecipients.UserID).
+			FROM(table.ThreadsRecipients).
+			WHERE(table.ThreadsRecipients.ThreadID.EQ(threadID).
+				AND(table.ThreadsRecipients.UserID.IS_NOT_NULL())),
+		postgres.SELECT(table.UsersGroups.UserID).
+			FROM(table.ThreadsRecipients.
+				INNER_JOIN(table.UsersGroups, table.UsersGroups.GroupID.EQ(table.ThreadsRecipients.GroupID))).
+			WHERE(table.ThreadsRecipients.ThreadID.EQ(threadID)),
+		postgres.SELECT(table.Users.ID).
+			FROM(rule.
+				INNER_JOIN(table.Users, table.Users.Role.EQ(rule.Role))).
+			WHERE(rule.ThreadID.EQ(threadID).
+				AND(rule.Type.EQ(postgres.String(ThreadRuleRole)))),
+		postgres.SELECT(table.ParentsChildren.ParentID).
+			FROM(rule.
+				INNER_JOIN(table.Users, table.Users.ClassID.EQ(rule.ClassID)).
+				INNER_JOIN(table.ParentsChildren, table.ParentsChildren.ChildID.EQ(table.Users.ID))).
+			WHERE(rule.ThreadID.EQ(threadID).
+				AND(rule.Type.EQ(postgres.String(ThreadRuleClassParents)))),
+		postgres.SELECT(table.TeachersClasses.TeacherID).
+			FROM(rule.
+				INNER_JOIN(table.TeachersClasses, table.TeachersClasses.ClassID.EQ(rule.ClassID))).
+			WHERE(rule.ThreadID.EQ(threadID).
+				AND(rule.Type.EQ(postgres.String(ThreadRuleClassTeachers)))),
+		postgres.SELECT(table.StudentsJournals.StudentID).
+			FROM(rule.
+				INNER_JOIN(table.StudentsJournals, table.StudentsJournals.JournalID.EQ(rule.JournalID))).
+			WHERE(rule.ThreadID.EQ(threadID).
+				AND(rule.Type.EQ(postgres.String(ThreadRuleJournalStudents)))),
+	)
+}
+
+// whether the user is in the thread, userID should not depend on the outer query
+// so the user's threads are looked up once
+func threadIncludesUser(threadID, userID postgres.IntegerExpression) postgres.BoolExpression {
+	return threadID.IN(userThreadIDs(userID))
+}
+
 func (m MessagingModel) GetThreadByID(threadID int) (*ThreadExt, error) {
 	query := postgres.SELECT(table.Threads.AllColumns, table.Users.ID, table.Users.Name, table.Users.Role).
 		FROM(table.Threads.
@@ -213,6 +386,56 @@ func (m MessagingModel) GetUsersInThread(threadID int) ([]*UserExt, error) {
 	return users, nil
 }
 
+// ids of users in the thread, directly, through a group or by a rule
+func (m MessagingModel) GetUserIDsInThread(threadID int) ([]int, error) {
+	query := postgres.SELECT(table.Users.ID).
+		FROM(table.Users).
+		WHERE(table.Users.ID.IN(threadMemberIDs(helpers.PostgresInt(threadID))))
+
+	var userIDs []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// the ones of userIDs that are in the thread, for a few users without listing every member
+func (m MessagingModel) FilterUserIDsInThread(threadID int, userIDs []int) ([]int, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var uids []postgres.Expression
+	for _, uid := range userIDs {
+		uids = append(uids, helpers.PostgresInt(uid))
+	}
+
+	member := table.Users.AS("member")
+
+	query := postgres.SELECT(member.ID).
+		FROM(member).
+		WHERE(member.ID.IN(uids...).
+			AND(threadIncludesUser(helpers.PostgresInt(threadID), member.ID)))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (m MessagingModel) GetGroupsInThread(threadID int) ([]*Group, error) {
 	query := postgres.SELECT(table.Groups.ID, table.Groups.Name).
 		FROM(table.Groups.
@@ -233,6 +456,68 @@ func (m MessagingModel) GetGroupsInThread(threadID int) ([]*Group, error) {
 	return groups, nil
 }
 
+func (m MessagingModel) AddRulesToThread(threadID int, rules []*ThreadRule) error {
+	for _, r := range rules {
+		r.ThreadID = &threadID
+	}
+
+	stmt := table.ThreadsRules.INSERT(table.ThreadsRules.MutableColumns).
+		MODELS(rules).
+		ON_CONFLICT(table.ThreadsRules.MutableColumns...).DO_NOTHING()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m MessagingModel) RemoveRulesFromThread(threadID int, ruleIDs []int) error {
+	var rids []postgres.Expression
+	for _, rid := range ruleIDs {
+		rids = append(rids, helpers.PostgresInt(rid))
+	}
+
+	stmt := table.ThreadsRules.DELETE().
+		WHERE(table.ThreadsRules.ID.IN(rids...).
+			AND(table.ThreadsRules.ThreadID.EQ(helpers.PostgresInt(threadID))))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m MessagingModel) GetRulesInThread(threadID int) ([]*ThreadRuleExt, error) {
+	query := postgres.SELECT(table.ThreadsRules.AllColumns, table.Classes.ID, table.Classes.Name, table.Journals.ID, table.Journals.Name).
+		FROM(table.ThreadsRules.
+			LEFT_JOIN(table.Classes, table.Classes.ID.EQ(table.ThreadsRules.ClassID)).
+			LEFT_JOIN(table.Journals, table.Journals.ID.EQ(table.ThreadsRules.JournalID))).
+		WHERE(table.ThreadsRules.ThreadID.EQ(helpers.PostgresInt(threadID))).
+		ORDER_BY(table.ThreadsRules.ID.ASC())
+
+	var rules []*ThreadRuleExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
 func (m MessagingModel) GetMessageByID(messageID int) (*Message, error) {
 	query := postgres.SELECT(table.Messages.AllColumns).
 		FROM(table.Messages).
@@ -272,22 +557,41 @@ func (m MessagingModel) InsertMessage(ms *Message) error {
 	return nil
 }
 
+// deletes the message, readers that had read up to it are moved back to the message before it
 func (m MessagingModel) DeleteMessage(messageID int) error {
-	stmt := table.Messages.DELETE().
-		WHERE(table.Messages.ID.EQ(helpers.PostgresInt(messageID)))
-
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := stmt.ExecContext(ctx, m.DB)
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	previous := postgres.SELECT(postgres.MAX(table.Messages.ID)).
+		FROM(table.Messages).
+		WHERE(table.Messages.ThreadID.EQ(table.MessagesRead.ThreadID).
+			AND(table.Messages.ID.LT(helpers.PostgresInt(messageID))))
+
+	_, err = table.MessagesRead.UPDATE().
+		SET(table.MessagesRead.MessageID.SET(postgres.IntExp(previous))).
+		WHERE(table.MessagesRead.MessageID.EQ(helpers.PostgresInt(messageID))).
+		ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = table.Messages.DELETE().
+		WHERE(table.Messages.ID.EQ(helpers.PostgresInt(messageID))).
+		ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (m MessagingModel) UpdateMessage(ms *Message) error {
+func (m MessagingModel) UpdateMessage(tx *sql.Tx, ms *Message) error {
 	stmt := table.Messages.UPDATE(table.Messages.Body, table.Messages.UpdatedAt).
 		MODEL(ms).
 		WHERE(table.Messages.ID.EQ(helpers.PostgresInt(ms.ID)))
@@ -295,7 +599,7 @@ func (m MessagingModel) UpdateMessage(ms *Message) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := stmt.ExecContext(ctx, m.DB)
+	_, err := stmt.ExecContext(ctx, tx)
 	if err != nil {
 		return err
 	}
@@ -303,12 +607,93 @@ func (m MessagingModel) UpdateMessage(ms *Message) error {
 	return nil
 }
 
-func (m MessagingModel) GetAllMessagesByThreadID(threadID int) ([]*MessageExt, error) {
-	query := postgres.SELECT(table.Messages.AllColumns, table.Users.ID, table.Users.Name, table.Users.Role).
+func (m MessagingModel) InsertMessageEdit(tx *sql.Tx, e *MessageEdit) error {
+	stmt := table.MessagesEdits.INSERT(table.MessagesEdits.MutableColumns).
+		MODEL(e).
+		RETURNING(table.MessagesEdits.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// previous bodies of the message, oldest first
+func (m MessagingModel) GetMessageEdits(messageID int) ([]*MessageEdit, error) {
+	query := postgres.SELECT(table.MessagesEdits.AllColumns).
+		FROM(table.MessagesEdits).
+		WHERE(table.MessagesEdits.MessageID.EQ(helpers.PostgresInt(messageID))).
+		ORDER_BY(table.MessagesEdits.EditedAt.ASC(), table.MessagesEdits.ID.ASC())
+
+	var edits []*MessageEdit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &edits)
+	if err != nil {
+		return nil, err
+	}
+
+	return edits, nil
+}
+
+// hides the message for everyone but administrators, a nil hiddenBy shows it again
+func (m MessagingModel) SetMessageHidden(tx *sql.Tx, messageID int, hiddenBy *int) error {
+	var hiddenAt *time.Time
+	if hiddenBy != nil {
+		hiddenAt = helpers.ToPtr(time.Now().UTC())
+	}
+
+	stmt := table.Messages.UPDATE(table.Messages.HiddenAt, table.Messages.HiddenBy).
+		MODEL(&Message{HiddenAt: hiddenAt, HiddenBy: hiddenBy}).
+		WHERE(table.Messages.ID.EQ(helpers.PostgresInt(messageID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// gets up to limit messages of the thread, the latest ones or the ones right before or after the given message,
+// always in chronological order
+func (m MessagingModel) GetMessagesByThreadID(threadID int, before, after *Message, limit int) ([]*MessageExt, error) {
+	where := table.Messages.ThreadID.EQ(helpers.PostgresInt(threadID))
+	order := []postgres.OrderByClause{table.Messages.CreatedAt.DESC(), table.Messages.ID.DESC()}
+
+	switch {
+	case after != nil:
+		where = where.AND(table.Messages.CreatedAt.GT(postgres.TimestampzT(*after.CreatedAt)).
+			OR(table.Messages.CreatedAt.EQ(postgres.TimestampzT(*after.CreatedAt)).
+				AND(table.Messages.ID.GT(helpers.PostgresInt(after.ID)))))
+		order = []postgres.OrderByClause{table.Messages.CreatedAt.ASC(), table.Messages.ID.ASC()}
+	case before != nil:
+		where = where.AND(table.Messages.CreatedAt.LT(postgres.TimestampzT(*before.CreatedAt)).
+			OR(table.Messages.CreatedAt.EQ(postgres.TimestampzT(*before.CreatedAt)).
+				AND(table.Messages.ID.LT(helpers.PostgresInt(before.ID)))))
+	}
+
+	query := postgres.SELECT(table.Messages.AllColumns, table.Users.ID, table.Users.Name, table.Users.Role,
+		postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.MessagesEdits).
+				WHERE(table.MessagesEdits.MessageID.EQ(table.Messages.ID)),
+		).AS("messageext.edited")).
 		FROM(table.Messages.
 			INNER_JOIN(table.Users, table.Users.ID.EQ(table.Messages.UserID))).
-		WHERE(table.Messages.ThreadID.EQ(helpers.PostgresInt(threadID))).
-		ORDER_BY(table.Messages.CreatedAt.ASC())
+		WHERE(where).
+		ORDER_BY(order...).
+		LIMIT(int64(limit))
 
 	var messages []*MessageExt
 
@@ -320,32 +705,64 @@ func (m MessagingModel) GetAllMessagesByThreadID(threadID int) ([]*MessageExt, e
 		return nil, err
 	}
 
+	if after == nil {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+
 	return messages, nil
 }
 
-func (m MessagingModel) GetThreadsForUser(userID int, search string) ([]*ThreadExt, error) {
+// gets up to limit threads of the user matching the filter, starting after the cursor if one is given.
+// The search uses the text search configuration language, like MessageSearch.Language
+func (m MessagingModel) GetThreadsForUser(userID int, search, language, filter string, cursor *ThreadCursor, limit int) ([]*ThreadExt, error) {
 	uid := helpers.PostgresInt(userID)
 
 	from := table.Threads.
-		INNER_JOIN(table.ThreadsRecipients, table.ThreadsRecipients.ThreadID.EQ(table.Threads.ID)).
-		LEFT_JOIN(table.UsersGroups, table.UsersGroups.GroupID.EQ(table.ThreadsRecipients.GroupID).
-			AND(table.UsersGroups.UserID.EQ(uid))).
 		LEFT_JOIN(table.ThreadsRead, table.ThreadsRead.ThreadID.EQ(table.Threads.ID).
 			AND(table.ThreadsRead.UserID.EQ(uid))).
+		LEFT_JOIN(table.ThreadsStates, table.ThreadsStates.ThreadID.EQ(table.Threads.ID).
+			AND(table.ThreadsStates.UserID.EQ(uid))).
 		INNER_JOIN(table.Users, table.Users.ID.EQ(table.Threads.UserID))
 
-	var where postgres.BoolExpression
+	where := threadIncludesUser(table.Threads.ID, uid)
+
+	switch filter {
+	case ThreadFilterArchived:
+		where = where.AND(table.ThreadsStates.Archived.IS_TRUE())
+	case ThreadFilterMuted:
+		where = where.AND(table.ThreadsStates.Muted.IS_TRUE())
+	case ThreadFilterPinned:
+		where = where.AND(table.ThreadsStates.Pinned.IS_TRUE())
+	case ThreadFilterAll:
+	default:
+		where = where.AND(table.ThreadsStates.Archived.IS_NOT_TRUE())
+	}
+
 	if search != "" {
-		from = from.LEFT_JOIN(table.Messages, table.Messages.ThreadID.EQ(table.Threads.ID))
-		where = postgres.AND(
-			table.ThreadsRecipients.UserID.EQ(uid).OR(table.UsersGroups.UserID.EQ(uid)),
-			postgres.CAST(
-				postgres.Raw("(to_tsvector('simple', threads.title) @@ plainto_tsquery('simple', #search)) OR (to_tsvector('simple', messages.body) @@ plainto_tsquery('simple', #search))",
-					postgres.RawArgs{"#search": search}),
-			).AS_BOOL(),
-		)
-	} else {
-		where = table.ThreadsRecipients.UserID.EQ(uid).OR(table.UsersGroups.UserID.EQ(uid))
+		// hidden messages can't be found by their body
+		from = from.LEFT_JOIN(table.Messages, table.Messages.ThreadID.EQ(table.Threads.ID).
+			AND(table.Messages.HiddenAt.IS_NULL()))
+		where = where.AND(postgres.CAST(
+			postgres.Raw("(to_tsvector(#language::regconfig, threads.title) @@ plainto_tsquery(#language::regconfig, #search)) OR "+
+				"(to_tsvector(#language::regconfig, messages.body) @@ plainto_tsquery(#language::regconfig, #search))",
+				postgres.RawArgs{"#language": language, "#search": search}),
+		).AS_BOOL())
+	}
+
+	pinned := table.ThreadsStates.Pinned.IS_TRUE()
+
+	if cursor != nil {
+		after := table.Threads.UpdatedAt.LT(postgres.TimestampzT(cursor.UpdatedAt)).
+			OR(table.Threads.UpdatedAt.EQ(postgres.TimestampzT(cursor.UpdatedAt)).
+				AND(table.Threads.ID.LT(helpers.PostgresInt(cursor.ID))))
+
+		if cursor.Pinned {
+			where = where.AND(pinned.IS_FALSE().OR(after))
+		} else {
+			where = where.AND(pinned.IS_FALSE().AND(after))
+		}
 	}
 
 	query := postgres.SELECT(
@@ -353,10 +770,14 @@ func (m MessagingModel) GetThreadsForUser(userID int, search string) ([]*ThreadE
 		table.Users.ID, table.Users.Name, table.Users.Role,
 		postgres.CASE().WHEN(table.ThreadsRead.UserID.IS_NOT_NULL()).THEN(postgres.Bool(true)).ELSE(postgres.Bool(false)).AS("threadext.read"),
 		postgres.SELECT(postgres.COUNT(table.Messages.ID)).FROM(table.Messages).WHERE(table.Messages.ThreadID.EQ(table.Threads.ID)).AS("threadext.message_count"),
+		table.ThreadsStates.Archived.IS_TRUE().AS("threadext.archived"),
+		table.ThreadsStates.Muted.IS_TRUE().AS("threadext.muted"),
+		pinned.AS("threadext.pinned"),
 	).DISTINCT().
 		FROM(from).
 		WHERE(where).
-		ORDER_BY(table.Threads.UpdatedAt.DESC())
+		ORDER_BY(pinned.DESC(), table.Threads.UpdatedAt.DESC(), table.Threads.ID.DESC()).
+		LIMIT(int64(limit))
 
 	var threads []*ThreadExt
 
@@ -371,19 +792,20 @@ func (m MessagingModel) GetThreadsForUser(userID int, search string) ([]*ThreadE
 	return threads, nil
 }
 
+// muted threads don't count as unread
 func (m MessagingModel) DoesUserHaveUnread(userID int) (bool, error) {
 	uid := helpers.PostgresInt(userID)
 
 	query := postgres.SELECT(postgres.COUNT(postgres.STAR)).
 		FROM(table.Threads.
-			INNER_JOIN(table.ThreadsRecipients, table.ThreadsRecipients.ThreadID.EQ(table.Threads.ID)).
-			LEFT_JOIN(table.UsersGroups, table.UsersGroups.GroupID.EQ(table.ThreadsRecipients.GroupID).
-				AND(table.UsersGroups.UserID.EQ(uid))).
 			LEFT_JOIN(table.ThreadsRead, table.ThreadsRead.ThreadID.EQ(table.Threads.ID).
-				AND(table.ThreadsRead.UserID.EQ(uid)))).
+				AND(table.ThreadsRead.UserID.EQ(uid))).
+			LEFT_JOIN(table.ThreadsStates, table.ThreadsStates.ThreadID.EQ(table.Threads.ID).
+				AND(table.ThreadsStates.UserID.EQ(uid)))).
 		WHERE(postgres.AND(
-			table.ThreadsRecipients.UserID.EQ(uid).OR(table.UsersGroups.UserID.EQ(uid)),
+			threadIncludesUser(table.Threads.ID, uid),
 			table.ThreadsRead.UserID.IS_NULL(),
+			table.ThreadsStates.Muted.IS_NOT_TRUE(),
 		))
 
 	var result []int
@@ -399,17 +821,42 @@ func (m MessagingModel) DoesUserHaveUnread(userID int) (bool, error) {
 	return result[0] > 0, nil
 }
 
+func (m MessagingModel) CountUnreadThreadsForUser(userID int) (int, error) {
+	uid := helpers.PostgresInt(userID)
+
+	query := postgres.SELECT(postgres.COUNT(postgres.DISTINCT(table.Threads.ID))).
+		FROM(table.Threads.
+			LEFT_JOIN(table.ThreadsRead, table.ThreadsRead.ThreadID.EQ(table.Threads.ID).
+				AND(table.ThreadsRead.UserID.EQ(uid))).
+			LEFT_JOIN(table.ThreadsStates, table.ThreadsStates.ThreadID.EQ(table.Threads.ID).
+				AND(table.ThreadsStates.UserID.EQ(uid)))).
+		WHERE(postgres.AND(
+			threadIncludesUser(table.Threads.ID, uid),
+			table.ThreadsRead.UserID.IS_NULL(),
+			table.ThreadsStates.Muted.IS_NOT_TRUE(),
+		))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result[0], nil
+}
+
 func (m MessagingModel) IsUserInThread(userID, threadID int) (bool, error) {
 	uid := helpers.PostgresInt(userID)
 
 	query := postgres.SELECT(postgres.COUNT(postgres.STAR)).
-		FROM(table.Threads.
-			INNER_JOIN(table.ThreadsRecipients, table.ThreadsRecipients.ThreadID.EQ(table.Threads.ID)).
-			LEFT_JOIN(table.UsersGroups, table.UsersGroups.GroupID.EQ(table.ThreadsRecipients.GroupID).
-				AND(table.UsersGroups.UserID.EQ(uid)))).
+		FROM(table.Threads).
 		WHERE(postgres.AND(
-			table.ThreadsRecipients.UserID.EQ(uid).OR(table.UsersGroups.UserID.EQ(uid)),
 			table.Threads.ID.EQ(helpers.PostgresInt(threadID)),
+			threadIncludesUser(table.Threads.ID, uid),
 		))
 
 	var result []int
@@ -456,9 +903,10 @@ func (m MessagingModel) SetThreadAsUnreadForAll(threadID int) error {
 	return nil
 }
 
-func (m MessagingModel) SetThreadLocked(threadID int, locked bool) error {
-	stmt := table.Threads.UPDATE(table.Threads.Locked).
-		SET(locked).
+// byAdmin marks a moderation lock, which only administrators can post in or lift
+func (m MessagingModel) SetThreadLocked(threadID int, locked, byAdmin bool) error {
+	stmt := table.Threads.UPDATE(table.Threads.Locked, table.Threads.LockedByAdmin).
+		SET(locked, locked && byAdmin).
 		WHERE(table.Threads.ID.EQ(helpers.PostgresInt(threadID)))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -487,3 +935,127 @@ func (m MessagingModel) SetThreadUpdatedAt(threadID int) error {
 
 	return nil
 }
+
+// moves the user's last read message of the thread forward, never back
+func (m MessagingModel) SetMessagesReadForUser(threadID, userID, messageID int) error {
+	stmt := table.MessagesRead.INSERT(table.MessagesRead.AllColumns).
+		MODEL(MessageRead{
+			ThreadID:  &threadID,
+			UserID:    &userID,
+			MessageID: &messageID,
+			ReadAt:    helpers.ToPtr(time.Now().UTC()),
+		}).
+		ON_CONFLICT(table.MessagesRead.ThreadID, table.MessagesRead.UserID).
+		DO_UPDATE(postgres.SET(
+			table.MessagesRead.MessageID.SET(table.MessagesRead.EXCLUDED.MessageID),
+			table.MessagesRead.ReadAt.SET(table.MessagesRead.EXCLUDED.ReadAt),
+		).WHERE(table.MessagesRead.MessageID.IS_NULL().OR(table.MessagesRead.EXCLUDED.MessageID.GT(table.MessagesRead.MessageID))))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m MessagingModel) GetThreadReceipts(threadID int, userIDs []int) ([]*ThreadReceipt, error) {
+	var uids []postgres.Expression
+	for _, id := range userIDs {
+		uids = append(uids, helpers.PostgresInt(id))
+	}
+
+	query := postgres.SELECT(table.Users.ID, table.Users.Name, table.Users.Role, table.MessagesRead.MessageID, table.MessagesRead.ReadAt).
+		FROM(table.Users.
+			LEFT_JOIN(table.MessagesRead, table.MessagesRead.UserID.EQ(table.Users.ID).
+				AND(table.MessagesRead.ThreadID.EQ(helpers.PostgresInt(threadID))))).
+		WHERE(table.Users.ID.IN(uids...)).
+		ORDER_BY(table.Users.Name.ASC())
+
+	var receipts []*ThreadReceipt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &receipts)
+	if err != nil {
+		return nil, err
+	}
+
+	return receipts, nil
+}
+
+// the user's state for the thread, all flags unset if it has never been changed
+func (m MessagingModel) GetThreadState(threadID, userID int) (*ThreadState, error) {
+	query := postgres.SELECT(table.ThreadsStates.AllColumns).
+		FROM(table.ThreadsStates).
+		WHERE(table.ThreadsStates.ThreadID.EQ(helpers.PostgresInt(threadID)).
+			AND(table.ThreadsStates.UserID.EQ(helpers.PostgresInt(userID))))
+
+	var state ThreadState
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &state)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return &ThreadState{
+				ThreadID: &threadID,
+				UserID:   &userID,
+				Archived: helpers.ToPtr(false),
+				Muted:    helpers.ToPtr(false),
+				Pinned:   helpers.ToPtr(false),
+			}, nil
+		default:
+			return nil, err
+		}
+	}
+
+	return &state, nil
+}
+
+func (m MessagingModel) SetThreadState(state *ThreadState) error {
+	stmt := table.ThreadsStates.INSERT(table.ThreadsStates.AllColumns).
+		MODEL(state).
+		ON_CONFLICT(table.ThreadsStates.ThreadID, table.ThreadsStates.UserID).
+		DO_UPDATE(postgres.SET(
+			table.ThreadsStates.Archived.SET(table.ThreadsStates.EXCLUDED.Archived),
+			table.ThreadsStates.Muted.SET(table.ThreadsStates.EXCLUDED.Muted),
+			table.ThreadsStates.Pinned.SET(table.ThreadsStates.EXCLUDED.Pinned),
+		))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// members that have muted the thread and get no notifications for it
+func (m MessagingModel) GetUserIDsMutingThread(threadID int) ([]int, error) {
+	query := postgres.SELECT(table.ThreadsStates.UserID).
+		FROM(table.ThreadsStates).
+		WHERE(table.ThreadsStates.ThreadID.EQ(helpers.PostgresInt(threadID)).
+			AND(table.ThreadsStates.Muted.IS_TRUE()))
+
+	var userIDs []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
@@ -3,37 +3,65 @@ package data
 import "database/sql"
 
 type Models struct {
-	Users       UserModel
-	Classes     ClassModel
-	Subjects    SubjectModel
-	Journals    JournalModel
-	Lessons     LessonModel
-	Assignments AssignmentModel
-	Grades      GradeModel
-	Marks       MarkModel
-	Absences    AbsenceModel
-	Groups      GroupModel
-	Messaging   MessagingModel
-	Sessions    SessionModel
-	Years       YearModel
-	Logs        LogModel
+	Users         UserModel
+	Classes       ClassModel
+	Subjects      SubjectModel
+	Journals      JournalModel
+	Lessons       LessonModel
+	Assignments   AssignmentModel
+	Grades        GradeModel
+	Marks         MarkModel
+	Absences      AbsenceModel
+	Groups        GroupModel
+	Messaging     MessagingModel
+	Sessions      SessionModel
+	Years         YearModel
+	Logs          LogModel
+	Attachments   AttachmentModel
+	Timetable     TimetableModel
+	Calendar      CalendarModel
+	Feeds         FeedModel
+	Substitutions SubstitutionModel
+	Bookings      BookingModel
+	Submissions   SubmissionModel
+	JournalGroups JournalGroupModel
+	Notifications NotificationModel
+	Events        EventModel
+	Announcements AnnouncementModel
+	Emails        EmailModel
+	Push          PushModel
+	Moderation    ModerationModel
 }
 
 func NewModel(db *sql.DB) Models {
 	return Models{
-		Users:       UserModel{DB: db},
-		Classes:     ClassModel{DB: db},
-		Subjects:    SubjectModel{DB: db},
-		Journals:    JournalModel{DB: db},
-		Lessons:     LessonModel{DB: db},
-		Assignments: AssignmentModel{DB: db},
-		Grades:      GradeModel{DB: db},
-		Marks:       MarkModel{DB: db},
-		Absences:    AbsenceModel{DB: db},
-		Groups:      GroupModel{DB: db},
-		Messaging:   MessagingModel{DB: db},
-		Sessions:    SessionModel{DB: db},
-		Years:       YearModel{DB: db},
-		Logs:        LogModel{DB: db},
+		Users:         UserModel{DB: db},
+		Classes:       ClassModel{DB: db},
+		Subjects:      SubjectModel{DB: db},
+		Journals:      JournalModel{DB: db},
+		Lessons:       LessonModel{DB: db},
+		Assignments:   AssignmentModel{DB: db},
+		Grades:        GradeModel{DB: db},
+		Marks:         MarkModel{DB: db},
+		Absences:      AbsenceModel{DB: db},
+		Groups:        GroupModel{DB: db},
+		Messaging:     MessagingModel{DB: db},
+		Sessions:      SessionModel{DB: db},
+		Years:         YearModel{DB: db},
+		Logs:          LogModel{DB: db},
+		Attachments:   AttachmentModel{DB: db},
+		Timetable:     TimetableModel{DB: db},
+		Calendar:      CalendarModel{DB: db},
+		Feeds:         FeedModel{DB: db},
+		Substitutions: SubstitutionModel{DB: db},
+		Bookings:      BookingModel{DB: db},
+		Submissions:   SubmissionModel{DB: db},
+		JournalGroups: JournalGroupModel{DB: db},
+		Notifications: NotificationModel{DB: db},
+		Events:        EventModel{DB: db},
+		Announcements: AnnouncementModel{DB: db},
+		Emails:        EmailModel{DB: db},
+		Push:          PushModel{DB: db},
+		Moderation:    ModerationModel{DB: db},
 	}
 }
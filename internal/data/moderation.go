@@ -0,0 +1,244 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+)
+
+var (
+	ErrNoSuchReport          = errors.New("no such report")
+	ErrReportAlreadyResolved = errors.New("report already resolved")
+	ErrNotMuted              = errors.New("user not muted")
+	ErrMutedFromMessaging    = errors.New("muted from messaging")
+)
+
+type MessageReport = model.MessagesReports
+
+type MessageReportExt struct {
+	MessageReport
+	Message  *Message `json:"message"`
+	Author   *User    `json:"author" alias:"author"`
+	Reporter *User    `json:"reporter" alias:"reporter"`
+}
+
+type MessagingMute = model.MessagingMutes
+
+type MessagingMuteExt struct {
+	MessagingMute
+	User *User `json:"user"`
+}
+
+type ModerationModel struct {
+	DB *sql.DB
+}
+
+// a user reports a message only once, reporting it again does nothing
+func (m ModerationModel) InsertMessageReport(r *MessageReport) error {
+	stmt := table.MessagesReports.INSERT(table.MessagesReports.MutableColumns).
+		MODEL(r).
+		ON_CONFLICT(table.MessagesReports.MessageID, table.MessagesReports.UserID).DO_NOTHING()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m ModerationModel) GetMessageReportByID(reportID int) (*MessageReport, error) {
+	query := postgres.SELECT(table.MessagesReports.AllColumns).
+		FROM(table.MessagesReports).
+		WHERE(table.MessagesReports.ID.EQ(helpers.PostgresInt(reportID)))
+
+	var report MessageReport
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &report)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchReport
+		default:
+			return nil, err
+		}
+	}
+
+	return &report, nil
+}
+
+// the moderation queue, unresolved reports oldest first
+func (m ModerationModel) GetUnresolvedMessageReports() ([]*MessageReportExt, error) {
+	author := table.Users.AS("author")
+	reporter := table.Users.AS("reporter")
+
+	query := postgres.SELECT(table.MessagesReports.AllColumns, table.Messages.AllColumns,
+		author.ID, author.Name, author.Role, reporter.ID, reporter.Name, reporter.Role).
+		FROM(table.MessagesReports.
+			INNER_JOIN(table.Messages, table.Messages.ID.EQ(table.MessagesReports.MessageID)).
+			INNER_JOIN(author, author.ID.EQ(table.Messages.UserID)).
+			INNER_JOIN(reporter, reporter.ID.EQ(table.MessagesReports.UserID))).
+		WHERE(table.MessagesReports.ResolvedAt.IS_NULL()).
+		ORDER_BY(table.MessagesReports.CreatedAt.ASC(), table.MessagesReports.ID.ASC())
+
+	var reports []*MessageReportExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &reports)
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+func (m ModerationModel) ResolveMessageReport(reportID, resolvedBy int) error {
+	stmt := table.MessagesReports.UPDATE(table.MessagesReports.ResolvedBy, table.MessagesReports.ResolvedAt).
+		SET(helpers.PostgresInt(resolvedBy), postgres.TimestampzT(time.Now().UTC())).
+		WHERE(table.MessagesReports.ID.EQ(helpers.PostgresInt(reportID)).
+			AND(table.MessagesReports.ResolvedAt.IS_NULL()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrReportAlreadyResolved
+	}
+
+	return nil
+}
+
+// resolves all open reports of the message, done when it gets hidden
+func (m ModerationModel) ResolveReportsForMessage(tx *sql.Tx, messageID, resolvedBy int) error {
+	stmt := table.MessagesReports.UPDATE(table.MessagesReports.ResolvedBy, table.MessagesReports.ResolvedAt).
+		SET(helpers.PostgresInt(resolvedBy), postgres.TimestampzT(time.Now().UTC())).
+		WHERE(table.MessagesReports.MessageID.EQ(helpers.PostgresInt(messageID)).
+			AND(table.MessagesReports.ResolvedAt.IS_NULL()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// mutes the user, replacing an earlier mute
+func (m ModerationModel) SetMessagingMute(mute *MessagingMute) error {
+	stmt := table.MessagingMutes.INSERT(table.MessagingMutes.AllColumns).
+		MODEL(mute).
+		ON_CONFLICT(table.MessagingMutes.UserID).
+		DO_UPDATE(postgres.SET(
+			table.MessagingMutes.MutedBy.SET(table.MessagingMutes.EXCLUDED.MutedBy),
+			table.MessagingMutes.Reason.SET(table.MessagingMutes.EXCLUDED.Reason),
+			table.MessagingMutes.MutedUntil.SET(table.MessagingMutes.EXCLUDED.MutedUntil),
+			table.MessagingMutes.CreatedAt.SET(table.MessagingMutes.EXCLUDED.CreatedAt),
+		))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m ModerationModel) DeleteMessagingMute(userID int) error {
+	stmt := table.MessagingMutes.DELETE().
+		WHERE(table.MessagingMutes.UserID.EQ(helpers.PostgresInt(userID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotMuted
+	}
+
+	return nil
+}
+
+// the user's mute if it hasn't run out yet
+func (m ModerationModel) GetActiveMessagingMute(userID int) (*MessagingMute, error) {
+	query := postgres.SELECT(table.MessagingMutes.AllColumns).
+		FROM(table.MessagingMutes).
+		WHERE(table.MessagingMutes.UserID.EQ(helpers.PostgresInt(userID)).
+			AND(table.MessagingMutes.MutedUntil.GT(postgres.TimestampzT(time.Now().UTC()))))
+
+	var mute MessagingMute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &mute)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNotMuted
+		default:
+			return nil, err
+		}
+	}
+
+	return &mute, nil
+}
+
+func (m ModerationModel) GetActiveMessagingMutes() ([]*MessagingMuteExt, error) {
+	query := postgres.SELECT(table.MessagingMutes.AllColumns, table.Users.ID, table.Users.Name, table.Users.Role).
+		FROM(table.MessagingMutes.
+			INNER_JOIN(table.Users, table.Users.ID.EQ(table.MessagingMutes.UserID))).
+		WHERE(table.MessagingMutes.MutedUntil.GT(postgres.TimestampzT(time.Now().UTC()))).
+		ORDER_BY(table.MessagingMutes.MutedUntil.ASC())
+
+	var mutes []*MessagingMuteExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &mutes)
+	if err != nil {
+		return nil, err
+	}
+
+	return mutes, nil
+}
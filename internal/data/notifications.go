@@ -0,0 +1,125 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+)
+
+const (
+	NotificationAssignmentPublished = "assignment_published"
+)
+
+var (
+	ErrNoSuchNotification = errors.New("no such notification")
+)
+
+type Notification = model.Notifications
+
+type NotificationModel struct {
+	DB *sql.DB
+}
+
+func (m NotificationModel) GetNotificationByID(notificationID int) (*Notification, error) {
+	query := postgres.SELECT(table.Notifications.AllColumns).
+		FROM(table.Notifications).
+		WHERE(table.Notifications.ID.EQ(helpers.PostgresInt(notificationID)))
+
+	var notification Notification
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &notification)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchNotification
+		default:
+			return nil, err
+		}
+	}
+
+	return &notification, nil
+}
+
+func (m NotificationModel) GetNotificationsForUser(userID int, unreadOnly bool) ([]*Notification, error) {
+	where := table.Notifications.UserID.EQ(helpers.PostgresInt(userID))
+	if unreadOnly {
+		where = where.AND(table.Notifications.ReadAt.IS_NULL())
+	}
+
+	query := postgres.SELECT(table.Notifications.AllColumns).
+		FROM(table.Notifications).
+		WHERE(where).
+		ORDER_BY(table.Notifications.CreatedAt.DESC()).
+		LIMIT(100)
+
+	var notifications []*Notification
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (m NotificationModel) InsertNotifications(tx *sql.Tx, notifications []*Notification) error {
+	stmt := table.Notifications.INSERT(table.Notifications.MutableColumns).
+		MODELS(notifications)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m NotificationModel) SetNotificationRead(notificationID int) error {
+	stmt := table.Notifications.UPDATE(table.Notifications.ReadAt).
+		SET(time.Now().UTC()).
+		WHERE(table.Notifications.ID.EQ(helpers.PostgresInt(notificationID)).
+			AND(table.Notifications.ReadAt.IS_NULL()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m NotificationModel) SetAllNotificationsRead(userID int) error {
+	stmt := table.Notifications.UPDATE(table.Notifications.ReadAt).
+		SET(time.Now().UTC()).
+		WHERE(table.Notifications.UserID.EQ(helpers.PostgresInt(userID)).
+			AND(table.Notifications.ReadAt.IS_NULL()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
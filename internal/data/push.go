@@ -0,0 +1,141 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+type PushSubscription = model.PushSubscriptions
+
+// a subscription of someone that should be notified about ForUser
+type PushRecipient struct {
+	ID        int    `alias:"push_subscriptions.id"`
+	UserID    int    `alias:"push_subscriptions.user_id"`
+	Endpoint  string `alias:"push_subscriptions.endpoint"`
+	P256dh    string `alias:"push_subscriptions.p256dh"`
+	Auth      string `alias:"push_subscriptions.auth"`
+	ForUserID int    `alias:"for_user.id"`
+	ForName   string `alias:"for_user.name"`
+}
+
+type PushModel struct {
+	DB *sql.DB
+}
+
+// registering an endpoint again moves it to the new session
+func (m PushModel) InsertPushSubscription(s *PushSubscription) error {
+	stmt := table.PushSubscriptions.INSERT(table.PushSubscriptions.MutableColumns).
+		MODEL(s).
+		ON_CONFLICT(table.PushSubscriptions.Endpoint).
+		DO_UPDATE(postgres.SET(
+			table.PushSubscriptions.UserID.SET(table.PushSubscriptions.EXCLUDED.UserID),
+			table.PushSubscriptions.SessionID.SET(table.PushSubscriptions.EXCLUDED.SessionID),
+			table.PushSubscriptions.P256dh.SET(table.PushSubscriptions.EXCLUDED.P256dh),
+			table.PushSubscriptions.Auth.SET(table.PushSubscriptions.EXCLUDED.Auth),
+			table.PushSubscriptions.CreatedAt.SET(table.PushSubscriptions.EXCLUDED.CreatedAt),
+		)).
+		RETURNING(table.PushSubscriptions.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m PushModel) DeletePushSubscriptionForSession(sessionID int, endpoint string) error {
+	stmt := table.PushSubscriptions.DELETE().
+		WHERE(table.PushSubscriptions.SessionID.EQ(helpers.PostgresInt(sessionID)).
+			AND(table.PushSubscriptions.Endpoint.EQ(postgres.String(endpoint))))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// on logout, the browser shouldn't keep getting pushes for a session that has ended
+func (m PushModel) DeletePushSubscriptionsForSession(sessionID int) error {
+	stmt := table.PushSubscriptions.DELETE().
+		WHERE(table.PushSubscriptions.SessionID.EQ(helpers.PostgresInt(sessionID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m PushModel) DeletePushSubscriptionByID(subscriptionID int) error {
+	stmt := table.PushSubscriptions.DELETE().
+		WHERE(table.PushSubscriptions.ID.EQ(helpers.PostgresInt(subscriptionID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// subscriptions of the users, or their parents with withParents set, that haven't turned the event type off.
+// Sessions expire after a few idle minutes, so subscriptions last until logout, unsubscribing
+// or the push service saying they are gone
+func (m PushModel) GetPushRecipients(eventType string, userIDs []int, withParents bool) ([]*PushRecipient, error) {
+	var uids []postgres.Expression
+	for _, uid := range userIDs {
+		uids = append(uids, helpers.PostgresInt(uid))
+	}
+
+	forUser := table.Users.AS("for_user")
+	recipient := table.Users.AS("recipient")
+
+	query := postgres.SELECT(table.PushSubscriptions.ID, table.PushSubscriptions.UserID, table.PushSubscriptions.Endpoint,
+		table.PushSubscriptions.P256dh, table.PushSubscriptions.Auth, forUser.ID, forUser.Name).
+		FROM(forUser.
+			INNER_JOIN(recipient, notificationRecipient(recipient, forUser, withParents)).
+			INNER_JOIN(table.PushSubscriptions, table.PushSubscriptions.UserID.EQ(recipient.ID)).
+			LEFT_JOIN(table.NotificationPreferences, table.NotificationPreferences.UserID.EQ(recipient.ID).
+				AND(table.NotificationPreferences.EventType.EQ(postgres.String(eventType))))).
+		WHERE(postgres.AND(
+			forUser.ID.IN(uids...),
+			recipient.Active.IS_TRUE(),
+			recipient.Archived.IS_FALSE(),
+			table.NotificationPreferences.Delivery.IS_NULL().
+				OR(table.NotificationPreferences.Delivery.NOT_EQ(postgres.String(DeliveryOff))),
+		))
+
+	var recipients []*PushRecipient
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
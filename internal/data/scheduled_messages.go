@@ -0,0 +1,210 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+)
+
+var (
+	ErrNoSuchScheduledMessage = errors.New("no such scheduled message")
+	ErrScheduledMessageSent   = errors.New("scheduled message already sent")
+)
+
+// how long the scheduler has to deliver a message it has claimed
+const scheduledMessageClaimLease = 5 * time.Minute
+
+// a new thread, or a message to ThreadID, sent by the scheduler at SendAt unless it's a draft.
+// Recipients of a new thread are kept as JSON
+type ScheduledMessage = model.MessagesScheduled
+
+func (m MessagingModel) InsertScheduledMessage(s *ScheduledMessage) error {
+	stmt := table.MessagesScheduled.INSERT(table.MessagesScheduled.MutableColumns).
+		MODEL(s).
+		RETURNING(table.MessagesScheduled.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m MessagingModel) GetScheduledMessageByID(scheduledID int) (*ScheduledMessage, error) {
+	query := postgres.SELECT(table.MessagesScheduled.AllColumns).
+		FROM(table.MessagesScheduled).
+		WHERE(table.MessagesScheduled.ID.EQ(helpers.PostgresInt(scheduledID)))
+
+	var scheduled ScheduledMessage
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &scheduled)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchScheduledMessage
+		default:
+			return nil, err
+		}
+	}
+
+	return &scheduled, nil
+}
+
+// the user's drafts and scheduled messages not sent yet, including the ones that failed to send
+func (m MessagingModel) GetScheduledMessagesForUser(userID int) ([]*ScheduledMessage, error) {
+	query := postgres.SELECT(table.MessagesScheduled.AllColumns).
+		FROM(table.MessagesScheduled).
+		WHERE(table.MessagesScheduled.UserID.EQ(helpers.PostgresInt(userID)).
+			AND(table.MessagesScheduled.SentAt.IS_NULL())).
+		ORDER_BY(table.MessagesScheduled.SendAt.ASC(), table.MessagesScheduled.UpdatedAt.DESC())
+
+	var scheduled []*ScheduledMessage
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &scheduled)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduled, nil
+}
+
+// updates a scheduled message unless it has been sent or the scheduler is sending it,
+// a failed message is rescheduled by updating it with its error cleared
+func (m MessagingModel) UpdateScheduledMessage(s *ScheduledMessage) error {
+	stmt := table.MessagesScheduled.UPDATE(table.MessagesScheduled.Title, table.MessagesScheduled.Body, table.MessagesScheduled.Recipients,
+		table.MessagesScheduled.Draft, table.MessagesScheduled.SendAt, table.MessagesScheduled.UpdatedAt, table.MessagesScheduled.Error).
+		MODEL(s).
+		WHERE(table.MessagesScheduled.ID.EQ(helpers.PostgresInt(s.ID)).
+			AND(scheduledMessageChangeable()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrScheduledMessageSent
+	}
+
+	return nil
+}
+
+// deletes a scheduled message unless it has been sent or the scheduler is sending it
+func (m MessagingModel) DeleteScheduledMessage(scheduledID int) error {
+	stmt := table.MessagesScheduled.DELETE().
+		WHERE(table.MessagesScheduled.ID.EQ(helpers.PostgresInt(scheduledID)).
+			AND(scheduledMessageChangeable()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrScheduledMessageSent
+	}
+
+	return nil
+}
+
+// not sent and not claimed by the scheduler
+func scheduledMessageChangeable() postgres.BoolExpression {
+	return table.MessagesScheduled.SentAt.IS_NULL().
+		AND(table.MessagesScheduled.ClaimedUntil.IS_NULL().
+			OR(table.MessagesScheduled.ClaimedUntil.LT(postgres.TimestampzT(time.Now().UTC()))))
+}
+
+// claims the scheduled messages that are due and returns them, so each is sent by only one instance.
+// The claim runs out after a while, a message whose sender died before marking it is then sent again.
+// Failed messages wait for their author to reschedule them
+func (m MessagingModel) ClaimDueScheduledMessages() ([]*ScheduledMessage, error) {
+	now := time.Now().UTC()
+
+	stmt := table.MessagesScheduled.UPDATE(table.MessagesScheduled.ClaimedUntil).
+		SET(postgres.TimestampzT(now.Add(scheduledMessageClaimLease))).
+		WHERE(postgres.AND(
+			scheduledMessageChangeable(),
+			table.MessagesScheduled.Error.IS_NULL(),
+			table.MessagesScheduled.Draft.IS_FALSE(),
+			table.MessagesScheduled.SendAt.LT_EQ(postgres.TimestampzT(now)),
+		)).
+		RETURNING(table.MessagesScheduled.AllColumns)
+
+	var scheduled []*ScheduledMessage
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, &scheduled)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduled, nil
+}
+
+// marks a claimed message as sent once it has been delivered
+func (m MessagingModel) SetScheduledMessageSent(scheduledID int) error {
+	stmt := table.MessagesScheduled.UPDATE(table.MessagesScheduled.SentAt, table.MessagesScheduled.ClaimedUntil).
+		SET(postgres.TimestampzT(time.Now().UTC()), postgres.NULL).
+		WHERE(table.MessagesScheduled.ID.EQ(helpers.PostgresInt(scheduledID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// records why the scheduler couldn't send the message and releases it, its author sees the reason in the list
+func (m MessagingModel) SetScheduledMessageError(scheduledID int, sendErr error) error {
+	stmt := table.MessagesScheduled.UPDATE(table.MessagesScheduled.Error, table.MessagesScheduled.ClaimedUntil).
+		SET(postgres.String(sendErr.Error()), postgres.NULL).
+		WHERE(table.MessagesScheduled.ID.EQ(helpers.PostgresInt(scheduledID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
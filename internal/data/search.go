@@ -0,0 +1,140 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+// marks the matches in headlines, control characters so they can't clash with the message text
+const (
+	HeadlineStart = "\x02"
+	HeadlineStop  = "\x03"
+)
+
+// what to search for and where, Language is a text search configuration such as "simple" or "estonian",
+// it can be checked with TextSearchConfigExists
+type MessageSearch struct {
+	Query    string
+	Language string
+	AuthorID *int
+	ThreadID *int
+	From     *time.Time
+	Until    *time.Time
+	Offset   int
+	Limit    int
+}
+
+type MessageSearchResult struct {
+	Message
+	Thread   *Thread  `json:"thread"`
+	User     *User    `json:"user"`
+	Rank     *float64 `json:"rank" alias:"search.rank"`
+	Headline *string  `json:"headline" alias:"search.headline"`
+}
+
+// whether postgres has a text search configuration with the name
+func (m MessagingModel) TextSearchConfigExists(name string) (bool, error) {
+	stmt := postgres.RawStatement("SELECT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = #name)",
+		postgres.RawArgs{"#name": name})
+
+	var result []bool
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result[0], nil
+}
+
+// whether the table has a GIN index on to_tsvector(language, column), without one searches read the whole table
+func (m MessagingModel) TextSearchIndexExists(tableName, column, language string) (bool, error) {
+	stmt := postgres.RawStatement("SELECT EXISTS (SELECT 1 FROM pg_indexes WHERE schemaname = current_schema() AND tablename = #table AND strpos(indexdef, #def) > 0)",
+		postgres.RawArgs{
+			"#table": tableName,
+			"#def":   fmt.Sprintf("USING gin (to_tsvector('%s'::regconfig, %s))", language, column),
+		})
+
+	var result []bool
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result[0], nil
+}
+
+// messages in the user's threads matching the query, best matches first, with a snippet of each.
+// Hidden messages are left out
+func (m MessagingModel) SearchMessages(userID int, s *MessageSearch) ([]*MessageSearchResult, error) {
+	vector := "to_tsvector(#language::regconfig, messages.body)"
+	tsQuery := "websearch_to_tsquery(#language::regconfig, #query)"
+	args := postgres.RawArgs{"#language": s.Language, "#query": s.Query}
+
+	where := postgres.AND(
+		threadIncludesUser(table.Threads.ID, helpers.PostgresInt(userID)),
+		table.Messages.HiddenAt.IS_NULL(),
+		postgres.CAST(postgres.Raw(vector+" @@ "+tsQuery, args)).AS_BOOL(),
+	)
+
+	if s.AuthorID != nil {
+		where = where.AND(table.Messages.UserID.EQ(helpers.PostgresInt(*s.AuthorID)))
+	}
+	if s.ThreadID != nil {
+		where = where.AND(table.Messages.ThreadID.EQ(helpers.PostgresInt(*s.ThreadID)))
+	}
+	if s.From != nil {
+		where = where.AND(table.Messages.CreatedAt.GT_EQ(postgres.TimestampzT(*s.From)))
+	}
+	if s.Until != nil {
+		where = where.AND(table.Messages.CreatedAt.LT(postgres.TimestampzT(*s.Until)))
+	}
+
+	rank := postgres.FloatExp(postgres.Raw("ts_rank("+vector+", "+tsQuery+")", args))
+	headline := postgres.StringExp(postgres.Raw(
+		"ts_headline(#language::regconfig, messages.body, "+tsQuery+", #options)",
+		postgres.RawArgs{
+			"#language": s.Language,
+			"#query":    s.Query,
+			"#options":  fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=20, MinWords=5", HeadlineStart, HeadlineStop),
+		}))
+
+	query := postgres.SELECT(
+		table.Messages.ID, table.Messages.ThreadID, table.Messages.UserID, table.Messages.Type, table.Messages.CreatedAt, table.Messages.UpdatedAt,
+		table.Threads.ID, table.Threads.Title,
+		table.Users.ID, table.Users.Name, table.Users.Role,
+		rank.AS("search.rank"),
+		headline.AS("search.headline"),
+	).
+		FROM(table.Messages.
+			INNER_JOIN(table.Threads, table.Threads.ID.EQ(table.Messages.ThreadID)).
+			INNER_JOIN(table.Users, table.Users.ID.EQ(table.Messages.UserID))).
+		WHERE(where).
+		ORDER_BY(rank.DESC(), table.Messages.CreatedAt.DESC(), table.Messages.ID.DESC()).
+		OFFSET(int64(s.Offset)).
+		LIMIT(int64(s.Limit))
+
+	var results []*MessageSearchResult
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
 	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
 	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
 	"github.com/go-jet/jet/v2/postgres"
 	"github.com/go-jet/jet/v2/qrm"
 )
@@ -20,6 +21,9 @@ var (
 
 type Session = model.Sessions
 
+// how long a ticket for opening the event stream can be used
+const EventsTicketLifetime = time.Minute
+
 type SessionModel struct {
 	DB *sql.DB
 }
@@ -44,6 +48,36 @@ func (m SessionModel) InsertSession(s *Session) error {
 	return nil
 }
 
+// a ticket opens the event stream once for the session, so the session token itself never goes into a url
+func (m SessionModel) InsertEventsTicket(sessionID int, ticket *types.Token) error {
+	current := time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// the session's tickets that ran out unused go on the way
+	_, err := table.EventsTickets.DELETE().
+		WHERE(table.EventsTickets.SessionID.EQ(helpers.PostgresInt(sessionID)).
+			AND(table.EventsTickets.Expires.LT_EQ(postgres.TimestampzT(current)))).
+		ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	_, err = table.EventsTickets.INSERT(table.EventsTickets.MutableColumns).
+		MODEL(model.EventsTickets{
+			SessionID: &sessionID,
+			Token:     ticket,
+			Expires:   helpers.ToPtr(current.Add(EventsTicketLifetime)),
+		}).
+		ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m SessionModel) ExtendSession(sessionID int) error {
 	current := time.Now().UTC()
 
@@ -0,0 +1,176 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/go-jet/jet/v2/postgres"
+)
+
+const (
+	SubmissionSubmitted = "submitted"
+	SubmissionReturned  = "returned"
+)
+
+var (
+	ErrNoSuchSubmission = errors.New("no such submission")
+)
+
+type Submission = model.Submissions
+
+type SubmissionExt struct {
+	Submission
+	Late       bool         `json:"late"`
+	Assignment *Assignment  `json:"assignment,omitempty"`
+	Student    *User        `json:"student,omitempty" alias:"student"`
+	Teacher    *User        `json:"teacher,omitempty" alias:"feedback_teacher"`
+	Mark       *MinimalMark `json:"mark,omitempty"`
+}
+
+type SubmissionModel struct {
+	DB *sql.DB
+	// the school's time zone, deadline days end at its midnight
+	Location *time.Location
+}
+
+// a submission is late when it was handed in after the deadline day
+func (s *SubmissionExt) setLate(loc *time.Location) {
+	if s.Assignment == nil || s.Assignment.Deadline == nil || s.Assignment.Deadline.Time == nil || s.SubmittedAt == nil {
+		return
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	s.Late = s.SubmittedAt.In(loc).Format("2006-01-02") > s.Assignment.Deadline.Format("2006-01-02")
+}
+
+func (m SubmissionModel) getSubmissions(where postgres.BoolExpression) ([]*SubmissionExt, error) {
+	student := table.Users.AS("student")
+	feedbackTeacher := table.Users.AS("feedback_teacher")
+
+	query := postgres.SELECT(
+		table.Submissions.AllColumns,
+		table.Assignments.ID, table.Assignments.JournalID, table.Assignments.Deadline, table.Assignments.Type,
+		student.ID, student.Name, student.Role,
+		feedbackTeacher.ID, feedbackTeacher.Name, feedbackTeacher.Role,
+		table.Marks.ID, table.Marks.Type, table.Marks.Comment, table.Grades.Identifier).
+		FROM(table.Submissions.
+			INNER_JOIN(table.Assignments, table.Assignments.ID.EQ(table.Submissions.AssignmentID)).
+			INNER_JOIN(student, student.ID.EQ(table.Submissions.StudentID)).
+			LEFT_JOIN(feedbackTeacher, feedbackTeacher.ID.EQ(table.Submissions.FeedbackBy)).
+			LEFT_JOIN(table.Marks, table.Marks.ID.EQ(table.Submissions.MarkID)).
+			LEFT_JOIN(table.Grades, table.Grades.ID.EQ(table.Marks.GradeID))).
+		WHERE(where).
+		ORDER_BY(student.Name.ASC(), table.Submissions.ID.ASC())
+
+	var submissions []*SubmissionExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &submissions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range submissions {
+		s.setLate(m.Location)
+	}
+
+	return submissions, nil
+}
+
+func (m SubmissionModel) GetSubmissionByID(submissionID int) (*SubmissionExt, error) {
+	submissions, err := m.getSubmissions(table.Submissions.ID.EQ(helpers.PostgresInt(submissionID)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(submissions) == 0 {
+		return nil, ErrNoSuchSubmission
+	}
+
+	return submissions[0], nil
+}
+
+func (m SubmissionModel) GetSubmissionForStudent(studentID, assignmentID int) (*SubmissionExt, error) {
+	submissions, err := m.getSubmissions(table.Submissions.StudentID.EQ(helpers.PostgresInt(studentID)).
+		AND(table.Submissions.AssignmentID.EQ(helpers.PostgresInt(assignmentID))))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(submissions) == 0 {
+		return nil, ErrNoSuchSubmission
+	}
+
+	return submissions[0], nil
+}
+
+func (m SubmissionModel) GetSubmissionsForAssignment(assignmentID int) ([]*SubmissionExt, error) {
+	return m.getSubmissions(table.Submissions.AssignmentID.EQ(helpers.PostgresInt(assignmentID)))
+}
+
+// inserts the submission or, if the student has already submitted, replaces its body
+// and marks it as submitted again
+func (m SubmissionModel) UpsertSubmission(s *Submission) error {
+	stmt := table.Submissions.INSERT(table.Submissions.AssignmentID, table.Submissions.StudentID, table.Submissions.Body, table.Submissions.Status, table.Submissions.SubmittedAt).
+		MODEL(s).
+		ON_CONFLICT(table.Submissions.AssignmentID, table.Submissions.StudentID).
+		DO_UPDATE(postgres.SET(
+			table.Submissions.Body.SET(table.Submissions.EXCLUDED.Body),
+			table.Submissions.Status.SET(table.Submissions.EXCLUDED.Status),
+			table.Submissions.SubmittedAt.SET(table.Submissions.EXCLUDED.SubmittedAt),
+		)).
+		RETURNING(table.Submissions.AllColumns)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// a file added to a submission hands it in again
+func (m SubmissionModel) SetSubmissionSubmitted(submissionID int) error {
+	stmt := table.Submissions.UPDATE(table.Submissions.Status, table.Submissions.SubmittedAt).
+		SET(postgres.String(SubmissionSubmitted), postgres.TimestampzT(time.Now().UTC())).
+		WHERE(table.Submissions.ID.EQ(helpers.PostgresInt(submissionID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m SubmissionModel) SetSubmissionFeedback(tx *sql.Tx, s *Submission) error {
+	stmt := table.Submissions.UPDATE(table.Submissions.Status, table.Submissions.Feedback, table.Submissions.FeedbackBy, table.Submissions.FeedbackAt, table.Submissions.MarkID).
+		MODEL(s).
+		WHERE(table.Submissions.ID.EQ(helpers.PostgresInt(s.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+)
+
+// how long after a substitution has ended the substitute can still fill in marks
+const SubstitutionGracePeriod = 7 * 24 * time.Hour
+
+var (
+	ErrNoSuchSubstitution = errors.New("no such substitution")
+)
+
+type Substitution = model.Substitutions
+
+type SubstitutionExt struct {
+	Substitution
+	Journal       *Journal `json:"journal,omitempty"`
+	Subject       *Subject `json:"subject,omitempty"`
+	Lesson        *Lesson  `json:"lesson,omitempty"`
+	Substitute    *User    `json:"substitute,omitempty" alias:"substitute"`
+	AbsentTeacher *User    `json:"absent_teacher,omitempty" alias:"absent_teacher"`
+}
+
+type SubstitutionReport struct {
+	Teacher       *User `json:"teacher" alias:"substitute"`
+	Substitutions int   `json:"substitutions"`
+	Lessons       int   `json:"lessons"`
+}
+
+type SubstitutionModel struct {
+	DB *sql.DB
+}
+
+func (m SubstitutionModel) GetSubstitutionByID(substitutionID int) (*Substitution, error) {
+	query := postgres.SELECT(table.Substitutions.AllColumns).
+		FROM(table.Substitutions).
+		WHERE(table.Substitutions.ID.EQ(helpers.PostgresInt(substitutionID)))
+
+	var substitution Substitution
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &substitution)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchSubstitution
+		default:
+			return nil, err
+		}
+	}
+
+	return &substitution, nil
+}
+
+func (m SubstitutionModel) InsertSubstitution(s *Substitution) error {
+	stmt := table.Substitutions.INSERT(table.Substitutions.MutableColumns).
+		MODEL(s).
+		RETURNING(table.Substitutions.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m SubstitutionModel) DeleteSubstitution(substitutionID int) error {
+	stmt := table.Substitutions.DELETE().
+		WHERE(table.Substitutions.ID.EQ(helpers.PostgresInt(substitutionID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m SubstitutionModel) getSubstitutions(where postgres.BoolExpression) ([]*SubstitutionExt, error) {
+	substitute := table.Users.AS("substitute")
+	absentTeacher := table.Users.AS("absent_teacher")
+
+	query := postgres.SELECT(
+		table.Substitutions.AllColumns,
+		table.Journals.ID, table.Journals.Name,
+		table.Subjects.ID, table.Subjects.Name,
+		table.Lessons.ID, table.Lessons.Date, table.Lessons.Description,
+		substitute.ID, substitute.Name, substitute.Role,
+		absentTeacher.ID, absentTeacher.Name, absentTeacher.Role).
+		FROM(table.Substitutions.
+			INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.Substitutions.JournalID)).
+			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)).
+			LEFT_JOIN(table.Lessons, table.Lessons.ID.EQ(table.Substitutions.LessonID)).
+			INNER_JOIN(substitute, substitute.ID.EQ(table.Substitutions.SubstituteID)).
+			LEFT_JOIN(absentTeacher, absentTeacher.ID.EQ(table.Substitutions.AbsentTeacherID))).
+		WHERE(where).
+		ORDER_BY(table.Substitutions.StartDate.ASC(), table.Substitutions.ID.ASC())
+
+	var substitutions []*SubstitutionExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &substitutions)
+	if err != nil {
+		return nil, err
+	}
+
+	return substitutions, nil
+}
+
+func substitutionOverlaps(from, until *types.Date) postgres.BoolExpression {
+	return table.Substitutions.EndDate.GT_EQ(postgres.DateT(*from.Time)).
+		AND(table.Substitutions.StartDate.LT_EQ(postgres.DateT(*until.Time)))
+}
+
+func (m SubstitutionModel) GetSubstitutionsForJournal(journalID int) ([]*SubstitutionExt, error) {
+	return m.getSubstitutions(table.Substitutions.JournalID.EQ(helpers.PostgresInt(journalID)))
+}
+
+// substitutions the teacher is doing or has needed in the date range
+func (m SubstitutionModel) GetSubstitutionsForTeacher(teacherID int, from, until *types.Date) ([]*SubstitutionExt, error) {
+	return m.getSubstitutions(postgres.AND(
+		table.Substitutions.SubstituteID.EQ(helpers.PostgresInt(teacherID)).
+			OR(table.Substitutions.AbsentTeacherID.EQ(helpers.PostgresInt(teacherID))),
+		substitutionOverlaps(from, until),
+	))
+}
+
+func (m SubstitutionModel) GetSubstitutionsForStudent(studentID int, from, until *types.Date) ([]*SubstitutionExt, error) {
+	return m.getSubstitutions(postgres.AND(
+		postgres.EXISTS(
+			postgres.SELECT(postgres.Int32(1)).
+				FROM(table.StudentsJournals).
+				WHERE(table.StudentsJournals.JournalID.EQ(table.Substitutions.JournalID).
+					AND(table.StudentsJournals.StudentID.EQ(helpers.PostgresInt(studentID))))),
+		substitutionOverlaps(from, until),
+	))
+}
+
+// whether the lesson falls within one of the user's substitutions that hasn't expired yet
+func substituteCoversLesson(userID int) postgres.BoolExpression {
+	expiredBefore := time.Now().UTC().Add(-SubstitutionGracePeriod)
+
+	return postgres.EXISTS(
+		postgres.SELECT(postgres.Int32(1)).
+			FROM(table.Substitutions).
+			WHERE(postgres.AND(
+				table.Substitutions.JournalID.EQ(table.Lessons.JournalID),
+				table.Substitutions.SubstituteID.EQ(helpers.PostgresInt(userID)),
+				table.Substitutions.LessonID.IS_NULL().OR(table.Substitutions.LessonID.EQ(table.Lessons.ID)),
+				table.Lessons.Date.BETWEEN(table.Substitutions.StartDate, table.Substitutions.EndDate),
+				table.Substitutions.EndDate.GT_EQ(postgres.DateT(expiredBefore)),
+			)))
+}
+
+func (m SubstitutionModel) IsUserSubstituteForLesson(userID, lessonID int) (bool, error) {
+	query := postgres.SELECT(postgres.COUNT(postgres.Int32(1))).
+		FROM(table.Lessons).
+		WHERE(table.Lessons.ID.EQ(helpers.PostgresInt(lessonID)).
+			AND(substituteCoversLesson(userID)))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result[0] > 0, nil
+}
+
+func (m SubstitutionModel) IsUserSubstituteInJournal(userID, journalID int) (bool, error) {
+	expiredBefore := time.Now().UTC().Add(-SubstitutionGracePeriod)
+
+	query := postgres.SELECT(postgres.COUNT(postgres.Int32(1))).
+		FROM(table.Substitutions).
+		WHERE(postgres.AND(
+			table.Substitutions.JournalID.EQ(helpers.PostgresInt(journalID)),
+			table.Substitutions.SubstituteID.EQ(helpers.PostgresInt(userID)),
+			table.Substitutions.EndDate.GT_EQ(postgres.DateT(expiredBefore)),
+		))
+
+	var result []int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &result)
+	if err != nil {
+		return false, err
+	}
+
+	return result[0] > 0, nil
+}
+
+// lessons of the journal's course the substitute covers
+func (m SubstitutionModel) GetSubstituteLessonsByJournalID(userID, journalID, course int) ([]*LessonExt, error) {
+	query := postgres.SELECT(table.Lessons.AllColumns).
+		FROM(table.Lessons).
+		WHERE(postgres.AND(
+			table.Lessons.JournalID.EQ(helpers.PostgresInt(journalID)),
+			table.Lessons.Course.EQ(helpers.PostgresInt(course)),
+			substituteCoversLesson(userID),
+		)).
+		ORDER_BY(table.Lessons.Date.DESC())
+
+	var lessons []*LessonExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &lessons)
+	if err != nil {
+		return nil, err
+	}
+
+	return lessons, nil
+}
+
+// number of substitutions and lessons covered by each substitute in the date range
+func (m SubstitutionModel) GetSubstitutionReport(from, until *types.Date) ([]*SubstitutionReport, error) {
+	substitute := table.Users.AS("substitute")
+
+	query := postgres.SELECT(
+		substitute.ID, substitute.Name, substitute.Role,
+		postgres.COUNT(postgres.DISTINCT(table.Substitutions.ID)).AS("substitution_report.substitutions"),
+		postgres.COUNT(postgres.DISTINCT(table.Lessons.ID)).AS("substitution_report.lessons")).
+		FROM(table.Substitutions.
+			INNER_JOIN(substitute, substitute.ID.EQ(table.Substitutions.SubstituteID)).
+			LEFT_JOIN(table.Lessons, postgres.AND(
+				table.Lessons.JournalID.EQ(table.Substitutions.JournalID),
+				table.Substitutions.LessonID.IS_NULL().OR(table.Substitutions.LessonID.EQ(table.Lessons.ID)),
+				table.Lessons.Date.BETWEEN(table.Substitutions.StartDate, table.Substitutions.EndDate),
+				table.Lessons.Date.BETWEEN(postgres.DateT(*from.Time), postgres.DateT(*until.Time)),
+			))).
+		WHERE(substitutionOverlaps(from, until)).
+		GROUP_BY(substitute.ID).
+		ORDER_BY(substitute.Name.ASC())
+
+	var report []*SubstitutionReport
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &report)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,447 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/model"
+	"github.com/annusingmar/lavurso-backend/internal/data/gen/lavurso/public/table"
+	"github.com/annusingmar/lavurso-backend/internal/helpers"
+	"github.com/annusingmar/lavurso-backend/internal/types"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNoSuchRoom          = errors.New("no such room")
+	ErrRoomNameExists      = errors.New("room with this name already exists")
+	ErrNoSuchPeriod        = errors.New("no such period")
+	ErrPeriodNumberExists  = errors.New("period with this number already exists")
+	ErrNoSuchTimetableSlot = errors.New("no such timetable slot")
+	ErrJournalSlotTaken    = errors.New("journal already has a lesson in this slot")
+	ErrTeacherSlotTaken    = errors.New("teacher already has a lesson in this slot")
+	ErrRoomSlotTaken       = errors.New("room is already booked in this slot")
+)
+
+type Room = model.Rooms
+
+type Period = model.Periods
+
+type TimetableSlot = model.TimetableSlots
+
+type TimetableSlotExt struct {
+	TimetableSlot
+	Journal *Journal `json:"journal,omitempty"`
+	Subject *Subject `json:"subject,omitempty"`
+	Period  *Period  `json:"period,omitempty"`
+	Room    *Room    `json:"room,omitempty"`
+	Teacher *User    `json:"teacher,omitempty" alias:"teacher"`
+}
+
+type TimetableModel struct {
+	DB *sql.DB
+}
+
+// ROOMS
+
+func (m TimetableModel) AllRooms() ([]*Room, error) {
+	query := postgres.SELECT(table.Rooms.AllColumns).
+		FROM(table.Rooms).
+		ORDER_BY(table.Rooms.Name.ASC())
+
+	var rooms []*Room
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &rooms)
+	if err != nil {
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+func (m TimetableModel) GetRoomByID(roomID int) (*Room, error) {
+	query := postgres.SELECT(table.Rooms.AllColumns).
+		FROM(table.Rooms).
+		WHERE(table.Rooms.ID.EQ(helpers.PostgresInt(roomID)))
+
+	var room Room
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &room)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchRoom
+		default:
+			return nil, err
+		}
+	}
+
+	return &room, nil
+}
+
+func (m TimetableModel) InsertRoom(r *Room) error {
+	stmt := table.Rooms.INSERT(table.Rooms.MutableColumns).
+		MODEL(r).
+		RETURNING(table.Rooms.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, r)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrRoomNameExists
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m TimetableModel) UpdateRoom(r *Room) error {
+	stmt := table.Rooms.UPDATE(table.Rooms.MutableColumns).
+		MODEL(r).
+		WHERE(table.Rooms.ID.EQ(helpers.PostgresInt(r.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrRoomNameExists
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m TimetableModel) DeleteRoom(roomID int) error {
+	stmt := table.Rooms.DELETE().
+		WHERE(table.Rooms.ID.EQ(helpers.PostgresInt(roomID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PERIODS
+
+func (m TimetableModel) AllPeriods() ([]*Period, error) {
+	query := postgres.SELECT(table.Periods.AllColumns).
+		FROM(table.Periods).
+		ORDER_BY(table.Periods.Number.ASC())
+
+	var periods []*Period
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &periods)
+	if err != nil {
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+func (m TimetableModel) GetPeriodByID(periodID int) (*Period, error) {
+	query := postgres.SELECT(table.Periods.AllColumns).
+		FROM(table.Periods).
+		WHERE(table.Periods.ID.EQ(helpers.PostgresInt(periodID)))
+
+	var period Period
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &period)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchPeriod
+		default:
+			return nil, err
+		}
+	}
+
+	return &period, nil
+}
+
+func (m TimetableModel) InsertPeriod(p *Period) error {
+	stmt := table.Periods.INSERT(table.Periods.MutableColumns).
+		MODEL(p).
+		RETURNING(table.Periods.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, p)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrPeriodNumberExists
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m TimetableModel) UpdatePeriod(p *Period) error {
+	stmt := table.Periods.UPDATE(table.Periods.MutableColumns).
+		MODEL(p).
+		WHERE(table.Periods.ID.EQ(helpers.PostgresInt(p.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrPeriodNumberExists
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m TimetableModel) DeletePeriod(periodID int) error {
+	stmt := table.Periods.DELETE().
+		WHERE(table.Periods.ID.EQ(helpers.PostgresInt(periodID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SLOTS
+
+func (m TimetableModel) GetSlotByID(slotID int) (*TimetableSlot, error) {
+	query := postgres.SELECT(table.TimetableSlots.AllColumns).
+		FROM(table.TimetableSlots).
+		WHERE(table.TimetableSlots.ID.EQ(helpers.PostgresInt(slotID)))
+
+	var slot TimetableSlot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &slot)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchTimetableSlot
+		default:
+			return nil, err
+		}
+	}
+
+	return &slot, nil
+}
+
+func (m TimetableModel) InsertSlot(s *TimetableSlot) error {
+	stmt := table.TimetableSlots.INSERT(table.TimetableSlots.MutableColumns).
+		MODEL(s).
+		RETURNING(table.TimetableSlots.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, s)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			switch pgErr.ConstraintName {
+			case "timetable_slots_journal_unique":
+				return ErrJournalSlotTaken
+			case "timetable_slots_teacher_unique":
+				return ErrTeacherSlotTaken
+			case "timetable_slots_room_unique":
+				return ErrRoomSlotTaken
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m TimetableModel) DeleteSlot(slotID int) error {
+	stmt := table.TimetableSlots.DELETE().
+		WHERE(table.TimetableSlots.ID.EQ(helpers.PostgresInt(slotID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m TimetableModel) getTimetable(where postgres.BoolExpression) ([]*TimetableSlotExt, error) {
+	teacher := table.Users.AS("teacher")
+
+	query := postgres.SELECT(
+		table.TimetableSlots.AllColumns,
+		table.Journals.ID, table.Journals.Name,
+		table.Subjects.ID, table.Subjects.Name,
+		table.Periods.AllColumns,
+		table.Rooms.AllColumns,
+		teacher.ID, teacher.Name,
+	).
+		FROM(table.TimetableSlots.
+			INNER_JOIN(table.Journals, table.Journals.ID.EQ(table.TimetableSlots.JournalID)).
+			INNER_JOIN(table.Subjects, table.Subjects.ID.EQ(table.Journals.SubjectID)).
+			INNER_JOIN(table.Periods, table.Periods.ID.EQ(table.TimetableSlots.PeriodID)).
+			INNER_JOIN(teacher, teacher.ID.EQ(table.TimetableSlots.TeacherID)).
+			LEFT_JOIN(table.Rooms, table.Rooms.ID.EQ(table.TimetableSlots.RoomID))).
+		WHERE(where).
+		ORDER_BY(table.TimetableSlots.Weekday.ASC(), table.Periods.Number.ASC())
+
+	var slots []*TimetableSlotExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &slots)
+	if err != nil {
+		return nil, err
+	}
+
+	return slots, nil
+}
+
+func (m TimetableModel) GetTimetableForJournal(journalID int) ([]*TimetableSlotExt, error) {
+	return m.getTimetable(table.TimetableSlots.JournalID.EQ(helpers.PostgresInt(journalID)))
+}
+
+func (m TimetableModel) GetTimetableForTeacher(teacherID, yearID int) ([]*TimetableSlotExt, error) {
+	return m.getTimetable(table.TimetableSlots.TeacherID.EQ(helpers.PostgresInt(teacherID)).
+		AND(table.Journals.YearID.EQ(helpers.PostgresInt(yearID))))
+}
+
+func (m TimetableModel) GetTimetableForClass(classID, yearID int) ([]*TimetableSlotExt, error) {
+	classJournal := postgres.EXISTS(
+		postgres.SELECT(postgres.Int32(1)).
+			FROM(table.StudentsJournals.
+				INNER_JOIN(table.Users, table.Users.ID.EQ(table.StudentsJournals.StudentID))).
+			WHERE(table.StudentsJournals.JournalID.EQ(table.Journals.ID).
+				AND(table.Users.ClassID.EQ(helpers.PostgresInt(classID)))),
+	)
+
+	return m.getTimetable(classJournal.AND(table.Journals.YearID.EQ(helpers.PostgresInt(yearID))))
+}
+
+// creates lessons for every slot of the journal between from and until, leaving out
+// the skipped dates and dates that already have a lesson generated for the slot.
+// The range is cut to the journal's school year if the year has its dates set
+func (m TimetableModel) GenerateLessonsForJournal(journalID, course int, year *Year, from, until time.Time, skip []time.Time) (int, error) {
+	if year.StartDate != nil && year.StartDate.Time != nil && from.Before(*year.StartDate.Time) {
+		from = *year.StartDate.Time
+	}
+	if year.EndDate != nil && year.EndDate.Time != nil && until.After(*year.EndDate.Time) {
+		until = *year.EndDate.Time
+	}
+
+	slots, err := m.GetTimetableForJournal(journalID)
+	if err != nil {
+		return 0, err
+	}
+
+	skipped := make(map[string]bool)
+	for _, d := range skip {
+		skipped[d.Format("2006-01-02")] = true
+	}
+
+	now := time.Now().UTC()
+
+	var lessons []*Lesson
+
+	for day := from; !day.After(until); day = day.AddDate(0, 0, 1) {
+		if skipped[day.Format("2006-01-02")] {
+			continue
+		}
+
+		weekday := int(day.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+
+		for _, s := range slots {
+			if *s.Weekday != weekday {
+				continue
+			}
+
+			lessons = append(lessons, &Lesson{
+				JournalID:       &journalID,
+				Description:     helpers.ToPtr(""),
+				Date:            &types.Date{Time: helpers.ToPtr(day)},
+				Course:          &course,
+				CreatedAt:       &now,
+				UpdatedAt:       &now,
+				TimetableSlotID: &s.ID,
+				StartTime:       s.Period.StartTime,
+				EndTime:         s.Period.EndTime,
+			})
+		}
+	}
+
+	if len(lessons) == 0 {
+		return 0, nil
+	}
+
+	stmt := table.Lessons.INSERT(table.Lessons.MutableColumns).
+		MODELS(lessons).
+		ON_CONFLICT(table.Lessons.TimetableSlotID, table.Lessons.Date).
+		DO_NOTHING()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return 0, err
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(created), nil
+}
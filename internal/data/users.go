@@ -268,6 +268,42 @@ func (m UserModel) GetAllStudentIDs() ([]int, error) {
 func (m UserModel) GetUserBySessionToken(plaintextToken string) (*UserExt, error) {
 	hash := sha256.Sum256([]byte(plaintextToken))
 
+	return m.getUserBySession(table.Sessions.Token.EQ(postgres.Bytea(hash[:])))
+}
+
+// the user of the session if it is still valid, for checking a long running request again
+func (m UserModel) GetUserBySessionID(sessionID int) (*UserExt, error) {
+	return m.getUserBySession(table.Sessions.ID.EQ(helpers.PostgresInt(sessionID)))
+}
+
+// uses up the event stream ticket and returns the user of the session it was made for
+func (m UserModel) GetUserByEventsTicket(plaintextTicket string) (*UserExt, error) {
+	hash := sha256.Sum256([]byte(plaintextTicket))
+
+	stmt := table.EventsTickets.DELETE().
+		WHERE(table.EventsTickets.Token.EQ(postgres.Bytea(hash[:])).
+			AND(table.EventsTickets.Expires.GT(postgres.TimestampzT(time.Now().UTC())))).
+		RETURNING(table.EventsTickets.SessionID)
+
+	var ticket model.EventsTickets
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := stmt.QueryContext(ctx, m.DB, &ticket)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	return m.getUserBySession(table.Sessions.ID.EQ(helpers.PostgresInt(*ticket.SessionID)))
+}
+
+func (m UserModel) getUserBySession(session postgres.BoolExpression) (*UserExt, error) {
 	query := postgres.SELECT(table.Users.AllColumns, table.Classes.Name, table.Sessions.ID).
 		FROM(table.Users.
 			LEFT_JOIN(table.Classes, table.Classes.ID.EQ(table.Users.ClassID)).
@@ -275,7 +311,7 @@ func (m UserModel) GetUserBySessionToken(plaintextToken string) (*UserExt, error
 		WHERE(postgres.AND(
 			table.Users.Archived.IS_FALSE(),
 			table.Users.Active.IS_TRUE(),
-			table.Sessions.Token.EQ(postgres.Bytea(hash[:])),
+			session,
 			table.Sessions.Expires.GT(postgres.TimestampzT(time.Now().UTC()))))
 
 	var user UserExt
@@ -415,7 +451,7 @@ func (m UserModel) GetParentsForChild(childID int) ([]*UserExt, error) {
 }
 
 func (m UserModel) GetChildrenForParent(parentID int) ([]*UserExt, error) {
-	query := postgres.SELECT(table.Users.ID, table.Users.Name, table.Users.Role).
+	query := postgres.SELECT(table.Users.ID, table.Users.Name, table.Users.Role, table.Users.ClassID).
 		FROM(table.Users.
 			INNER_JOIN(table.ParentsChildren, table.ParentsChildren.ChildID.EQ(table.Users.ID))).
 		WHERE(table.ParentsChildren.ParentID.EQ(helpers.PostgresInt(parentID)))
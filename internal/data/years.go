@@ -13,7 +13,10 @@ import (
 	"github.com/go-jet/jet/v2/qrm"
 )
 
-var ErrNoCurrentYear = errors.New("no current year set")
+var (
+	ErrNoCurrentYear = errors.New("no current year set")
+	ErrNoSuchYear    = errors.New("no such year")
+)
 
 type Year = model.Years
 
@@ -77,6 +80,45 @@ func (m YearModel) ListAllYearsWithStats() ([]*YearExt, error) {
 	return years, nil
 }
 
+func (m YearModel) GetYearByID(yearID int) (*YearExt, error) {
+	query := postgres.SELECT(table.Years.AllColumns).
+		FROM(table.Years).
+		WHERE(table.Years.ID.EQ(helpers.PostgresInt(yearID)))
+
+	var year YearExt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := query.QueryContext(ctx, m.DB, &year)
+	if err != nil {
+		switch {
+		case errors.Is(err, qrm.ErrNoRows):
+			return nil, ErrNoSuchYear
+		default:
+			return nil, err
+		}
+	}
+
+	return &year, nil
+}
+
+func (m YearModel) UpdateYear(y *Year) error {
+	stmt := table.Years.UPDATE(table.Years.DisplayName, table.Years.StartDate, table.Years.EndDate).
+		MODEL(y).
+		WHERE(table.Years.ID.EQ(helpers.PostgresInt(y.ID)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := stmt.ExecContext(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m YearModel) GetAllYearIDs() ([]int, error) {
 	query := postgres.SELECT(table.Years.ID).
 		FROM(table.Years)
@@ -0,0 +1,106 @@
+package ical
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405"
+	maxLineOctets  = 75
+)
+
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	// start and end are dates only, end is exclusive
+	AllDay bool
+}
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []*Event
+}
+
+func (c *Calendar) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	stamp := time.Now().UTC().Format(dateTimeFormat) + "Z"
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+escape(c.ProdID))
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escape(c.Name))
+	}
+
+	for _, e := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+escape(e.UID))
+		writeLine(&b, "DTSTAMP:"+stamp)
+		if e.AllDay {
+			writeLine(&b, "DTSTART;VALUE=DATE:"+e.Start.Format(dateFormat))
+			writeLine(&b, "DTEND;VALUE=DATE:"+e.End.Format(dateFormat))
+		} else {
+			// floating time, shown in the subscriber's local time zone
+			writeLine(&b, "DTSTART:"+e.Start.Format(dateTimeFormat))
+			writeLine(&b, "DTEND:"+e.End.Format(dateTimeFormat))
+		}
+		writeLine(&b, "SUMMARY:"+escape(e.Summary))
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escape(e.Description))
+		}
+		if e.Location != "" {
+			writeLine(&b, "LOCATION:"+escape(e.Location))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// escapes TEXT values as described in RFC 5545 section 3.3.11
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(s)
+}
+
+// writes a content line, folding it so that no line exceeds 75 octets
+// without splitting a multi-byte character
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+const timeout = 10 * time.Second
+
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTP(host string, port int, username, password, from string) (*SMTP, error) {
+	_, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	return &SMTP{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}, nil
+}
+
+// sends a plain text email, using STARTTLS when the server offers it, replyTo can be empty
+func (s *SMTP) Send(to, replyTo, subject, body string) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.Port)), timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: s.Host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Username != "" {
+		err = c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.Mail(from.Address)
+	if err != nil {
+		return err
+	}
+
+	err = c.Rcpt(to)
+	if err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	if replyTo != "" {
+		fmt.Fprintf(&msg, "Reply-To: %s\r\n", replyTo)
+	}
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&msg, "Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(body)
+
+	_, err = w.Write(msg.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,310 @@
+package mailer
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	replyPrefix    = "reply+"
+	signatureBytes = 10
+	maxPartSize    = 1 << 20
+)
+
+var (
+	ErrInvalidReplyAddress = errors.New("invalid reply address")
+	ErrNoReplyAddress      = errors.New("no reply address among recipients")
+	ErrNoTextBody          = errors.New("no plain text body")
+)
+
+// where quoted text starts in a reply, everything from the line on is dropped
+var quoteStart = []*regexp.Regexp{
+	regexp.MustCompile(`^>`),
+	regexp.MustCompile(`^On .*wrote:$`),
+	regexp.MustCompile(`^-{2,}\s*Original Message\s*-{2,}$`),
+	regexp.MustCompile(`^_{10,}$`),
+	regexp.MustCompile(`^From: .*$`),
+	regexp.MustCompile(`^-- ?$`),
+	regexp.MustCompile(`^Sent from my .*$`),
+}
+
+type InboundMessage struct {
+	From       string
+	Recipients []string
+	Text       string
+	// Authentication-Results headers (RFC 8601), as added by the receiving mail servers
+	AuthResults []string
+}
+
+// a reply address for the user in the thread, signed so it can't be guessed for other threads or users
+func ReplyAddress(secret, domain string, threadID, userID int) string {
+	payload := fmt.Sprintf("%d.%d", threadID, userID)
+	return fmt.Sprintf("%s%s.%s@%s", replyPrefix, payload, replySignature(secret, payload), domain)
+}
+
+func ParseReplyAddress(secret, domain, address string) (threadID, userID int, err error) {
+	local, host, ok := strings.Cut(strings.ToLower(address), "@")
+	if !ok || host != strings.ToLower(domain) || !strings.HasPrefix(local, replyPrefix) {
+		return 0, 0, ErrInvalidReplyAddress
+	}
+
+	parts := strings.Split(strings.TrimPrefix(local, replyPrefix), ".")
+	if len(parts) != 3 {
+		return 0, 0, ErrInvalidReplyAddress
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(replySignature(secret, payload))) {
+		return 0, 0, ErrInvalidReplyAddress
+	}
+
+	threadID, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, ErrInvalidReplyAddress
+	}
+
+	userID, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, ErrInvalidReplyAddress
+	}
+
+	return threadID, userID, nil
+}
+
+// hex, as mail servers don't always keep the case of the local part
+func replySignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil)[:signatureBytes])
+}
+
+// finds the reply address the message was sent to
+func (m *InboundMessage) ReplyTarget(secret, domain string) (threadID, userID int, err error) {
+	for _, r := range m.Recipients {
+		threadID, userID, err = ParseReplyAddress(secret, domain, r)
+		if err == nil {
+			return threadID, userID, nil
+		}
+	}
+
+	return 0, 0, ErrNoReplyAddress
+}
+
+// parses a raw RFC 5322 message, keeping only the sender, the recipients and the plain text body
+func ParseInbound(r io.Reader) (*InboundMessage, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []string
+	for _, h := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		addresses, err := msg.Header.AddressList(h)
+		if err != nil {
+			continue
+		}
+		for _, a := range addresses {
+			recipients = append(recipients, a.Address)
+		}
+	}
+
+	text, err := textBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InboundMessage{
+		From:        from.Address,
+		Recipients:  recipients,
+		Text:        text,
+		AuthResults: msg.Header["Authentication-Results"],
+	}, nil
+}
+
+// whether the mail server identified by authservID vouched for the From address, with DMARC passing
+// or DKIM or SPF passing for the From domain. Only the topmost header is trusted, as that is the one
+// our own server added, the sender can add any others themselves, even with our authserv-id
+func (m *InboundMessage) SenderVerified(authservID string) bool {
+	_, fromDomain, ok := strings.Cut(strings.ToLower(m.From), "@")
+	if !ok || authservID == "" || len(m.AuthResults) == 0 {
+		return false
+	}
+
+	results := strings.Split(stripComments(m.AuthResults[0]), ";")
+
+	// the authserv-id can be followed by a version
+	fields := strings.Fields(results[0])
+	if len(fields) == 0 || !strings.EqualFold(fields[0], authservID) {
+		return false
+	}
+
+	for _, result := range results[1:] {
+		fields := strings.Fields(strings.ToLower(result))
+		if len(fields) == 0 {
+			continue
+		}
+
+		method, verdict, _ := strings.Cut(fields[0], "=")
+		if verdict != "pass" {
+			continue
+		}
+
+		props := make(map[string]string)
+		for _, f := range fields[1:] {
+			key, value, _ := strings.Cut(f, "=")
+			props[key] = value
+		}
+
+		var domain string
+		switch method {
+		case "dmarc":
+			domain = props["header.from"]
+		case "dkim":
+			domain = props["header.d"]
+			if domain == "" {
+				_, domain, _ = strings.Cut(props["header.i"], "@")
+			}
+		case "spf":
+			domain = props["smtp.mailfrom"]
+			if _, d, ok := strings.Cut(domain, "@"); ok {
+				domain = d
+			}
+		}
+
+		if domain != "" && (fromDomain == domain || strings.HasSuffix(fromDomain, "."+domain)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removes (comments) from a header value
+func stripComments(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// the first text/plain part, looking into multipart messages
+func textBody(contentType, encoding string, body io.Reader) (string, error) {
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case mediaType == "text/plain":
+		text := decodeTransfer(encoding, body)
+
+		// without a charset it is US-ASCII, which is also valid UTF-8
+		if charset := strings.ToLower(params["charset"]); charset != "" && charset != "utf-8" && charset != "us-ascii" {
+			enc, err := htmlindex.Get(charset)
+			if err != nil {
+				return "", err
+			}
+			text = enc.NewDecoder().Reader(text)
+		}
+
+		b, err := io.ReadAll(io.LimitReader(text, maxPartSize))
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case strings.HasPrefix(mediaType, "multipart/"):
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return "", ErrNoTextBody
+			}
+			if err != nil {
+				return "", err
+			}
+
+			text, err := textBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err == nil {
+				return text, nil
+			}
+		}
+	}
+
+	return "", ErrNoTextBody
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		// the decoder skips the line breaks
+		return base64.NewDecoder(base64.StdEncoding, body)
+	}
+	return body
+}
+
+// cuts the reply off where the quoted message or the signature starts
+func StripReply(text string) string {
+	var lines []string
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(nil, maxPartSize)
+
+scan:
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		for _, re := range quoteStart {
+			if re.MatchString(line) {
+				break scan
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	reply := strings.TrimSpace(strings.Join(lines, "\n"))
+
+	// clients wrap a long "On ... wrote:" line, which leaves the start of it behind
+	if strings.HasSuffix(reply, "wrote:") {
+		i := strings.LastIndex(reply, "\n\n")
+		if i < 0 {
+			return ""
+		}
+		reply = strings.TrimSpace(reply[:i])
+	}
+
+	return reply
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Local struct {
+	Dir string
+}
+
+func NewLocal(dir string) (*Local, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Local{Dir: dir}, nil
+}
+
+func (l *Local) path(key string) string {
+	return filepath.Join(l.Dir, filepath.Base(key))
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	f, err := os.CreateTemp(l.Dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), l.path(key))
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(l.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	err := os.Remove(l.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (l *Local) PresignGet(key, fileName string, expiry time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3Service         = "s3"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3MaxPresign      = 7 * 24 * time.Hour
+)
+
+type S3 struct {
+	Endpoint  *url.URL
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+	Client    *http.Client
+}
+
+func NewS3(endpoint, region, bucket, accessKey, secretKey string, pathStyle bool) (*S3, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("no S3 bucket set")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3{
+		Endpoint:  u,
+		Region:    region,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		PathStyle: pathStyle,
+		Client:    &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.Endpoint
+	if s.PathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.Bucket + "/" + key
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	}
+	u.RawPath = ""
+	return &u
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3) PresignGet(key, fileName string, expiry time.Duration) (string, error) {
+	return s.presignGet(key, fileName, expiry, time.Now().UTC())
+}
+
+func (s *S3) presignGet(key, fileName string, expiry time.Duration, now time.Time) (string, error) {
+	if expiry <= 0 || expiry > s3MaxPresign {
+		return "", fmt.Errorf("invalid presign expiry %s", expiry)
+	}
+
+	u := s.objectURL(key)
+
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", s3Algorithm)
+	q.Set("X-Amz-Credential", s.AccessKey+"/"+s.scope(now))
+	q.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	q.Set("X-Amz-SignedHeaders", "host")
+	if fileName != "" {
+		q.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+
+	canonicalQuery := canonicalQueryString(q)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		uriEncode(u.Path, false),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	signature := s.signature(now, canonicalRequest)
+
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+
+	return u.String(), nil
+}
+
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3: %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return resp, nil
+}
+
+// signs the host, content type, range and x-amz-* headers, the payload is left unsigned unless
+// X-Amz-Content-Sha256 is already set
+func (s *S3) sign(req *http.Request, now time.Time) {
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.Join(values, ",")
+		}
+	}
+
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQueryString(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		headers["x-amz-content-sha256"],
+	}, "\n")
+
+	signature := s.signature(now, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.AccessKey, s.scope(now), signedHeaders, signature))
+}
+
+func (s *S3) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.Region + "/" + s3Service + "/aws4_request"
+}
+
+func (s *S3) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format("20060102T150405Z"),
+		s.scope(t),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQueryString(q url.Values) string {
+	var keys []string
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := q[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// percent-encodes everything except RFC 3986 unreserved characters, as SigV4 requires
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
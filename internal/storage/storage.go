@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNotFound            = errors.New("object not found")
+	ErrPresignNotSupported = errors.New("presigned URLs not supported by storage backend")
+)
+
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	PresignGet(key, fileName string, expiry time.Duration) (string, error)
+}
+
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
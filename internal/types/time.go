@@ -0,0 +1,94 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidTimeFormat = errors.New("invalid time format")
+)
+
+type Time struct {
+	*time.Time
+}
+
+func ParseTime(s string) (*Time, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return nil, ErrInvalidTimeFormat
+	}
+
+	return &Time{&t}, nil
+}
+
+func (t *Time) String() string {
+	return t.Format("15:04")
+}
+
+func (t *Time) UnmarshalJSON(b []byte) error {
+	var ts string
+	if err := json.Unmarshal(b, &ts); err != nil {
+		return ErrInvalidTimeFormat
+	}
+
+	if ts == "" {
+		t.Time = nil
+		return nil
+	}
+
+	parsed, err := ParseTime(ts)
+	if err != nil {
+		return err
+	}
+
+	*t = *parsed
+	return nil
+}
+
+func (t *Time) MarshalJSON() ([]byte, error) {
+	var ft string
+
+	if t.Time != nil {
+		ft = t.String()
+	} else {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(ft)
+}
+
+func (t *Time) Scan(src any) error {
+	var s string
+
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case time.Time:
+		t.Time = &v
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into time", src)
+	}
+
+	parsed, err := time.Parse("15:04:05", s)
+	if err != nil {
+		return err
+	}
+
+	t.Time = &parsed
+	return nil
+}
+
+func (t Time) Value() (driver.Value, error) {
+	if t.Time == nil {
+		return nil, nil
+	}
+
+	return t.Format("15:04:05"), nil
+}
@@ -0,0 +1,360 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	timeout    = 10 * time.Second
+	recordSize = 4096
+	// what is left of a record for the payload after the padding delimiter and the auth tag
+	MaxPayloadSize = recordSize - 1 - 16
+)
+
+var (
+	ErrInvalidKey          = errors.New("invalid key")
+	ErrPayloadTooLarge     = errors.New("payload too large")
+	ErrSubscriptionExpired = errors.New("subscription expired")
+	ErrPrivateEndpoint     = errors.New("push endpoint is not a public address")
+)
+
+// shared address space (RFC 6598), not covered by netip's IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// checks that the keys the browser gave are usable
+func (s *Subscription) Validate() error {
+	p256dh, err := decodeBase64(s.P256dh)
+	if err != nil {
+		return ErrInvalidKey
+	}
+	_, err = ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return ErrInvalidKey
+	}
+
+	auth, err := decodeBase64(s.Auth)
+	if err != nil || len(auth) != 16 {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
+
+// sends push messages signed with the application server's VAPID key (RFC 8292).
+// Endpoints come from users, so only public addresses are connected to unless AllowPrivate is set
+type Client struct {
+	AllowPrivate bool
+
+	key       *ecdsa.PrivateKey
+	publicKey []byte
+	subject   string
+	http      *http.Client
+}
+
+// privateKey is the base64url encoded P-256 private key, subject a mailto: or https: URL
+// push services can contact the sender at
+func NewClient(privateKey, subject string) (*Client, error) {
+	d, err := decodeBase64(privateKey)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	ecdhKey, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	// uncompressed point, 0x04 || X || Y
+	publicKey := ecdhKey.PublicKey().Bytes()
+
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(publicKey[1:33]),
+			Y:     new(big.Int).SetBytes(publicKey[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}
+
+	c := &Client{
+		key:       key,
+		publicKey: publicKey,
+		subject:   subject,
+	}
+
+	// checked when connecting as well, the name could resolve differently than when subscribing
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !c.AllowPrivate && !publicAddr(addrPort.Addr()) {
+				return ErrPrivateEndpoint
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	c.http = &http.Client{Timeout: timeout, Transport: transport}
+
+	return c, nil
+}
+
+// checks that the endpoint's host only resolves to public addresses
+func (c *Client) CheckEndpoint(endpoint string) error {
+	if c.AllowPrivate {
+		return nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return ErrPrivateEndpoint
+		}
+	}
+
+	return nil
+}
+
+// whether the address is reachable on the internet, so not loopback, private, link-local and the like
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// generates a new base64url encoded private key
+func GeneratePrivateKey() (string, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// the key browsers need as applicationServerKey when subscribing
+func (c *Client) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(c.publicKey)
+}
+
+// encrypts and sends the payload, ErrSubscriptionExpired means the subscription should be removed
+func (c *Client) Send(s *Subscription, payload []byte, ttl time.Duration) error {
+	body, err := Encrypt(s.P256dh, s.Auth, payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	token, err := c.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, c.PublicKey()))
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusGone || resp.StatusCode == http.StatusNotFound:
+		return ErrSubscriptionExpired
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("push service responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+// a JWT signed with ES256 for the push service's origin
+func (c *Client) vapidToken(audience string) (string, error) {
+	header, err := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"aud": audience,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": c.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// encrypts the payload for the subscription's keys as a single aes128gcm record (RFC 8291, RFC 8188)
+func Encrypt(p256dh, auth string, payload []byte) ([]byte, error) {
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return encrypt(p256dh, auth, payload, asPrivate, salt)
+}
+
+// the application server's key pair and the salt are normally random, they are passed in for testing
+func encrypt(p256dh, auth string, payload []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	uaPublicBytes, err := decodeBase64(p256dh)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	authSecret, err := decodeBase64(auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, ErrInvalidKey
+	}
+
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	// "WebPush: info" || 0x00 || ua_public || as_public
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicBytes...)
+	keyInfo = append(keyInfo, asPublic...)
+
+	ikm := make([]byte, 32)
+	_, err = io.ReadFull(hkdf.New(sha256.New, ecdhSecret, authSecret, keyInfo), ikm)
+	if err != nil {
+		return nil, err
+	}
+
+	cek := make([]byte, 16)
+	_, err = io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: aes128gcm\x00")), cek)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 12)
+	_, err = io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: nonce\x00")), nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 marks the last record, no further padding
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	// salt || record size || key id length || key id (as_public) || ciphertext
+	var header bytes.Buffer
+	header.Write(salt)
+	binary.Write(&header, binary.BigEndian, uint32(recordSize))
+	header.WriteByte(byte(len(asPublic)))
+	header.Write(asPublic)
+
+	return gcm.Seal(header.Bytes(), nonce, plaintext, nil), nil
+}
+
+// browsers hand out keys base64url encoded, usually without padding
+func decodeBase64(s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return base64.URLEncoding.DecodeString(s)
+	}
+	return b, nil
+}
@@ -0,0 +1,73 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"net/netip"
+	"testing"
+)
+
+// the example from RFC 8291 appendix A
+func TestEncryptRFC8291(t *testing.T) {
+	const (
+		plaintext  = "V2hlbiBJIGdyb3cgdXAsIEkgd2FudCB0byBiZSBhIHdhdGVybWVsb24"
+		asPrivate  = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+		uaPublic   = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+		salt       = "DGv6ra1nlYgDCS1FRnbzlw"
+		authSecret = "BTBZMqHH6r4Tts7J_aSIgg"
+		expected   = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	)
+
+	payload, err := base64.RawURLEncoding.DecodeString(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asKeyBytes, err := base64.RawURLEncoding.DecodeString(asPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asKey, err := ecdh.P256().NewPrivateKey(asKeyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saltBytes, err := base64.RawURLEncoding.DecodeString(salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := encrypt(uaPublic, authSecret, payload, asKey, saltBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := base64.RawURLEncoding.EncodeToString(body); got != expected {
+		t.Errorf("encrypted body\n got %s\nwant %s", got, expected)
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":              true,
+		"2606:4700::1111":      true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.100.100.200":      false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:169.254.169.2": false,
+	}
+
+	for addr, public := range tests {
+		if got := publicAddr(netip.MustParseAddr(addr)); got != public {
+			t.Errorf("publicAddr(%s) = %t, want %t", addr, got, public)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+ALTER TABLE "excuses" ADD "status" text NOT NULL DEFAULT 'accepted';
+
+ALTER TABLE "excuses" ALTER "status" SET DEFAULT 'pending';
+
+ALTER TABLE "excuses" ADD "reviewer_id" integer;
+
+ALTER TABLE "excuses" ADD "review_comment" text;
+
+ALTER TABLE "excuses" ADD "reviewed_at" timestamptz;
+
+UPDATE "excuses" SET "reviewer_id" = "user_id", "reviewed_at" = "at";
+
+ALTER TABLE "excuses"
+    ADD CONSTRAINT "excuses_relation_3" FOREIGN KEY ("reviewer_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION;
+
+ALTER TABLE "excuses"
+    ADD CONSTRAINT "excuse_valid_status" CHECK (status IN ('pending', 'accepted', 'rejected'));
+
+---- create above / drop below ----
+
+ALTER TABLE "excuses" DROP CONSTRAINT "excuse_valid_status";
+
+ALTER TABLE "excuses" DROP "reviewed_at";
+
+ALTER TABLE "excuses" DROP "review_comment";
+
+ALTER TABLE "excuses" DROP "reviewer_id";
+
+ALTER TABLE "excuses" DROP "status";
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE "absence_notices" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "student_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "start_date" date NOT NULL,
+    "end_date" date NOT NULL,
+    "reason" text NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    CHECK ("start_date" <= "end_date")
+);
+
+ALTER TABLE "absence_notices"
+    ADD CONSTRAINT "absence_notices_relation_1" FOREIGN KEY ("student_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "absence_notices"
+    ADD CONSTRAINT "absence_notices_relation_2" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX absence_notices_student_dates_idx ON absence_notices (student_id, start_date, end_date);
+
+---- create above / drop below ----
+
+DROP TABLE "absence_notices";
\ No newline at end of file
@@ -0,0 +1,38 @@
+CREATE TABLE "attachments" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "user_id" integer NOT NULL,
+    "file_name" text NOT NULL,
+    "content_type" text NOT NULL,
+    "size" integer NOT NULL,
+    "storage_key" text NOT NULL UNIQUE,
+    "excuse_id" integer,
+    "assignment_id" integer,
+    "lesson_id" integer,
+    "message_id" integer,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT "attachment_single_target" CHECK (num_nonnulls("excuse_id", "assignment_id", "lesson_id", "message_id") = 1)
+);
+
+ALTER TABLE "attachments"
+    ADD CONSTRAINT "attachments_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "attachments"
+    ADD CONSTRAINT "attachments_relation_2" FOREIGN KEY ("excuse_id") REFERENCES "excuses" ("mark_id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "attachments"
+    ADD CONSTRAINT "attachments_relation_3" FOREIGN KEY ("assignment_id") REFERENCES "assignments" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "attachments"
+    ADD CONSTRAINT "attachments_relation_4" FOREIGN KEY ("lesson_id") REFERENCES "lessons" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "attachments"
+    ADD CONSTRAINT "attachments_relation_5" FOREIGN KEY ("message_id") REFERENCES "messages" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX attachments_excuse_id_idx ON attachments (excuse_id);
+CREATE INDEX attachments_assignment_id_idx ON attachments (assignment_id);
+CREATE INDEX attachments_lesson_id_idx ON attachments (lesson_id);
+CREATE INDEX attachments_message_id_idx ON attachments (message_id);
+
+---- create above / drop below ----
+
+DROP TABLE "attachments";
\ No newline at end of file
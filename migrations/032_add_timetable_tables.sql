@@ -0,0 +1,56 @@
+CREATE TABLE "rooms" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "name" text NOT NULL UNIQUE
+);
+
+CREATE TABLE "periods" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "number" integer NOT NULL UNIQUE,
+    "start_time" time NOT NULL,
+    "end_time" time NOT NULL,
+    CHECK ("start_time" < "end_time")
+);
+
+CREATE TABLE "timetable_slots" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "journal_id" integer NOT NULL,
+    "weekday" integer NOT NULL,
+    "period_id" integer NOT NULL,
+    "teacher_id" integer NOT NULL,
+    "room_id" integer,
+    CHECK ("weekday" BETWEEN 1 AND 7),
+    CONSTRAINT "timetable_slots_journal_unique" UNIQUE ("journal_id", "weekday", "period_id"),
+    CONSTRAINT "timetable_slots_teacher_unique" UNIQUE ("teacher_id", "weekday", "period_id"),
+    CONSTRAINT "timetable_slots_room_unique" UNIQUE ("room_id", "weekday", "period_id")
+);
+
+ALTER TABLE "timetable_slots"
+    ADD CONSTRAINT "timetable_slots_relation_1" FOREIGN KEY ("journal_id") REFERENCES "journals" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "timetable_slots"
+    ADD CONSTRAINT "timetable_slots_relation_2" FOREIGN KEY ("period_id") REFERENCES "periods" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "timetable_slots"
+    ADD CONSTRAINT "timetable_slots_relation_3" FOREIGN KEY ("teacher_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "timetable_slots"
+    ADD CONSTRAINT "timetable_slots_relation_4" FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+ALTER TABLE "lessons"
+    ADD COLUMN "timetable_slot_id" integer;
+
+ALTER TABLE "lessons"
+    ADD CONSTRAINT "lessons_relation_2" FOREIGN KEY ("timetable_slot_id") REFERENCES "timetable_slots" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+ALTER TABLE "lessons"
+    ADD CONSTRAINT "lessons_timetable_slot_date_unique" UNIQUE ("timetable_slot_id", "date");
+
+---- create above / drop below ----
+
+ALTER TABLE "lessons" DROP COLUMN "timetable_slot_id";
+
+DROP TABLE "timetable_slots";
+
+DROP TABLE "periods";
+
+DROP TABLE "rooms";
\ No newline at end of file
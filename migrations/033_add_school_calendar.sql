@@ -0,0 +1,34 @@
+ALTER TABLE "years"
+    ADD COLUMN "start_date" date,
+    ADD COLUMN "end_date" date,
+    ADD CONSTRAINT "year_valid_dates" CHECK ("start_date" <= "end_date");
+
+CREATE TABLE "calendar_entries" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "year_id" integer NOT NULL,
+    "class_id" integer,
+    "type" text NOT NULL,
+    "title" text NOT NULL,
+    "description" text,
+    "start_date" date NOT NULL,
+    "end_date" date NOT NULL,
+    CONSTRAINT "calendar_entry_valid_type" CHECK ("type" IN ('holiday', 'vacation', 'event')),
+    CONSTRAINT "calendar_entry_valid_dates" CHECK ("start_date" <= "end_date"),
+    CONSTRAINT "calendar_entry_school_wide_days_off" CHECK ("type" = 'event' OR "class_id" IS NULL)
+);
+
+ALTER TABLE "calendar_entries"
+    ADD CONSTRAINT "calendar_entries_relation_1" FOREIGN KEY ("year_id") REFERENCES "years" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "calendar_entries"
+    ADD CONSTRAINT "calendar_entries_relation_2" FOREIGN KEY ("class_id") REFERENCES "classes" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX calendar_entries_year_dates_idx ON calendar_entries (year_id, start_date, end_date);
+
+---- create above / drop below ----
+
+DROP TABLE "calendar_entries";
+
+ALTER TABLE "years"
+    DROP COLUMN "start_date",
+    DROP COLUMN "end_date";
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE "calendar_feeds" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "user_id" integer NOT NULL,
+    "student_id" integer,
+    "token" bytea NOT NULL UNIQUE,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "last_accessed" timestamptz
+);
+
+ALTER TABLE "calendar_feeds"
+    ADD CONSTRAINT "calendar_feeds_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "calendar_feeds"
+    ADD CONSTRAINT "calendar_feeds_relation_2" FOREIGN KEY ("student_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+---- create above / drop below ----
+
+DROP TABLE "calendar_feeds";
\ No newline at end of file
@@ -0,0 +1,36 @@
+CREATE TABLE "substitutions" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "journal_id" integer NOT NULL,
+    "lesson_id" integer,
+    "substitute_id" integer NOT NULL,
+    "absent_teacher_id" integer,
+    "start_date" date NOT NULL,
+    "end_date" date NOT NULL,
+    "note" text,
+    "created_by" integer,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT "substitution_valid_dates" CHECK ("start_date" <= "end_date")
+);
+
+ALTER TABLE "substitutions"
+    ADD CONSTRAINT "substitutions_relation_1" FOREIGN KEY ("journal_id") REFERENCES "journals" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "substitutions"
+    ADD CONSTRAINT "substitutions_relation_2" FOREIGN KEY ("lesson_id") REFERENCES "lessons" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "substitutions"
+    ADD CONSTRAINT "substitutions_relation_3" FOREIGN KEY ("substitute_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "substitutions"
+    ADD CONSTRAINT "substitutions_relation_4" FOREIGN KEY ("absent_teacher_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+ALTER TABLE "substitutions"
+    ADD CONSTRAINT "substitutions_relation_5" FOREIGN KEY ("created_by") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX substitutions_journal_dates_idx ON substitutions (journal_id, start_date, end_date);
+
+CREATE INDEX substitutions_substitute_dates_idx ON substitutions (substitute_id, start_date, end_date);
+
+---- create above / drop below ----
+
+DROP TABLE "substitutions";
\ No newline at end of file
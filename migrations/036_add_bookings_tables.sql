@@ -0,0 +1,63 @@
+ALTER TABLE "lessons"
+    ADD COLUMN "start_time" time,
+    ADD COLUMN "end_time" time,
+    ADD CONSTRAINT "lesson_valid_times" CHECK (("start_time" IS NULL AND "end_time" IS NULL) OR "start_time" < "end_time");
+
+UPDATE "lessons"
+SET "start_time" = "periods"."start_time", "end_time" = "periods"."end_time"
+FROM "timetable_slots"
+INNER JOIN "periods" ON "periods"."id" = "timetable_slots"."period_id"
+WHERE "timetable_slots"."id" = "lessons"."timetable_slot_id";
+
+CREATE TABLE "resources" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "name" text NOT NULL UNIQUE,
+    "description" text
+);
+
+CREATE TABLE "bookings" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "room_id" integer,
+    "resource_id" integer,
+    "lesson_id" integer,
+    "class_id" integer,
+    "user_id" integer NOT NULL,
+    "title" text,
+    "date" date NOT NULL,
+    "start_time" time NOT NULL,
+    "end_time" time NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT "booking_single_target" CHECK (num_nonnulls("room_id", "resource_id") = 1),
+    CONSTRAINT "booking_valid_times" CHECK ("start_time" < "end_time")
+);
+
+ALTER TABLE "bookings"
+    ADD CONSTRAINT "bookings_relation_1" FOREIGN KEY ("room_id") REFERENCES "rooms" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "bookings"
+    ADD CONSTRAINT "bookings_relation_2" FOREIGN KEY ("resource_id") REFERENCES "resources" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "bookings"
+    ADD CONSTRAINT "bookings_relation_3" FOREIGN KEY ("lesson_id") REFERENCES "lessons" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "bookings"
+    ADD CONSTRAINT "bookings_relation_4" FOREIGN KEY ("class_id") REFERENCES "classes" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "bookings"
+    ADD CONSTRAINT "bookings_relation_5" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX bookings_room_date_idx ON bookings (room_id, date);
+
+CREATE INDEX bookings_resource_date_idx ON bookings (resource_id, date);
+
+CREATE INDEX bookings_user_date_idx ON bookings (user_id, date);
+
+---- create above / drop below ----
+
+DROP TABLE "bookings";
+
+DROP TABLE "resources";
+
+ALTER TABLE "lessons"
+    DROP COLUMN "start_time",
+    DROP COLUMN "end_time";
\ No newline at end of file
@@ -0,0 +1,47 @@
+CREATE TABLE "submissions" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "assignment_id" integer NOT NULL,
+    "student_id" integer NOT NULL,
+    "body" text,
+    "status" text NOT NULL,
+    "submitted_at" timestamptz NOT NULL DEFAULT NOW(),
+    "feedback" text,
+    "feedback_by" integer,
+    "feedback_at" timestamptz,
+    "mark_id" integer,
+    CONSTRAINT "submission_valid_status" CHECK ("status" IN ('submitted', 'returned')),
+    CONSTRAINT "submissions_assignment_student_unique" UNIQUE ("assignment_id", "student_id")
+);
+
+ALTER TABLE "submissions"
+    ADD CONSTRAINT "submissions_relation_1" FOREIGN KEY ("assignment_id") REFERENCES "assignments" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "submissions"
+    ADD CONSTRAINT "submissions_relation_2" FOREIGN KEY ("student_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "submissions"
+    ADD CONSTRAINT "submissions_relation_3" FOREIGN KEY ("feedback_by") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+ALTER TABLE "submissions"
+    ADD CONSTRAINT "submissions_relation_4" FOREIGN KEY ("mark_id") REFERENCES "marks" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+ALTER TABLE "attachments"
+    ADD COLUMN "submission_id" integer,
+    DROP CONSTRAINT "attachment_single_target",
+    ADD CONSTRAINT "attachment_single_target" CHECK (num_nonnulls("excuse_id", "assignment_id", "lesson_id", "message_id", "submission_id") = 1);
+
+ALTER TABLE "attachments"
+    ADD CONSTRAINT "attachments_relation_6" FOREIGN KEY ("submission_id") REFERENCES "submissions" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX attachments_submission_id_idx ON attachments (submission_id);
+
+---- create above / drop below ----
+
+DELETE FROM "attachments" WHERE "submission_id" IS NOT NULL;
+
+ALTER TABLE "attachments"
+    DROP CONSTRAINT "attachment_single_target",
+    DROP COLUMN "submission_id",
+    ADD CONSTRAINT "attachment_single_target" CHECK (num_nonnulls("excuse_id", "assignment_id", "lesson_id", "message_id") = 1);
+
+DROP TABLE "submissions";
\ No newline at end of file
@@ -0,0 +1,61 @@
+CREATE TABLE "journal_groups" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "journal_id" integer NOT NULL,
+    "name" text NOT NULL,
+    CONSTRAINT "journal_groups_journal_name_unique" UNIQUE ("journal_id", "name")
+);
+
+ALTER TABLE "journal_groups"
+    ADD CONSTRAINT "journal_groups_relation_1" FOREIGN KEY ("journal_id") REFERENCES "journals" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE TABLE "journal_groups_students" (
+    "group_id" integer NOT NULL,
+    "student_id" integer NOT NULL
+);
+
+ALTER TABLE "journal_groups_students"
+    ADD CONSTRAINT "journal_groups_students_pkey" PRIMARY KEY ("group_id", "student_id");
+
+ALTER TABLE "journal_groups_students"
+    ADD CONSTRAINT "journal_groups_students_relation_1" FOREIGN KEY ("group_id") REFERENCES "journal_groups" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "journal_groups_students"
+    ADD CONSTRAINT "journal_groups_students_relation_2" FOREIGN KEY ("student_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE TABLE "assignments_students" (
+    "assignment_id" integer NOT NULL,
+    "student_id" integer NOT NULL
+);
+
+ALTER TABLE "assignments_students"
+    ADD CONSTRAINT "assignments_students_pkey" PRIMARY KEY ("assignment_id", "student_id");
+
+ALTER TABLE "assignments_students"
+    ADD CONSTRAINT "assignments_students_relation_1" FOREIGN KEY ("assignment_id") REFERENCES "assignments" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "assignments_students"
+    ADD CONSTRAINT "assignments_students_relation_2" FOREIGN KEY ("student_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE TABLE "assignments_groups" (
+    "assignment_id" integer NOT NULL,
+    "group_id" integer NOT NULL
+);
+
+ALTER TABLE "assignments_groups"
+    ADD CONSTRAINT "assignments_groups_pkey" PRIMARY KEY ("assignment_id", "group_id");
+
+ALTER TABLE "assignments_groups"
+    ADD CONSTRAINT "assignments_groups_relation_1" FOREIGN KEY ("assignment_id") REFERENCES "assignments" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "assignments_groups"
+    ADD CONSTRAINT "assignments_groups_relation_2" FOREIGN KEY ("group_id") REFERENCES "journal_groups" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+---- create above / drop below ----
+
+DROP TABLE "assignments_groups";
+
+DROP TABLE "assignments_students";
+
+DROP TABLE "journal_groups_students";
+
+DROP TABLE "journal_groups";
\ No newline at end of file
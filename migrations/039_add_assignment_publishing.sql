@@ -0,0 +1,36 @@
+ALTER TABLE "assignments"
+    ADD COLUMN "draft" boolean NOT NULL DEFAULT FALSE,
+    ADD COLUMN "publish_at" timestamptz,
+    ADD COLUMN "published_at" timestamptz;
+
+UPDATE "assignments" SET "published_at" = "created_at";
+
+CREATE INDEX assignments_unpublished_idx ON assignments (publish_at) WHERE published_at IS NULL;
+
+CREATE TABLE "notifications" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "user_id" integer NOT NULL,
+    "type" text NOT NULL,
+    "title" text NOT NULL,
+    "body" text,
+    "assignment_id" integer,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "read_at" timestamptz
+);
+
+ALTER TABLE "notifications"
+    ADD CONSTRAINT "notifications_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "notifications"
+    ADD CONSTRAINT "notifications_relation_2" FOREIGN KEY ("assignment_id") REFERENCES "assignments" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, created_at);
+
+---- create above / drop below ----
+
+DROP TABLE "notifications";
+
+ALTER TABLE "assignments"
+    DROP COLUMN "draft",
+    DROP COLUMN "publish_at",
+    DROP COLUMN "published_at";
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE "messages_read" (
+    "thread_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "message_id" integer NOT NULL,
+    "read_at" timestamptz NOT NULL
+);
+
+ALTER TABLE "messages_read"
+    ADD CONSTRAINT "messages_read_pkey" PRIMARY KEY ("thread_id", "user_id");
+
+ALTER TABLE "messages_read"
+    ADD CONSTRAINT "messages_read_relation_1" FOREIGN KEY ("thread_id") REFERENCES "threads" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "messages_read"
+    ADD CONSTRAINT "messages_read_relation_2" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "messages_read"
+    ADD CONSTRAINT "messages_read_relation_3" FOREIGN KEY ("message_id") REFERENCES "messages" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+---- create above / drop below ----
+
+DROP TABLE "messages_read";
\ No newline at end of file
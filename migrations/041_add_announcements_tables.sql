@@ -0,0 +1,67 @@
+CREATE TABLE "announcements" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "user_id" integer,
+    "title" text NOT NULL,
+    "body" text NOT NULL,
+    "publish_at" timestamptz NOT NULL,
+    "expires_at" timestamptz,
+    "requires_ack" boolean NOT NULL DEFAULT FALSE,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "updated_at" timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT "announcement_expires_after_publish" CHECK ("expires_at" IS NULL OR "expires_at" > "publish_at")
+);
+
+ALTER TABLE "announcements"
+    ADD CONSTRAINT "announcements_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX announcements_publish_at_idx ON announcements (publish_at);
+
+CREATE TABLE "announcements_audiences" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "announcement_id" integer NOT NULL,
+    "type" text NOT NULL,
+    "role" text,
+    "class_id" integer,
+    "group_id" integer,
+    CONSTRAINT "announcement_audience_valid_type" CHECK (
+        ("type" = 'everyone' AND num_nonnulls("role", "class_id", "group_id") = 0) OR
+        ("type" = 'role' AND "role" IS NOT NULL AND num_nonnulls("class_id", "group_id") = 0) OR
+        ("type" IN ('class', 'class_parents') AND "class_id" IS NOT NULL AND num_nonnulls("role", "group_id") = 0) OR
+        ("type" = 'group' AND "group_id" IS NOT NULL AND num_nonnulls("role", "class_id") = 0)
+    )
+);
+
+ALTER TABLE "announcements_audiences"
+    ADD CONSTRAINT "announcements_audiences_relation_1" FOREIGN KEY ("announcement_id") REFERENCES "announcements" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "announcements_audiences"
+    ADD CONSTRAINT "announcements_audiences_relation_2" FOREIGN KEY ("class_id") REFERENCES "classes" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "announcements_audiences"
+    ADD CONSTRAINT "announcements_audiences_relation_3" FOREIGN KEY ("group_id") REFERENCES "groups" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX announcements_audiences_announcement_id_idx ON announcements_audiences (announcement_id);
+
+CREATE TABLE "announcements_read" (
+    "announcement_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "read_at" timestamptz NOT NULL,
+    "acknowledged_at" timestamptz
+);
+
+ALTER TABLE "announcements_read"
+    ADD CONSTRAINT "announcements_read_pkey" PRIMARY KEY ("announcement_id", "user_id");
+
+ALTER TABLE "announcements_read"
+    ADD CONSTRAINT "announcements_read_relation_1" FOREIGN KEY ("announcement_id") REFERENCES "announcements" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "announcements_read"
+    ADD CONSTRAINT "announcements_read_relation_2" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+---- create above / drop below ----
+
+DROP TABLE "announcements_read";
+
+DROP TABLE "announcements_audiences";
+
+DROP TABLE "announcements";
\ No newline at end of file
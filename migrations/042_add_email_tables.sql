@@ -0,0 +1,40 @@
+CREATE TABLE "notification_preferences" (
+    "user_id" integer NOT NULL,
+    "event_type" text NOT NULL,
+    "delivery" text NOT NULL,
+    CONSTRAINT "notification_preference_valid_delivery" CHECK ("delivery" IN ('immediate', 'digest', 'off'))
+);
+
+ALTER TABLE "notification_preferences"
+    ADD CONSTRAINT "notification_preferences_pkey" PRIMARY KEY ("user_id", "event_type");
+
+ALTER TABLE "notification_preferences"
+    ADD CONSTRAINT "notification_preferences_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE TABLE "email_outbox" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "user_id" integer NOT NULL,
+    "to_address" text NOT NULL,
+    "event_type" text NOT NULL,
+    "subject" text NOT NULL,
+    "body" text NOT NULL,
+    "digest" boolean NOT NULL DEFAULT FALSE,
+    "dedupe_key" text,
+    "send_after" timestamptz NOT NULL DEFAULT NOW(),
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    "sent_at" timestamptz,
+    "attempts" integer NOT NULL DEFAULT 0,
+    "last_error" text,
+    CONSTRAINT "email_outbox_dedupe_key_unique" UNIQUE ("dedupe_key")
+);
+
+ALTER TABLE "email_outbox"
+    ADD CONSTRAINT "email_outbox_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX email_outbox_unsent_idx ON email_outbox (send_after) WHERE sent_at IS NULL;
+
+---- create above / drop below ----
+
+DROP TABLE "email_outbox";
+
+DROP TABLE "notification_preferences";
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE "push_subscriptions" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "user_id" integer NOT NULL,
+    "session_id" integer NOT NULL,
+    "endpoint" text NOT NULL,
+    "p256dh" text NOT NULL,
+    "auth" text NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT "push_subscriptions_endpoint_unique" UNIQUE ("endpoint")
+);
+
+ALTER TABLE "push_subscriptions"
+    ADD CONSTRAINT "push_subscriptions_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "push_subscriptions"
+    ADD CONSTRAINT "push_subscriptions_relation_2" FOREIGN KEY ("session_id") REFERENCES "sessions" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+---- create above / drop below ----
+
+DROP TABLE "push_subscriptions";
\ No newline at end of file
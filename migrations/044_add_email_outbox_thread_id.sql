@@ -0,0 +1,10 @@
+ALTER TABLE "email_outbox"
+    ADD COLUMN "thread_id" integer;
+
+ALTER TABLE "email_outbox"
+    ADD CONSTRAINT "email_outbox_relation_2" FOREIGN KEY ("thread_id") REFERENCES "threads" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+---- create above / drop below ----
+
+ALTER TABLE "email_outbox"
+    DROP COLUMN "thread_id";
\ No newline at end of file
@@ -0,0 +1,29 @@
+CREATE TABLE "threads_rules" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "thread_id" integer NOT NULL,
+    "type" text NOT NULL,
+    "role" text,
+    "class_id" integer,
+    "journal_id" integer,
+    CONSTRAINT "thread_rule_valid_type" CHECK (
+        ("type" = 'role' AND "role" IS NOT NULL AND num_nonnulls("class_id", "journal_id") = 0) OR
+        ("type" IN ('class_parents', 'class_teachers') AND "class_id" IS NOT NULL AND num_nonnulls("role", "journal_id") = 0) OR
+        ("type" = 'journal_students' AND "journal_id" IS NOT NULL AND num_nonnulls("role", "class_id") = 0)
+    ),
+    UNIQUE NULLS NOT DISTINCT (thread_id, type, role, class_id, journal_id)
+);
+
+ALTER TABLE "threads_rules"
+    ADD CONSTRAINT "threads_rules_relation_1" FOREIGN KEY ("thread_id") REFERENCES "threads" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "threads_rules"
+    ADD CONSTRAINT "threads_rules_relation_2" FOREIGN KEY ("class_id") REFERENCES "classes" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "threads_rules"
+    ADD CONSTRAINT "threads_rules_relation_3" FOREIGN KEY ("journal_id") REFERENCES "journals" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX threads_rules_thread_id_idx ON threads_rules (thread_id);
+
+---- create above / drop below ----
+
+DROP TABLE "threads_rules";
\ No newline at end of file
@@ -0,0 +1,66 @@
+ALTER TABLE "messages"
+    ADD COLUMN "hidden_at" timestamp with time zone,
+    ADD COLUMN "hidden_by" integer;
+
+ALTER TABLE "messages"
+    ADD CONSTRAINT "messages_relation_3" FOREIGN KEY ("hidden_by") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE TABLE "messages_edits" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "message_id" integer NOT NULL,
+    "body" text NOT NULL,
+    "edited_at" timestamp with time zone NOT NULL
+);
+
+ALTER TABLE "messages_edits"
+    ADD CONSTRAINT "messages_edits_relation_1" FOREIGN KEY ("message_id") REFERENCES "messages" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX messages_edits_message_id_idx ON messages_edits (message_id);
+
+CREATE TABLE "messages_reports" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "message_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "reason" text NOT NULL,
+    "created_at" timestamp with time zone NOT NULL,
+    "resolved_by" integer,
+    "resolved_at" timestamp with time zone,
+    UNIQUE (message_id, user_id)
+);
+
+ALTER TABLE "messages_reports"
+    ADD CONSTRAINT "messages_reports_relation_1" FOREIGN KEY ("message_id") REFERENCES "messages" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "messages_reports"
+    ADD CONSTRAINT "messages_reports_relation_2" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "messages_reports"
+    ADD CONSTRAINT "messages_reports_relation_3" FOREIGN KEY ("resolved_by") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX messages_reports_open_idx ON messages_reports (created_at) WHERE resolved_at IS NULL;
+
+CREATE TABLE "messaging_mutes" (
+    "user_id" integer PRIMARY KEY,
+    "muted_by" integer,
+    "reason" text,
+    "muted_until" timestamp with time zone NOT NULL,
+    "created_at" timestamp with time zone NOT NULL
+);
+
+ALTER TABLE "messaging_mutes"
+    ADD CONSTRAINT "messaging_mutes_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "messaging_mutes"
+    ADD CONSTRAINT "messaging_mutes_relation_2" FOREIGN KEY ("muted_by") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+---- create above / drop below ----
+
+DROP TABLE "messaging_mutes";
+
+DROP TABLE "messages_reports";
+
+DROP TABLE "messages_edits";
+
+ALTER TABLE "messages"
+    DROP COLUMN "hidden_at",
+    DROP COLUMN "hidden_by";
\ No newline at end of file
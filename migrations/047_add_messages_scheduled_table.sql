@@ -0,0 +1,30 @@
+CREATE TABLE "messages_scheduled" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "user_id" integer NOT NULL,
+    "thread_id" integer,
+    "title" text,
+    "body" text NOT NULL,
+    "recipients" jsonb,
+    "draft" boolean NOT NULL DEFAULT FALSE,
+    "send_at" timestamptz,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "sent_at" timestamptz,
+    "error" text,
+    CONSTRAINT "scheduled_message_has_target" CHECK ("thread_id" IS NOT NULL OR "title" IS NOT NULL),
+    CONSTRAINT "scheduled_message_has_time" CHECK ("draft" OR "send_at" IS NOT NULL)
+);
+
+ALTER TABLE "messages_scheduled"
+    ADD CONSTRAINT "messages_scheduled_relation_1" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "messages_scheduled"
+    ADD CONSTRAINT "messages_scheduled_relation_2" FOREIGN KEY ("thread_id") REFERENCES "threads" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX messages_scheduled_unsent_idx ON messages_scheduled (send_at) WHERE sent_at IS NULL;
+
+CREATE INDEX messages_scheduled_user_id_idx ON messages_scheduled (user_id);
+
+---- create above / drop below ----
+
+DROP TABLE "messages_scheduled";
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE "threads_states" (
+    "thread_id" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "archived" boolean NOT NULL DEFAULT FALSE,
+    "muted" boolean NOT NULL DEFAULT FALSE,
+    "pinned" boolean NOT NULL DEFAULT FALSE,
+    PRIMARY KEY ("thread_id", "user_id")
+);
+
+ALTER TABLE "threads_states"
+    ADD CONSTRAINT "threads_states_relation_1" FOREIGN KEY ("thread_id") REFERENCES "threads" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE "threads_states"
+    ADD CONSTRAINT "threads_states_relation_2" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX threads_states_user_id_idx ON threads_states (user_id);
+
+---- create above / drop below ----
+
+DROP TABLE "threads_states";
\ No newline at end of file
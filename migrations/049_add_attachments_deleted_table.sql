@@ -0,0 +1,23 @@
+CREATE TABLE "attachments_deleted" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "storage_key" text NOT NULL,
+    "deleted_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+-- attachments also go when what they belong to is deleted, so their files are queued for removal here
+CREATE FUNCTION queue_attachment_file_removal() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO attachments_deleted (storage_key) VALUES (OLD.storage_key);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachments_queue_file_removal
+    AFTER DELETE ON "attachments"
+    FOR EACH ROW EXECUTE FUNCTION queue_attachment_file_removal();
+
+---- create above / drop below ----
+
+DROP TRIGGER "attachments_queue_file_removal" ON "attachments";
+DROP FUNCTION queue_attachment_file_removal();
+DROP TABLE "attachments_deleted";
\ No newline at end of file
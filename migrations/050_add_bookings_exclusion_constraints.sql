@@ -0,0 +1,14 @@
+CREATE EXTENSION btree_gist;
+
+-- the conflict check before inserting a booking can race, these keep rooms and resources from being double booked
+ALTER TABLE "bookings"
+    ADD CONSTRAINT "booking_no_room_overlap" EXCLUDE USING gist ("room_id" WITH =, tsrange("date" + "start_time", "date" + "end_time") WITH &&) WHERE ("room_id" IS NOT NULL),
+    ADD CONSTRAINT "booking_no_resource_overlap" EXCLUDE USING gist ("resource_id" WITH =, tsrange("date" + "start_time", "date" + "end_time") WITH &&) WHERE ("resource_id" IS NOT NULL);
+
+---- create above / drop below ----
+
+ALTER TABLE "bookings"
+    DROP CONSTRAINT "booking_no_room_overlap",
+    DROP CONSTRAINT "booking_no_resource_overlap";
+
+DROP EXTENSION btree_gist;
\ No newline at end of file
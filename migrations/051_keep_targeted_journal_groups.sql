@@ -0,0 +1,11 @@
+-- an assignment with no target groups left would go to the whole journal, so groups cant be deleted while assignments target them.
+-- NO ACTION rather than RESTRICT so deleting the journal, which removes its assignments too, still works
+ALTER TABLE "assignments_groups"
+    DROP CONSTRAINT "assignments_groups_relation_2",
+    ADD CONSTRAINT "assignments_groups_relation_2" FOREIGN KEY ("group_id") REFERENCES "journal_groups" ("id") ON UPDATE CASCADE ON DELETE NO ACTION;
+
+---- create above / drop below ----
+
+ALTER TABLE "assignments_groups"
+    DROP CONSTRAINT "assignments_groups_relation_2",
+    ADD CONSTRAINT "assignments_groups_relation_2" FOREIGN KEY ("group_id") REFERENCES "journal_groups" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
\ No newline at end of file
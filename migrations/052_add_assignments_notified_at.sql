@@ -0,0 +1,11 @@
+ALTER TABLE "assignments"
+    ADD COLUMN "notified_at" timestamptz;
+
+UPDATE "assignments" SET "notified_at" = "published_at";
+
+CREATE INDEX assignments_unnotified_idx ON assignments (published_at) WHERE published_at IS NOT NULL AND notified_at IS NULL;
+
+---- create above / drop below ----
+
+ALTER TABLE "assignments"
+    DROP COLUMN "notified_at";
\ No newline at end of file
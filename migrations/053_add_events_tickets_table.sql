@@ -0,0 +1,15 @@
+CREATE TABLE "events_tickets" (
+    "id" integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    "session_id" integer NOT NULL,
+    "token" bytea NOT NULL UNIQUE,
+    "expires" timestamptz NOT NULL
+);
+
+ALTER TABLE "events_tickets"
+    ADD CONSTRAINT "events_tickets_relation_1" FOREIGN KEY ("session_id") REFERENCES "sessions" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX events_tickets_session_id_idx ON events_tickets (session_id);
+
+---- create above / drop below ----
+
+DROP TABLE "events_tickets";
\ No newline at end of file
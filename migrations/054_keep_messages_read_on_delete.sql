@@ -0,0 +1,23 @@
+-- when the last read message is deleted the pointer is moved back to the message before it (see DeleteMessage),
+-- other deletions leave it empty instead of forgetting that the user has read the thread
+ALTER TABLE "messages_read"
+    ALTER COLUMN "message_id" DROP NOT NULL,
+    DROP CONSTRAINT "messages_read_relation_3",
+    ADD CONSTRAINT "messages_read_relation_3" FOREIGN KEY ("message_id") REFERENCES "messages" ("id") ON UPDATE CASCADE ON DELETE SET NULL;
+
+-- threads read before messages_read existed count as read up to their latest message
+INSERT INTO "messages_read" ("thread_id", "user_id", "message_id", "read_at")
+SELECT "threads_read"."thread_id", "threads_read"."user_id", MAX("messages"."id"), MAX("messages"."created_at")
+FROM "threads_read"
+INNER JOIN "messages" ON "messages"."thread_id" = "threads_read"."thread_id"
+GROUP BY "threads_read"."thread_id", "threads_read"."user_id"
+ON CONFLICT ("thread_id", "user_id") DO NOTHING;
+
+---- create above / drop below ----
+
+DELETE FROM "messages_read" WHERE "message_id" IS NULL;
+
+ALTER TABLE "messages_read"
+    DROP CONSTRAINT "messages_read_relation_3",
+    ADD CONSTRAINT "messages_read_relation_3" FOREIGN KEY ("message_id") REFERENCES "messages" ("id") ON UPDATE CASCADE ON DELETE CASCADE,
+    ALTER COLUMN "message_id" SET NOT NULL;
\ No newline at end of file
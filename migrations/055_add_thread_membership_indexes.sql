@@ -0,0 +1,32 @@
+-- thread members are resolved from the rules with joins, these cover both directions
+CREATE INDEX threads_recipients_thread_id_idx ON threads_recipients (thread_id);
+CREATE INDEX threads_recipients_user_id_idx ON threads_recipients (user_id);
+CREATE INDEX threads_recipients_group_id_idx ON threads_recipients (group_id);
+
+CREATE INDEX threads_rules_class_id_idx ON threads_rules (class_id) WHERE class_id IS NOT NULL;
+CREATE INDEX threads_rules_journal_id_idx ON threads_rules (journal_id) WHERE journal_id IS NOT NULL;
+CREATE INDEX threads_rules_role_idx ON threads_rules (role) WHERE role IS NOT NULL;
+
+CREATE INDEX users_groups_group_id_idx ON users_groups (group_id);
+CREATE INDEX parents_children_child_id_idx ON parents_children (child_id);
+CREATE INDEX teachers_classes_class_id_idx ON teachers_classes (class_id);
+CREATE INDEX students_journals_journal_id_idx ON students_journals (journal_id);
+CREATE INDEX users_class_id_idx ON users (class_id);
+CREATE INDEX users_role_idx ON users (role);
+
+---- create above / drop below ----
+
+DROP INDEX users_role_idx;
+DROP INDEX users_class_id_idx;
+DROP INDEX students_journals_journal_id_idx;
+DROP INDEX teachers_classes_class_id_idx;
+DROP INDEX parents_children_child_id_idx;
+DROP INDEX users_groups_group_id_idx;
+
+DROP INDEX threads_rules_role_idx;
+DROP INDEX threads_rules_journal_id_idx;
+DROP INDEX threads_rules_class_id_idx;
+
+DROP INDEX threads_recipients_group_id_idx;
+DROP INDEX threads_recipients_user_id_idx;
+DROP INDEX threads_recipients_thread_id_idx;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE "messages_scheduled" ADD COLUMN "claimed_until" timestamptz;
+
+-- failed messages stay unsent so their authors can reschedule them
+UPDATE "messages_scheduled" SET "sent_at" = NULL WHERE "error" IS NOT NULL;
+
+---- create above / drop below ----
+
+UPDATE "messages_scheduled" SET "sent_at" = "updated_at" WHERE "error" IS NOT NULL;
+
+ALTER TABLE "messages_scheduled" DROP COLUMN "claimed_until";
\ No newline at end of file
@@ -0,0 +1,28 @@
+ALTER TABLE "timetable_slots"
+    ADD COLUMN "year_id" integer;
+
+UPDATE "timetable_slots" SET "year_id" = "journals"."year_id"
+    FROM "journals" WHERE "journals"."id" = "timetable_slots"."journal_id";
+
+ALTER TABLE "timetable_slots"
+    ALTER COLUMN "year_id" SET NOT NULL;
+
+ALTER TABLE "timetable_slots"
+    ADD CONSTRAINT "timetable_slots_relation_5" FOREIGN KEY ("year_id") REFERENCES "years" ("id") ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- a teacher or room is only taken for the slot in the same school year
+ALTER TABLE "timetable_slots"
+    DROP CONSTRAINT "timetable_slots_teacher_unique",
+    DROP CONSTRAINT "timetable_slots_room_unique",
+    ADD CONSTRAINT "timetable_slots_teacher_unique" UNIQUE ("year_id", "teacher_id", "weekday", "period_id"),
+    ADD CONSTRAINT "timetable_slots_room_unique" UNIQUE ("year_id", "room_id", "weekday", "period_id");
+
+---- create above / drop below ----
+
+ALTER TABLE "timetable_slots"
+    DROP CONSTRAINT "timetable_slots_teacher_unique",
+    DROP CONSTRAINT "timetable_slots_room_unique",
+    ADD CONSTRAINT "timetable_slots_teacher_unique" UNIQUE ("teacher_id", "weekday", "period_id"),
+    ADD CONSTRAINT "timetable_slots_room_unique" UNIQUE ("room_id", "weekday", "period_id");
+
+ALTER TABLE "timetable_slots" DROP COLUMN "year_id";
\ No newline at end of file
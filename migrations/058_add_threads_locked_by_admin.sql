@@ -0,0 +1,7 @@
+-- threads locked by an administrator only take messages from administrators
+ALTER TABLE "threads"
+    ADD COLUMN "locked_by_admin" boolean NOT NULL DEFAULT false;
+
+---- create above / drop below ----
+
+ALTER TABLE "threads" DROP COLUMN "locked_by_admin";
\ No newline at end of file
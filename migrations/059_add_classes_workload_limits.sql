@@ -0,0 +1,10 @@
+-- a class's own test limits, null uses the limits of the workload config
+ALTER TABLE "classes"
+    ADD COLUMN "max_tests_per_day" integer,
+    ADD COLUMN "max_tests_per_week" integer;
+
+---- create above / drop below ----
+
+ALTER TABLE "classes"
+    DROP COLUMN "max_tests_per_day",
+    DROP COLUMN "max_tests_per_week";
\ No newline at end of file